@@ -324,6 +324,14 @@ func (r *Application) AddConstraint(constraints ...string) *Application {
 	return r
 }
 
+// AddConstraints adds typed constraints, e.g. Unique("hostname") or GroupBy("rack_id", 3)
+//		constraints:	the constraints to add
+func (r *Application) AddConstraints(constraints ...Constraint) *Application {
+	r.Constraints = appendConstraints(r.Constraints, constraints)
+
+	return r
+}
+
 // EmptyConstraints explicitly empties constraints -- use this if you need to empty
 // constraints of an application that already has constraints set (setting constraints to nil will
 // keep the current value)
@@ -597,6 +605,75 @@ func (r *marathonClient) Applications(v url.Values) (*Applications, error) {
 	return applications, nil
 }
 
+// EachApplication calls the function for each application running in marathon, decoding the applications one at a
+// time from the response instead of retrieving them all at once. It stops at the first error of the function, which
+// it returns.
+//		v:		the query parameters, as for Applications
+//		fn:		the function processing the applications
+func (r *marathonClient) EachApplication(v url.Values, fn func(*Application) error) error {
+	query := v.Encode()
+	if query != "" {
+		query = "?" + query
+	}
+
+	return r.apiGet(marathonAPIApps+query, nil, &applicationsStream{fn: fn})
+}
+
+// applicationsStream decodes the applications of a response one at a time
+type applicationsStream struct {
+	fn func(*Application) error
+}
+
+func (s *applicationsStream) decode(decoder *json.Decoder) error {
+	// step: find the array of applications in the response object
+	if err := expectDelim(decoder, '{'); err != nil {
+		return err
+	}
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal response from Marathon: %s", err)
+		}
+		if key != "apps" {
+			var ignored json.RawMessage
+			if err := decoder.Decode(&ignored); err != nil {
+				return fmt.Errorf("failed to unmarshal response from Marathon: %s", err)
+			}
+			continue
+		}
+
+		// step: process the applications as they are decoded
+		if err := expectDelim(decoder, '['); err != nil {
+			return err
+		}
+		for decoder.More() {
+			application := new(Application)
+			if err := decoder.Decode(application); err != nil {
+				return fmt.Errorf("failed to unmarshal response from Marathon: %s", err)
+			}
+			if err := s.fn(application); err != nil {
+				return err
+			}
+		}
+		if err := expectDelim(decoder, ']'); err != nil {
+			return err
+		}
+	}
+	return expectDelim(decoder, '}')
+}
+
+// expectDelim reads the next token of the decoder, which has to be the given delimiter
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal response from Marathon: %s", err)
+	}
+	if token != delim {
+		return fmt.Errorf("failed to unmarshal response from Marathon: expected %s, got %v", delim, token)
+	}
+	return nil
+}
+
 // ListApplications retrieves an array of the application names currently running in marathon
 func (r *marathonClient) ListApplications(v url.Values) ([]string, error) {
 	applications, err := r.Applications(v)
@@ -760,24 +837,9 @@ func (r *marathonClient) CreateApplication(application *Application) (*Applicati
 //		name:		the id of the application
 //		timeout:	a duration of time to wait for an application to deploy
 func (r *marathonClient) WaitOnApplication(name string, timeout time.Duration) error {
-	if r.appExistAndRunning(name) {
-		return nil
-	}
-
-	timeoutTimer := time.After(timeout)
-	ticker := time.NewTicker(r.config.PollingWaitTime)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-timeoutTimer:
-			return ErrTimeoutError
-		case <-ticker.C:
-			if r.appExistAndRunning(name) {
-				return nil
-			}
-		}
-	}
+	return r.waitUntil(timeout, watchRunSpecs(matchID(name)), func(client *marathonClient) (bool, error) {
+		return client.appExistAndRunning(name), nil
+	})
 }
 
 func (r *marathonClient) appExistAndRunning(name string) bool {
@@ -798,7 +860,7 @@ func (r *marathonClient) DeleteApplication(name string, force bool) (*Deployment
 	path := buildPathWithForceParam(name, force)
 	// step: check of the application already exists
 	deployID := new(DeploymentID)
-	if err := r.apiDelete(path, nil, deployID); err != nil {
+	if err := r.resolveConflicts(func() error { return r.apiDelete(path, nil, deployID) }); err != nil {
 		return nil, err
 	}
 
@@ -828,7 +890,7 @@ func (r *marathonClient) ScaleApplicationInstances(name string, instances int, f
 	changes.Instances = &instances
 	path := buildPathWithForceParam(name, force)
 	deployID := new(DeploymentID)
-	if err := r.apiPut(path, changes, deployID); err != nil {
+	if err := r.resolveConflicts(func() error { return r.apiPut(path, changes, deployID) }); err != nil {
 		return nil, err
 	}
 
@@ -840,7 +902,7 @@ func (r *marathonClient) ScaleApplicationInstances(name string, instances int, f
 func (r *marathonClient) UpdateApplication(application *Application, force bool) (*DeploymentID, error) {
 	result := new(DeploymentID)
 	path := buildPathWithForceParam(application.ID, force)
-	if err := r.apiPut(path, application, result); err != nil {
+	if err := r.resolveConflicts(func() error { return r.apiPut(path, application, result) }); err != nil {
 		return nil, err
 	}
 	return result, nil
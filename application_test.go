@@ -17,6 +17,7 @@ limitations under the License.
 package marathon
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -356,6 +357,46 @@ func TestApplications(t *testing.T) {
 	assert.Equal(t, len(applications.Apps), 1)
 }
 
+func TestEachApplication(t *testing.T) {
+	endpoint := newFakeMarathonEndpoint(t, nil)
+	defer endpoint.Close()
+
+	applications, err := endpoint.Client.Applications(nil)
+	require.NoError(t, err)
+
+	var ids []string
+	err = endpoint.Client.EachApplication(nil, func(application *Application) error {
+		ids = append(ids, application.ID)
+		return nil
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, ids, len(applications.Apps)) {
+		for i, application := range applications.Apps {
+			assert.Equal(t, application.ID, ids[i])
+		}
+	}
+
+	v := url.Values{}
+	v.Set("cmd", "nginx")
+	ids = nil
+	err = endpoint.Client.EachApplication(v, func(application *Application) error {
+		ids = append(ids, application.ID)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, ids, 1)
+
+	// step: the iteration stops at the first error
+	stop := errors.New("stop")
+	count := 0
+	err = endpoint.Client.EachApplication(nil, func(application *Application) error {
+		count++
+		return stop
+	})
+	assert.Equal(t, stop, err)
+	assert.Equal(t, 1, count)
+}
+
 func TestApplicationsEmbedTaskStats(t *testing.T) {
 	endpoint := newFakeMarathonEndpoint(t, nil)
 	defer endpoint.Close()
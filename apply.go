@@ -0,0 +1,263 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ApplyActionType is the type of an action of an apply plan
+type ApplyActionType string
+
+const (
+	// ApplyCreate creates an application which does not exist
+	ApplyCreate ApplyActionType = "create"
+	// ApplyUpdate updates an application which differs from its desired state
+	ApplyUpdate ApplyActionType = "update"
+	// ApplyDelete deletes an application which is not desired anymore
+	ApplyDelete ApplyActionType = "delete"
+)
+
+// ApplyOptions configures how the desired applications are applied
+type ApplyOptions struct {
+	// DryRun only computes the plan, without executing it
+	DryRun bool
+	// Force applies the changes even if the applications are locked by deployments
+	Force bool
+	// Prune deletes the applications of the PruneGroup which are not desired
+	Prune bool
+	// PruneGroup is the group whose undesired applications are pruned, e.g. "/prod". It defaults to the ID of the
+	// group being applied, and is required when applying applications.
+	PruneGroup string
+}
+
+// ApplyAction is an action of an apply plan
+type ApplyAction struct {
+	// Type is the type of the action
+	Type ApplyActionType
+	// ID is the ID of the application
+	ID string
+	// Changes lists the JSON paths of the fields to update, e.g. "container.docker.image"
+	Changes []string
+	// Desired is the desired state of the application, nil when deleting it
+	Desired *Application
+	// Current is the current state of the application, nil when creating it
+	Current *Application
+	// Applied indicates that the action has been executed
+	Applied bool
+	// DeploymentID is the deployment started by the action, once applied
+	DeploymentID *DeploymentID
+}
+
+// ApplyPlan is the plan bringing the applications to their desired state
+type ApplyPlan struct {
+	// Actions are the actions of the plan: the creations, then the updates and the deletions
+	Actions []*ApplyAction
+	// Unchanged lists the IDs of the desired applications which are already in their desired state
+	Unchanged []string
+}
+
+// Apply brings the applications to their desired state, creating and updating them as needed, and deleting the
+// undesired ones when pruning. The fields populated by Marathon, like the tasks or the version, are ignored, as are
+// the fields the desired applications leave unset. The plan is returned along with the first error executing it, if
+// any, its actions telling which were applied.
+//		ctx:		the context of the API calls
+//		desired:	the desired state of the applications
+//		opts:		the options of the apply, if any
+func (r *marathonClient) Apply(ctx context.Context, desired []*Application, opts *ApplyOptions) (*ApplyPlan, error) {
+	if opts == nil {
+		opts = &ApplyOptions{}
+	}
+	if opts.Prune && opts.PruneGroup == "" {
+		return nil, errors.New("the group to prune is required")
+	}
+
+	client := r.withContext(ctx)
+	plan, err := client.applyPlan(desired, opts)
+	if err != nil || opts.DryRun {
+		return plan, err
+	}
+
+	return plan, client.executePlan(plan, opts.Force)
+}
+
+// ApplyGroup brings the applications of the group and of its subgroups to their desired state, as Apply does.
+// The IDs of the applications may be relative to their group.
+//		ctx:		the context of the API calls
+//		group:		the desired state of the group
+//		opts:		the options of the apply, if any
+func (r *marathonClient) ApplyGroup(ctx context.Context, group *Group, opts *ApplyOptions) (*ApplyPlan, error) {
+	options := ApplyOptions{}
+	if opts != nil {
+		options = *opts
+	}
+	if options.PruneGroup == "" {
+		options.PruneGroup = group.ID
+	}
+
+	return r.Apply(ctx, groupApplications(group, "/"), &options)
+}
+
+// applyPlan compares the desired applications with the current ones
+func (r *marathonClient) applyPlan(desired []*Application, opts *ApplyOptions) (*ApplyPlan, error) {
+	current := make(map[string]*Application)
+	err := r.EachApplication(nil, func(application *Application) error {
+		current[application.ID] = application
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	plan := new(ApplyPlan)
+	var creations, updates, deletions []*ApplyAction
+	desiredIDs := make(map[string]bool)
+	for _, application := range desired {
+		id := "/" + trimRootPath(application.ID)
+		if desiredIDs[id] {
+			return nil, fmt.Errorf("the application: %s is desired more than once", id)
+		}
+		desiredIDs[id] = true
+
+		existing, found := current[id]
+		if !found {
+			creations = append(creations, &ApplyAction{Type: ApplyCreate, ID: id, Desired: application})
+			continue
+		}
+		changes, err := applicationChanges(application, existing)
+		if err != nil {
+			return nil, err
+		}
+		if len(changes) == 0 {
+			plan.Unchanged = append(plan.Unchanged, id)
+			continue
+		}
+		updates = append(updates, &ApplyAction{
+			Type:    ApplyUpdate,
+			ID:      id,
+			Changes: changes,
+			Desired: application,
+			Current: existing,
+		})
+	}
+
+	if opts.Prune {
+		prefix := strings.TrimSuffix("/"+trimRootPath(opts.PruneGroup), "/") + "/"
+		for id, application := range current {
+			if strings.HasPrefix(id, prefix) && !desiredIDs[id] {
+				deletions = append(deletions, &ApplyAction{Type: ApplyDelete, ID: id, Current: application})
+			}
+		}
+	}
+
+	for _, actions := range [][]*ApplyAction{creations, updates, deletions} {
+		sort.Slice(actions, func(i, j int) bool { return actions[i].ID < actions[j].ID })
+		plan.Actions = append(plan.Actions, actions...)
+	}
+	sort.Strings(plan.Unchanged)
+
+	return plan, nil
+}
+
+// executePlan executes the actions of the plan in order, stopping at the first error
+func (r *marathonClient) executePlan(plan *ApplyPlan, force bool) error {
+	for _, action := range plan.Actions {
+		switch action.Type {
+		case ApplyCreate:
+			application := applicationDefinition(action.Desired, action.ID)
+			created, err := r.CreateApplication(application)
+			if err != nil {
+				return err
+			}
+			if len(created.Deployments) > 0 {
+				action.DeploymentID = &DeploymentID{DeploymentID: created.Deployments[0]["id"], Version: created.Version}
+			}
+		case ApplyUpdate:
+			deploymentID, err := r.UpdateApplication(applicationDefinition(action.Desired, action.ID), force)
+			if err != nil {
+				return err
+			}
+			action.DeploymentID = deploymentID
+		case ApplyDelete:
+			deploymentID, err := r.DeleteApplication(action.ID, force)
+			if err != nil {
+				return err
+			}
+			action.DeploymentID = deploymentID
+		}
+		action.Applied = true
+	}
+
+	return nil
+}
+
+// applicationDefinition returns a copy of the desired application with its absolute ID, without the fields
+// populated by Marathon
+func applicationDefinition(application *Application, id string) *Application {
+	definition := *application
+	definition.ID = id
+	definition.Tasks = nil
+	definition.TasksRunning, definition.TasksStaged, definition.TasksHealthy, definition.TasksUnhealthy = 0, 0, 0, 0
+	definition.TaskStats = nil
+	definition.Deployments = nil
+	definition.ReadinessCheckResults = nil
+	definition.LastTaskFailure = nil
+	definition.Version = ""
+	definition.VersionInfo = nil
+
+	return &definition
+}
+
+// groupApplications returns the applications of the group and of its subgroups, with absolute IDs
+//		group:		the group of the applications
+//		parent:		the ID of the parent of the group
+func groupApplications(group *Group, parent string) []*Application {
+	groupID := resolveID(group.ID, parent)
+
+	var applications []*Application
+	for _, application := range group.Apps {
+		definition := *application
+		definition.ID = resolveID(application.ID, groupID)
+		applications = append(applications, &definition)
+	}
+	for _, subgroup := range group.Groups {
+		applications = append(applications, groupApplications(subgroup, groupID)...)
+	}
+	return applications
+}
+
+// resolveID returns the absolute form of an ID which may be relative to its parent group
+func resolveID(id, parent string) string {
+	if strings.HasPrefix(id, "/") {
+		return id
+	}
+	return strings.TrimSuffix(parent, "/") + "/" + id
+}
+
+// applicationChanges returns the JSON paths of the fields set in the desired application which differ in the
+// current one
+func applicationChanges(desired, current *Application) ([]string, error) {
+	diff, err := DiffApplications(current, applicationDefinition(desired, current.ID))
+	if err != nil {
+		return nil, err
+	}
+	return diff.Paths(), nil
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyDryRun(t *testing.T) {
+	endpoint := newFakeMarathonEndpoint(t, nil)
+	defer endpoint.Close()
+
+	desired := []*Application{
+		NewDockerApplication().Name("fake-app").Count(3).CPU(0.5),
+		NewDockerApplication().Name("/new-app"),
+	}
+	plan, err := endpoint.Client.Apply(context.Background(), desired, &ApplyOptions{DryRun: true, Prune: true, PruneGroup: "/"})
+	require.NoError(t, err)
+	require.Len(t, plan.Actions, 3)
+
+	assert.Equal(t, ApplyCreate, plan.Actions[0].Type)
+	assert.Equal(t, "/new-app", plan.Actions[0].ID)
+	assert.Equal(t, ApplyUpdate, plan.Actions[1].Type)
+	assert.Equal(t, "/fake-app", plan.Actions[1].ID)
+	assert.Equal(t, []string{"instances"}, plan.Actions[1].Changes)
+	assert.Equal(t, ApplyDelete, plan.Actions[2].Type)
+	assert.Equal(t, "/fake-app-broken", plan.Actions[2].ID)
+	for _, action := range plan.Actions {
+		assert.False(t, action.Applied)
+	}
+
+	_, err = endpoint.Client.Apply(context.Background(), desired, &ApplyOptions{Prune: true})
+	assert.Error(t, err)
+	_, err = endpoint.Client.Apply(context.Background(), append(desired, desired[0]), nil)
+	assert.Error(t, err)
+}
+
+func TestApplyGroup(t *testing.T) {
+	endpoint := newFakeMarathonEndpoint(t, nil)
+	defer endpoint.Close()
+
+	group := &Group{
+		ID:     "/",
+		Apps:   []*Application{NewDockerApplication().Name("fake-app-broken").Count(2)},
+		Groups: []*Group{{ID: "new", Apps: []*Application{{ID: "app"}}}},
+	}
+	plan, err := endpoint.Client.ApplyGroup(context.Background(), group, &ApplyOptions{Prune: true})
+	require.NoError(t, err)
+	require.Len(t, plan.Actions, 2)
+	assert.Equal(t, ApplyCreate, plan.Actions[0].Type)
+	assert.Equal(t, "/new/app", plan.Actions[0].ID)
+	assert.Equal(t, ApplyDelete, plan.Actions[1].Type)
+	assert.Equal(t, "/fake-app", plan.Actions[1].ID)
+	assert.Equal(t, []string{"/fake-app-broken"}, plan.Unchanged)
+	for _, action := range plan.Actions {
+		assert.True(t, action.Applied)
+	}
+	assert.NotNil(t, plan.Actions[1].DeploymentID)
+
+	// step: the updates
+	plan, err = endpoint.Client.Apply(context.Background(), []*Application{NewDockerApplication().Name("fake-app").Count(3)}, nil)
+	require.NoError(t, err)
+	if assert.Len(t, plan.Actions, 1) {
+		assert.Equal(t, ApplyUpdate, plan.Actions[0].Type)
+		assert.True(t, plan.Actions[0].Applied)
+		assert.Equal(t, "83b215a6-4e26-4e44-9333-5c385eda6438", plan.Actions[0].DeploymentID.DeploymentID)
+	}
+}
+
+func TestApplicationChanges(t *testing.T) {
+	current := NewDockerApplication().Name("/app").Count(2).CPU(0.5).Memory(64).AddEnv("KEY", "value")
+	current.Container.Docker.Container("python:3")
+	current.Tasks = []*Task{{ID: "app.1"}}
+	current.TasksRunning = 2
+	current.Version = "2014-09-12T23:28:21.737Z"
+
+	// step: the unset and the server populated fields are ignored
+	desired := NewDockerApplication().Name("app").Count(2)
+	desired.Version = "2014-08-18T22:36:41.451Z"
+	changes, err := applicationChanges(desired, current)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	desired.CPU(1).EmptyEnvs()
+	desired.Container.Docker.Container("python:3.6")
+	changes, err = applicationChanges(desired, current)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"container.docker.image", "cpus", "env.KEY"}, changes)
+
+	// step: the empty values match the missing ones
+	current.Env = nil
+	changes, err = applicationChanges(desired, current)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"container.docker.image", "cpus"}, changes)
+}
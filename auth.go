@@ -0,0 +1,345 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// dcosLoginPath is the path of the login endpoint of the DC/OS identity and access manager
+	dcosLoginPath = "acs/api/v1/auth/login"
+	// dcosLoginTokenLifetime is the validity of the tokens signed by service accounts to log in
+	dcosLoginTokenLifetime = 5 * time.Minute
+	// dcosTokenLifetime is the assumed validity of an authentication token without an expiry
+	dcosTokenLifetime = time.Hour
+	// dcosTokenRefreshMargin is the time before the expiry of a token it is refreshed in
+	dcosTokenRefreshMargin = time.Minute
+	// dcosRejectedLoginInterval is the minimum time between the logins replacing a rejected token, so that an
+	// account denied access doesn't log in on every request
+	dcosRejectedLoginInterval = time.Minute
+)
+
+// Authenticator adds the credentials to the requests sent to Marathon
+type Authenticator interface {
+	// Authenticate adds the credentials to the request, e.g. its Authorization header
+	Authenticate(request *http.Request) error
+	// Refresh discards the credentials of the request Marathon rejected, unless they have been replaced
+	// meanwhile. It returns true when fresh credentials can be obtained, in which case the rejected request is
+	// retried once.
+	Refresh(rejected *http.Request) bool
+}
+
+// AuthenticationError is returned when the credentials of a request could not be obtained, e.g. the login of
+// a DC/OS service account failed
+type AuthenticationError struct {
+	Err error
+}
+
+func (e *AuthenticationError) Error() string {
+	return fmt.Sprintf("failed to authenticate the request: %s", e.Err)
+}
+
+// BasicAuthenticator authenticates the requests with HTTP basic auth
+//		user:		the name of the user
+//		password:	the password of the user
+func BasicAuthenticator(user, password string) Authenticator {
+	return &basicAuthenticator{user: user, password: password}
+}
+
+type basicAuthenticator struct {
+	user     string
+	password string
+}
+
+func (a *basicAuthenticator) Authenticate(request *http.Request) error {
+	request.SetBasicAuth(a.user, a.password)
+	return nil
+}
+
+func (a *basicAuthenticator) Refresh(rejected *http.Request) bool {
+	return false
+}
+
+// TokenAuthenticator authenticates the requests with a static DC/OS authentication token
+//		token:		the authentication token
+func TokenAuthenticator(token string) Authenticator {
+	return tokenAuthenticator(token)
+}
+
+type tokenAuthenticator string
+
+func (a tokenAuthenticator) Authenticate(request *http.Request) error {
+	request.Header.Set("Authorization", "token="+string(a))
+	return nil
+}
+
+func (a tokenAuthenticator) Refresh(rejected *http.Request) bool {
+	return false
+}
+
+// DCOSServiceAccount holds the credentials of a DC/OS service account
+type DCOSServiceAccount struct {
+	// UID is the ID of the service account
+	UID string
+	// PrivateKey is the key of the service account the login tokens are signed with
+	PrivateKey *rsa.PrivateKey
+	// LoginEndpoint is the URL of the login endpoint, e.g. "https://leader.mesos/acs/api/v1/auth/login"
+	LoginEndpoint string
+}
+
+// ParseDCOSServiceAccount parses the credentials of a DC/OS service account, in the JSON format of the secrets
+// created by the DC/OS CLI, i.e. holding the 'uid', 'private_key' and 'login_endpoint' fields
+//		content:	the JSON encoded credentials
+func ParseDCOSServiceAccount(content []byte) (*DCOSServiceAccount, error) {
+	var credentials struct {
+		Scheme        string `json:"scheme"`
+		UID           string `json:"uid"`
+		PrivateKey    string `json:"private_key"`
+		LoginEndpoint string `json:"login_endpoint"`
+	}
+	if err := json.Unmarshal(content, &credentials); err != nil {
+		return nil, fmt.Errorf("invalid service account credentials: %s", err)
+	}
+	if credentials.Scheme != "" && credentials.Scheme != "RS256" {
+		return nil, fmt.Errorf("unsupported service account scheme: %s", credentials.Scheme)
+	}
+	if credentials.UID == "" {
+		return nil, errors.New("the service account credentials lack the uid")
+	}
+
+	key, err := parseRSAPrivateKey([]byte(credentials.PrivateKey))
+	if err != nil {
+		return nil, err
+	}
+
+	return &DCOSServiceAccount{
+		UID:           credentials.UID,
+		PrivateKey:    key,
+		LoginEndpoint: credentials.LoginEndpoint,
+	}, nil
+}
+
+// parseRSAPrivateKey parses a PEM encoded RSA private key, either in the PKCS #1 or the PKCS #8 form
+func parseRSAPrivateKey(content []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, errors.New("the service account private key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid service account private key: %s", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("the service account private key is not an RSA key")
+	}
+	return key, nil
+}
+
+// DCOSAuthenticator authenticates the requests with the tokens obtained by logging in with a DC/OS service
+// account. The account logs in on the first request and again shortly before the token expires or when
+// Marathon rejects it.
+//		account:	the service account, without a login endpoint the one of the cluster of the request is used
+//		client:		the http client logging in, nil uses http.DefaultClient
+func DCOSAuthenticator(account DCOSServiceAccount, client *http.Client) Authenticator {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &dcosAuthenticator{
+		account: account,
+		client:  client,
+		now:     time.Now,
+	}
+}
+
+// isDCOSAuthenticator checks if the authenticator logs in with a DC/OS service account
+func isDCOSAuthenticator(authenticator Authenticator) bool {
+	_, ok := authenticator.(*dcosAuthenticator)
+	return ok
+}
+
+type dcosAuthenticator struct {
+	sync.Mutex
+	account DCOSServiceAccount
+	client  *http.Client
+	now     func() time.Time
+	// token is the current authentication token, empty when a login is required
+	token string
+	// expiry is the time the current token expires at
+	expiry time.Time
+	// rejectedAt is the time a token was last discarded after Marathon rejected it
+	rejectedAt time.Time
+}
+
+func (a *dcosAuthenticator) Authenticate(request *http.Request) error {
+	a.Lock()
+	defer a.Unlock()
+
+	// step: log in when there is no token yet or it is about to expire
+	if a.token == "" || !a.now().Add(dcosTokenRefreshMargin).Before(a.expiry) {
+		if err := a.login(request); err != nil {
+			return &AuthenticationError{Err: err}
+		}
+	}
+	request.Header.Set("Authorization", "token="+a.token)
+	return nil
+}
+
+func (a *dcosAuthenticator) Refresh(rejected *http.Request) bool {
+	a.Lock()
+	defer a.Unlock()
+
+	// step: the concurrent requests rejected with the same token log in once
+	if a.token == "" || rejected.Header.Get("Authorization") != "token="+a.token {
+		return true
+	}
+	// step: a token rejected soon after replacing a rejected one means the account is denied access
+	if !a.rejectedAt.IsZero() && a.now().Before(a.rejectedAt.Add(dcosRejectedLoginInterval)) {
+		return false
+	}
+	a.token = ""
+	a.rejectedAt = a.now()
+	return true
+}
+
+// login exchanges a login token signed by the service account for an authentication token
+//		request:	the request to authenticate, which provides the context and the default login endpoint
+func (a *dcosAuthenticator) login(request *http.Request) error {
+	// step: sign the login token
+	loginToken, err := signJWT(a.account.PrivateKey, map[string]interface{}{
+		"uid": a.account.UID,
+		"exp": a.now().Add(dcosLoginTokenLifetime).Unix(),
+	})
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(map[string]string{
+		"uid":   a.account.UID,
+		"token": loginToken,
+	})
+	if err != nil {
+		return err
+	}
+
+	// step: exchange it at the login endpoint
+	endpoint := a.account.LoginEndpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("%s://%s/%s", request.URL.Scheme, request.URL.Host, dcosLoginPath)
+	}
+	login, err := http.NewRequest("POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	login.Header.Set("Content-Type", "application/json")
+	login.Header.Set("Accept", "application/json")
+	response, err := a.client.Do(login.WithContext(request.Context()))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	content, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("login of the service account %s failed with status: %d, body: %s", a.account.UID,
+			response.StatusCode, oneLogLine(content))
+	}
+
+	var result struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(content, &result); err != nil {
+		return fmt.Errorf("invalid login response: %s", err)
+	}
+	if result.Token == "" {
+		return errors.New("the login response lacks the token")
+	}
+
+	// step: the token expires as claimed, or after an hour if it can't tell
+	a.token = result.Token
+	a.expiry = a.now().Add(dcosTokenLifetime)
+	if expiry, found := jwtExpiry(result.Token); found {
+		a.expiry = expiry
+	}
+	return nil
+}
+
+// signJWT creates a JSON Web Token with the given claims, signed with RS256
+func signJWT(key *rsa.PrivateKey, claims map[string]interface{}) (string, error) {
+	if key == nil {
+		return "", errors.New("the service account lacks the private key")
+	}
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// jwtExpiry reads the expiry claim of a JSON Web Token, without verifying it
+func jwtExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var claims struct {
+		Expiry int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Expiry == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(claims.Expiry, 0), true
+}
@@ -0,0 +1,377 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDCOS serves the login endpoint of DC/OS and the ping endpoint of Marathon, which accepts the last
+// issued token only
+type fakeDCOS struct {
+	sync.Mutex
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PublicKey
+	uid    string
+	// logins is the number of successful logins
+	logins int
+	// token is the last issued token
+	token string
+	// lifetime is the validity of the issued tokens
+	lifetime time.Duration
+	// denied rejects all the tokens, as for an account denied access to Marathon
+	denied bool
+}
+
+func newFakeDCOS(t *testing.T, uid string, key *rsa.PublicKey) *fakeDCOS {
+	dcos := &fakeDCOS{t: t, key: key, uid: uid, lifetime: time.Hour}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/"+dcosLoginPath, dcos.login)
+	mux.HandleFunc("/marathon/"+marathonAPIPing, func(writer http.ResponseWriter, request *http.Request) {
+		dcos.Lock()
+		defer dcos.Unlock()
+		if dcos.denied || request.Header.Get("Authorization") != "token="+dcos.token {
+			http.Error(writer, `{"message": "invalid token"}`, 401)
+			return
+		}
+		writer.Write([]byte("pong"))
+	})
+	dcos.server = httptest.NewServer(mux)
+
+	return dcos
+}
+
+func (d *fakeDCOS) login(writer http.ResponseWriter, request *http.Request) {
+	var login struct {
+		UID   string `json:"uid"`
+		Token string `json:"token"`
+	}
+	require.NoError(d.t, json.NewDecoder(request.Body).Decode(&login))
+
+	// step: verify the signature and the claims of the login token
+	parts := strings.Split(login.Token, ".")
+	require.Len(d.t, parts, 3)
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	require.NoError(d.t, err)
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if login.UID != d.uid || rsa.VerifyPKCS1v15(d.key, crypto.SHA256, digest[:], signature) != nil {
+		http.Error(writer, `{"title": "Unauthorized"}`, 401)
+		return
+	}
+	expiry, found := jwtExpiry(login.Token)
+	assert.True(d.t, found)
+	assert.True(d.t, expiry.After(time.Now()))
+
+	// step: issue a new token
+	d.Lock()
+	defer d.Unlock()
+	d.logins++
+	claims, _ := json.Marshal(map[string]interface{}{"uid": d.uid, "exp": time.Now().Add(d.lifetime).Unix()})
+	d.token = fmt.Sprintf("e30.%s.token-%d", base64.RawURLEncoding.EncodeToString(claims), d.logins)
+	json.NewEncoder(writer).Encode(map[string]string{"token": d.token})
+}
+
+func (d *fakeDCOS) loginCount() int {
+	d.Lock()
+	defer d.Unlock()
+	return d.logins
+}
+
+// revoke invalidates the last issued token
+func (d *fakeDCOS) revoke() {
+	d.Lock()
+	defer d.Unlock()
+	d.token = "revoked"
+}
+
+func TestBasicAuthenticator(t *testing.T) {
+	config := NewDefaultConfig()
+	config.Authenticator = BasicAuthenticator("user", "password")
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{
+		client: &config,
+		server: &serverConfig{username: "user", password: "password"},
+	})
+	defer endpoint.Close()
+
+	_, err := endpoint.Client.Applications(nil)
+	assert.NoError(t, err)
+}
+
+func TestTokenAuthenticator(t *testing.T) {
+	config := NewDefaultConfig()
+	config.Authenticator = TokenAuthenticator("secret")
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{
+		client: &config,
+		server: &serverConfig{dcosToken: "secret"},
+	})
+	defer endpoint.Close()
+
+	_, err := endpoint.Client.Applications(nil)
+	assert.NoError(t, err)
+
+	config.Authenticator = TokenAuthenticator("expired")
+	client, err := NewClient(config)
+	require.NoError(t, err)
+	_, err = client.Applications(nil)
+	if assert.Error(t, err) {
+		apiErr, ok := err.(*APIError)
+		if assert.True(t, ok) {
+			assert.Equal(t, ErrCodeUnauthorized, apiErr.ErrCode)
+		}
+	}
+}
+
+func TestDCOSAuthenticator(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	dcos := newFakeDCOS(t, "service-account", &key.PublicKey)
+	defer dcos.server.Close()
+
+	authenticator := DCOSAuthenticator(DCOSServiceAccount{UID: "service-account", PrivateKey: key}, nil)
+	config := NewDefaultConfig()
+	config.URL = dcos.server.URL
+	config.Authenticator = authenticator
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	// step: the service account logs in once and reuses the token
+	for i := 0; i < 3; i++ {
+		_, err = client.Ping()
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, dcos.loginCount())
+
+	// step: a rejected token is refreshed and the request retried
+	dcos.revoke()
+	_, err = client.Ping()
+	require.NoError(t, err)
+	assert.Equal(t, 2, dcos.loginCount())
+
+	// step: a token about to expire is refreshed beforehand
+	authenticator.(*dcosAuthenticator).now = func() time.Time {
+		return time.Now().Add(time.Hour)
+	}
+	_, err = client.Ping()
+	require.NoError(t, err)
+	assert.Equal(t, 3, dcos.loginCount())
+}
+
+func TestDCOSAuthenticatorConcurrentRejections(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	dcos := newFakeDCOS(t, "service-account", &key.PublicKey)
+	defer dcos.server.Close()
+
+	config := NewDefaultConfig()
+	config.URL = dcos.server.URL
+	config.Authenticator = DCOSAuthenticator(DCOSServiceAccount{UID: "service-account", PrivateKey: key}, nil)
+	client, err := NewClient(config)
+	require.NoError(t, err)
+	_, err = client.Ping()
+	require.NoError(t, err)
+
+	// step: the requests rejected with the same token log in once
+	dcos.revoke()
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.Ping()
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 2, dcos.loginCount())
+}
+
+func TestDCOSAuthenticatorDenied(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	dcos := newFakeDCOS(t, "service-account", &key.PublicKey)
+	dcos.denied = true
+	defer dcos.server.Close()
+
+	config := NewDefaultConfig()
+	config.URL = dcos.server.URL
+	config.Authenticator = DCOSAuthenticator(DCOSServiceAccount{UID: "service-account", PrivateKey: key}, nil)
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	// step: the account logs in again once, then its tokens being rejected too, not on every request
+	for i := 0; i < 3; i++ {
+		_, err = client.Ping()
+		require.Error(t, err)
+		assert.Equal(t, ErrCodeUnauthorized, err.(*APIError).ErrCode)
+	}
+	assert.Equal(t, 2, dcos.loginCount())
+}
+
+// refreshingAuthenticator is an authenticator whose credentials can always be refreshed
+type refreshingAuthenticator struct{}
+
+func (refreshingAuthenticator) Authenticate(request *http.Request) error {
+	return nil
+}
+
+func (refreshingAuthenticator) Refresh(rejected *http.Request) bool {
+	return true
+}
+
+func TestReauthenticationAttempts(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		// step: the health checks of the member marked down are answered apart
+		if request.URL.Path == "/"+marathonAPIPing {
+			writer.Write([]byte("pong"))
+			return
+		}
+		switch atomic.AddInt32(&requests, 1) {
+		case 1:
+			writer.WriteHeader(http.StatusUnauthorized)
+		case 2:
+			writer.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			writer.Write([]byte(`{"name": "marathon"}`))
+		}
+	}))
+	defer server.Close()
+
+	// step: the retry with fresh credentials doesn't use up an attempt of the retry policy
+	config := NewDefaultConfig()
+	config.URL = getTestURL(server.URL)
+	config.Authenticator = refreshingAuthenticator{}
+	config.RetryPolicy = &RetryPolicy{MaxAttempts: 2}
+	client, err := NewClient(config)
+	require.NoError(t, err)
+	_, err = client.Info()
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+}
+
+func TestReauthenticationOnce(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		writer.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	// step: the rejected request is retried once, however often the credentials can be refreshed
+	config := NewDefaultConfig()
+	config.URL = server.URL
+	config.Authenticator = refreshingAuthenticator{}
+	client, err := NewClient(config)
+	require.NoError(t, err)
+	_, err = client.Info()
+	assert.Error(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
+func TestDCOSAuthenticatorContext(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	released := make(chan struct{})
+	login := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		<-released
+	}))
+	defer login.Close()
+	defer close(released)
+
+	config := NewDefaultConfig()
+	config.URL = login.URL
+	config.Authenticator = DCOSAuthenticator(DCOSServiceAccount{UID: "service-account", PrivateKey: key}, nil)
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	// step: the login is aborted with the call
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = client.WithContext(ctx).Ping()
+	assert.Error(t, err)
+	assert.True(t, time.Since(start) < 5*time.Second)
+}
+
+func TestDCOSAuthenticatorLoginFailure(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	dcos := newFakeDCOS(t, "service-account", &key.PublicKey)
+	defer dcos.server.Close()
+
+	config := NewDefaultConfig()
+	config.URL = dcos.server.URL + "/marathon"
+	config.Authenticator = DCOSAuthenticator(DCOSServiceAccount{
+		UID:           "unknown-account",
+		PrivateKey:    key,
+		LoginEndpoint: dcos.server.URL + "/" + dcosLoginPath,
+	}, nil)
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	_, err = client.Ping()
+	if assert.Error(t, err) {
+		_, ok := err.(*AuthenticationError)
+		assert.True(t, ok)
+		assert.Contains(t, err.Error(), "unknown-account")
+	}
+	assert.Equal(t, 0, dcos.loginCount())
+}
+
+func TestParseDCOSServiceAccount(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	pkcs1 := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	content, _ := json.Marshal(map[string]string{
+		"scheme":         "RS256",
+		"uid":            "service-account",
+		"private_key":    string(pkcs1),
+		"login_endpoint": "https://leader.mesos/acs/api/v1/auth/login",
+	})
+	account, err := ParseDCOSServiceAccount(content)
+	require.NoError(t, err)
+	assert.Equal(t, "service-account", account.UID)
+	assert.Equal(t, "https://leader.mesos/acs/api/v1/auth/login", account.LoginEndpoint)
+	assert.Equal(t, key.D, account.PrivateKey.D)
+
+	_, err = ParseDCOSServiceAccount([]byte(`{"uid": "service-account", "private_key": "invalid"}`))
+	assert.Error(t, err)
+	_, err = ParseDCOSServiceAccount([]byte(`{"scheme": "HS256", "uid": "service-account"}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,282 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// cacheDeploymentEvents comprises the listener IDs of the deployment events
+	cacheDeploymentEvents = EventIDDeploymentSuccess | EventIDDeploymentFailed | EventIDDeploymentInfo |
+		EventIDDeploymentStepSuccess | EventIDDeploymentStepFailure
+	// cacheEventsFilter comprises the listener IDs of the events invalidating cached responses
+	cacheEventsFilter = EventIDAPIRequest | EventIDApplications | EventIDInstances | EventIDPods |
+		EventIDGroupChangeSuccess | cacheDeploymentEvents | EventIDStreamReconnected
+)
+
+// cacheWriteEffects are the resources, besides their own, whose responses the changes made to a resource affect
+var cacheWriteEffects = map[string][]string{
+	marathonAPIApps:         {marathonAPIGroups, marathonAPITasks, marathonAPIQueue},
+	marathonAPIPods:         {marathonAPIGroups},
+	marathonAPIGroups:       {marathonAPIApps, marathonAPIPods, marathonAPITasks, marathonAPIQueue},
+	marathonAPIDeployments:  {marathonAPIApps, marathonAPIPods, marathonAPIGroups, marathonAPITasks, marathonAPIQueue},
+	marathonAPITasks:        {marathonAPIApps, marathonAPIPods, marathonAPIGroups, marathonAPIQueue},
+	marathonAPIQueue:        {},
+	marathonAPISubscription: {},
+	marathonAPILeader:       {marathonAPIInfo},
+}
+
+// CachePolicy configures the cache of the responses to GET requests. The deployments are never cached, as they
+// are polled to wait on them.
+type CachePolicy struct {
+	// TTL is the time the responses are cached for, unless overridden by TTLs
+	TTL time.Duration
+	// TTLs overrides the TTL of the paths starting with the given prefixes, the longest prefix winning, e.g.
+	// {"v2/tasks": time.Second}. A zero TTL disables the caching of the paths.
+	TTLs map[string]time.Duration
+	// InvalidateOnEvents listens to the events of Marathon, through the configured events transport, to
+	// invalidate the cached responses they affect
+	InvalidateOnEvents bool
+}
+
+// responseCache caches the bodies of the responses to GET requests by path. Expired responses are revalidated
+// with conditional requests when Marathon provided an ETag or a modification time.
+type responseCache struct {
+	sync.Mutex
+	policy  CachePolicy
+	entries map[string]*cacheEntry
+	now     func() time.Time
+	// generation is bumped by each invalidation, so that the responses in flight meanwhile are not cached
+	generation uint64
+}
+
+// cacheEntry is a cached response
+type cacheEntry struct {
+	body         []byte
+	header       http.Header
+	etag         string
+	lastModified string
+	expiry       time.Time
+}
+
+func newResponseCache(policy CachePolicy) *responseCache {
+	ttls := make(map[string]time.Duration)
+	for prefix, ttl := range policy.TTLs {
+		ttls[strings.TrimPrefix(prefix, "/")] = ttl
+	}
+	policy.TTLs = ttls
+
+	return &responseCache{
+		policy:  policy,
+		entries: make(map[string]*cacheEntry),
+		now:     time.Now,
+	}
+}
+
+// ttl returns the time the responses of the path are cached for, zero if they are not cached
+func (c *responseCache) ttl(path string) time.Duration {
+	if hasResourcePrefix(strings.SplitN(path, "?", 2)[0], marathonAPIDeployments) {
+		return 0
+	}
+	ttl, longest := c.policy.TTL, -1
+	for prefix, prefixTTL := range c.policy.TTLs {
+		if strings.HasPrefix(path, prefix) && len(prefix) > longest {
+			ttl, longest = prefixTTL, len(prefix)
+		}
+	}
+	return ttl
+}
+
+// get returns the cached response of the path, if any, and whether it is still fresh
+func (c *responseCache) get(path string) (*cacheEntry, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	entry, found := c.entries[path]
+	if !found {
+		return nil, false
+	}
+	if c.now().Before(entry.expiry) {
+		return entry, true
+	}
+	// step: an expired response is only useful to revalidate it
+	if entry.etag == "" && entry.lastModified == "" {
+		delete(c.entries, path)
+		return nil, false
+	}
+	return entry, false
+}
+
+// currentGeneration returns the generation of the cache, to be captured before sending a request
+func (c *responseCache) currentGeneration() uint64 {
+	c.Lock()
+	defer c.Unlock()
+	return c.generation
+}
+
+// put caches the response of the path, unless the cache has been invalidated since the request was sent
+//		path:		the path of the request
+//		response:	the response
+//		body:		the body of the response
+//		generation:	the generation of the cache when the request was sent
+func (c *responseCache) put(path string, response *http.Response, body []byte, generation uint64) {
+	c.Lock()
+	defer c.Unlock()
+
+	// step: the response may predate the change which invalidated the cache
+	if c.generation != generation {
+		return
+	}
+
+	c.entries[path] = &cacheEntry{
+		body:         body,
+		header:       response.Header,
+		etag:         response.Header.Get("ETag"),
+		lastModified: response.Header.Get("Last-Modified"),
+		expiry:       c.now().Add(c.ttl(path)),
+	}
+}
+
+// revalidated extends the freshness of the response of the path after Marathon confirmed it is not modified
+func (c *responseCache) revalidated(path string, entry *cacheEntry) {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.entries[path] == entry {
+		entry.expiry = c.now().Add(c.ttl(path))
+	}
+}
+
+// invalidate removes the cached responses whose path (without the query) matches
+func (c *responseCache) invalidate(match func(path string) bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.generation++
+	for path := range c.entries {
+		if match(strings.SplitN(path, "?", 2)[0]) {
+			delete(c.entries, path)
+		}
+	}
+}
+
+// invalidateWrite removes the cached responses affected by a change made through the client
+//		path:		the path of the request making the change
+func (c *responseCache) invalidateWrite(path string) {
+	path = strings.SplitN(path, "?", 2)[0]
+	resource := ""
+	for prefix := range cacheWriteEffects {
+		if hasResourcePrefix(path, prefix) {
+			resource = prefix
+		}
+	}
+	// step: the effects of the changes to other resources are unknown
+	if resource == "" {
+		c.invalidate(func(string) bool { return true })
+		return
+	}
+
+	// step: the changes to an application or a pod only affect its own responses besides the collections
+	resourcePath := ""
+	if resource == marathonAPIApps || resource == marathonAPIPods {
+		resourcePath = runSpecPath(path)
+	}
+
+	c.invalidate(func(cached string) bool {
+		for _, affected := range cacheWriteEffects[resource] {
+			if hasResourcePrefix(cached, affected) {
+				return true
+			}
+		}
+		if resourcePath == "" || cached == resource {
+			return hasResourcePrefix(cached, resource)
+		}
+		return cached == resourcePath || strings.HasPrefix(cached, resourcePath+"/") ||
+			strings.HasPrefix(cached, resourcePath+"::")
+	})
+}
+
+// runSpecPath returns the path of the application or the pod a path is about, empty for their collections
+func runSpecPath(path string) string {
+	if strings.Count(path, "/") < 2 {
+		return ""
+	}
+	if index := strings.Index(path, "::"); index >= 0 {
+		return path[:index]
+	}
+	for _, segment := range []string{"/tasks", "/restart", "/versions"} {
+		if index := strings.Index(path, segment); index >= 0 &&
+			(len(path) == index+len(segment) || path[index+len(segment)] == '/') {
+			path = path[:index]
+		}
+	}
+	return path
+}
+
+// hasResourcePrefix checks if the path is the one of the resource or one of its subresources
+func hasResourcePrefix(path, resource string) bool {
+	return path == resource || strings.HasPrefix(path, resource+"/")
+}
+
+// invalidateEvent removes the cached responses affected by the event
+func (c *responseCache) invalidateEvent(event *Event) {
+	// step: events may have been missed while the stream was down
+	if event.ID == EventIDStreamReconnected {
+		c.invalidate(func(string) bool { return true })
+		return
+	}
+
+	// step: the collections and the groups are affected by any change
+	prefixes := []string{marathonAPIApps, marathonAPITasks, marathonAPIGroups, marathonAPIQueue}
+	if event.ID&(cacheDeploymentEvents|EventIDGroupChangeSuccess) != 0 {
+		prefixes = append(prefixes, marathonAPIDeployments)
+	}
+	if event.ID&(EventIDPods|EventIDInstances) != 0 {
+		prefixes = append(prefixes, marathonAPIPods)
+	}
+	ids := eventAppIDs(event)
+
+	c.invalidate(func(path string) bool {
+		for _, prefix := range prefixes {
+			if path == prefix || (prefix != marathonAPIApps && strings.HasPrefix(path, prefix+"/")) {
+				return true
+			}
+		}
+		// step: the applications and pods the event is about
+		for _, id := range ids {
+			for _, resource := range []string{marathonAPIApps, marathonAPIPods} {
+				resourcePath := resource + "/" + trimRootPath(id)
+				if path == resourcePath || strings.HasPrefix(path, resourcePath+"/") ||
+					strings.HasPrefix(path, resourcePath+"::") {
+					return true
+				}
+			}
+		}
+		return false
+	})
+}
+
+// invalidateOnEvents removes the cached responses affected by the events until the listener is removed
+func (r *marathonClient) invalidateOnEvents(events EventsChannel) {
+	for event := range events {
+		r.cache.invalidateEvent(event)
+	}
+}
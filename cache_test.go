@@ -0,0 +1,336 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingInterceptor counts the requests of the given path
+func countingInterceptor(path string, count *int32) Interceptor {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(request *http.Request) (*http.Response, error) {
+			if request.URL.Path == path {
+				atomic.AddInt32(count, 1)
+			}
+			return next(request)
+		}
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	var count int32
+	config := NewDefaultConfig()
+	config.Cache = &CachePolicy{
+		TTL:  time.Minute,
+		TTLs: map[string]time.Duration{"/v2/tasks": 0},
+	}
+	config.Interceptors = []Interceptor{countingInterceptor("/v2/apps", &count)}
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config})
+	defer endpoint.Close()
+	cache := endpoint.Client.(*marathonClient).cache
+
+	// step: the fresh response is served from the cache
+	for i := 0; i < 3; i++ {
+		applications, err := endpoint.Client.Applications(nil)
+		require.NoError(t, err)
+		assert.Len(t, applications.Apps, 2)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&count))
+
+	// step: the expired response is requested again
+	cache.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	_, err := endpoint.Client.Applications(nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&count))
+	cache.now = time.Now
+
+	// step: the changes made through the client invalidate the cache
+	_, err = endpoint.Client.Applications(nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&count))
+	_, err = endpoint.Client.CreateApplication(NewDockerApplication().Name(fakeAppName))
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&count))
+	_, err = endpoint.Client.Applications(nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&count))
+
+	// step: the paths with a zero TTL are not cached
+	_, err = endpoint.Client.AllTasks(nil)
+	require.NoError(t, err)
+	assert.NotContains(t, cache.entries, marathonAPITasks)
+}
+
+func TestCacheUncached(t *testing.T) {
+	var count int32
+	config := NewDefaultConfig()
+	config.Cache = &CachePolicy{TTL: time.Minute}
+	config.Interceptors = []Interceptor{countingInterceptor("/v2/apps", &count)}
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config})
+	defer endpoint.Close()
+	cache := endpoint.Client.(*marathonClient).cache
+
+	// step: the applications processed one at a time are streamed from Marathon
+	for i := 0; i < 2; i++ {
+		applications := 0
+		require.NoError(t, endpoint.Client.EachApplication(nil, func(*Application) error {
+			applications++
+			return nil
+		}))
+		assert.Equal(t, 2, applications)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&count))
+
+	// step: the deployments are polled by the waits
+	_, err := endpoint.Client.Deployments()
+	require.NoError(t, err)
+	assert.Empty(t, cache.entries)
+}
+
+func TestCacheInvalidatedInFlight(t *testing.T) {
+	var count int32
+	var cache *responseCache
+	config := NewDefaultConfig()
+	config.Cache = &CachePolicy{TTL: time.Minute}
+	config.Interceptors = []Interceptor{
+		countingInterceptor("/v2/apps", &count),
+		// step: a change is made while the first response is in flight
+		func(next RoundTripFunc) RoundTripFunc {
+			return func(request *http.Request) (*http.Response, error) {
+				response, err := next(request)
+				if request.URL.Path == "/v2/apps" && atomic.LoadInt32(&count) == 1 {
+					cache.invalidateWrite(marathonAPIApps + "/" + fakeAppName)
+				}
+				return response, err
+			}
+		},
+	}
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config})
+	defer endpoint.Close()
+	cache = endpoint.Client.(*marathonClient).cache
+
+	// step: the response predating the change is not cached
+	_, err := endpoint.Client.Applications(nil)
+	require.NoError(t, err)
+	assert.Empty(t, cache.entries)
+	_, err = endpoint.Client.Applications(nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&count))
+
+	// step: the next responses are cached again
+	_, err = endpoint.Client.Applications(nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&count))
+}
+
+func TestCacheInvalidateWrite(t *testing.T) {
+	paths := []string{
+		"v2/apps",
+		"v2/apps?embed=apps.tasks",
+		"v2/apps/foo",
+		"v2/apps/foo/tasks",
+		"v2/apps/foobar",
+		"v2/groups/prod",
+		"v2/pods/foo",
+		"v2/pods/foo::status",
+		"v2/queue",
+		"v2/info",
+	}
+	cases := []struct {
+		path      string
+		remaining []string
+	}{
+		{
+			path:      "v2/apps/foo?force=true",
+			remaining: []string{"v2/apps/foobar", "v2/info", "v2/pods/foo", "v2/pods/foo::status"},
+		},
+		{
+			path:      "v2/apps/foo/tasks/foo.1234?scale=true",
+			remaining: []string{"v2/apps/foobar", "v2/info", "v2/pods/foo", "v2/pods/foo::status"},
+		},
+		{
+			path:      "v2/pods/foo::instances/foo.1234",
+			remaining: []string{"v2/apps", "v2/apps/foo", "v2/apps/foo/tasks", "v2/apps/foobar", "v2/apps?embed=apps.tasks", "v2/info", "v2/queue"},
+		},
+		{
+			path:      "v2/queue/foo/delay",
+			remaining: []string{"v2/apps", "v2/apps/foo", "v2/apps/foo/tasks", "v2/apps/foobar", "v2/apps?embed=apps.tasks",
+				"v2/groups/prod", "v2/info", "v2/pods/foo", "v2/pods/foo::status"},
+		},
+		{
+			path:      "v2/groups/prod",
+			remaining: []string{"v2/info"},
+		},
+		{
+			path:      "v2/unknown",
+			remaining: []string{},
+		},
+	}
+	for _, x := range cases {
+		cache := newResponseCache(CachePolicy{TTL: time.Minute})
+		for _, path := range paths {
+			cache.put(path, &http.Response{Header: http.Header{}}, nil, cache.currentGeneration())
+		}
+		cache.invalidateWrite(x.path)
+
+		remaining := []string{}
+		for path := range cache.entries {
+			remaining = append(remaining, path)
+		}
+		sort.Strings(remaining)
+		assert.Equal(t, x.remaining, remaining, x.path)
+	}
+}
+
+func TestCacheConditionalRequests(t *testing.T) {
+	var full, notModified int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			writer.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(&full, 1)
+		writer.Header().Set("ETag", `"v1"`)
+		writer.Write([]byte(`{"apps": [{"id": "/fake-app"}]}`))
+	}))
+	defer server.Close()
+
+	config := NewDefaultConfig()
+	config.URL = server.URL
+	config.Cache = &CachePolicy{TTL: time.Minute}
+	client, err := NewClient(config)
+	require.NoError(t, err)
+	cache := client.(*marathonClient).cache
+
+	_, err = client.Applications(nil)
+	require.NoError(t, err)
+
+	// step: the expired response is revalidated
+	cache.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	applications, err := client.Applications(nil)
+	require.NoError(t, err)
+	if assert.Len(t, applications.Apps, 1) {
+		assert.Equal(t, "/fake-app", applications.Apps[0].ID)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&full))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&notModified))
+
+	// step: and fresh again
+	_, err = client.Applications(nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&notModified))
+}
+
+func TestCacheInvalidateEvent(t *testing.T) {
+	paths := []string{
+		"v2/apps",
+		"v2/apps?embed=apps.tasks",
+		"v2/apps/foo",
+		"v2/apps/foo/tasks",
+		"v2/apps/foobar",
+		"v2/apps/bar",
+		"v2/groups/prod",
+		"v2/deployments",
+		"v2/pods/foo::status",
+		"v2/info",
+	}
+	cases := []struct {
+		event     *Event
+		remaining []string
+	}{
+		{
+			event:     &Event{ID: EventIDStatusUpdate, Event: &EventStatusUpdate{AppID: "/foo"}},
+			remaining: []string{"v2/apps/bar", "v2/apps/foobar", "v2/deployments", "v2/info"},
+		},
+		{
+			event: &Event{ID: EventIDDeploymentSuccess, Event: &EventDeploymentSuccess{Plan: &DeploymentPlan{
+				Steps: []*StepActions{{Actions: []struct {
+					Action string `json:"action"`
+					Type   string `json:"type"`
+					App    string `json:"app"`
+				}{{App: "/bar"}}}},
+			}}},
+			remaining: []string{"v2/apps/foo", "v2/apps/foo/tasks", "v2/apps/foobar", "v2/info", "v2/pods/foo::status"},
+		},
+		{
+			event:     &Event{ID: EventIDPodUpdated, Event: &EventPodUpdated{}},
+			remaining: []string{"v2/apps/bar", "v2/apps/foo", "v2/apps/foo/tasks", "v2/apps/foobar", "v2/deployments", "v2/info"},
+		},
+		{
+			event:     &Event{ID: EventIDStreamReconnected, Event: &EventStreamReconnected{}},
+			remaining: []string{},
+		},
+	}
+	for _, x := range cases {
+		cache := newResponseCache(CachePolicy{TTL: time.Minute})
+		for _, path := range paths {
+			cache.put(path, &http.Response{Header: http.Header{}}, nil, cache.currentGeneration())
+		}
+		cache.invalidateEvent(x.event)
+
+		remaining := []string{}
+		for path := range cache.entries {
+			remaining = append(remaining, path)
+		}
+		sort.Strings(remaining)
+		assert.Equal(t, x.remaining, remaining, x.event.Name)
+	}
+}
+
+func TestCacheInvalidateOnEvents(t *testing.T) {
+	config := NewDefaultConfig()
+	config.EventsTransport = EventsTransportSSE
+	config.Cache = &CachePolicy{TTL: time.Minute, InvalidateOnEvents: true}
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config})
+	defer endpoint.Close()
+	cache := endpoint.Client.(*marathonClient).cache
+
+	_, err := endpoint.Client.Applications(nil)
+	require.NoError(t, err)
+	_, err = endpoint.Client.Info()
+	require.NoError(t, err)
+
+	time.Sleep(SSEConnectWaitTime)
+	endpoint.Server.PublishEvent(testCases.find("status_update_event").source)
+
+	deadline := time.Now().Add(eventPublishTimeout)
+	for {
+		cache.Lock()
+		_, apps := cache.entries[marathonAPIApps]
+		_, info := cache.entries[marathonAPIInfo]
+		cache.Unlock()
+		if !apps {
+			assert.True(t, info)
+			break
+		}
+		if time.Now().After(deadline) {
+			assert.Fail(t, "the cache was not invalidated in time")
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
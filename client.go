@@ -18,17 +18,18 @@ package marathon
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"net/url"
 	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -60,6 +61,8 @@ type Marathon interface {
 	RestartApplication(name string, force bool) (*DeploymentID, error)
 	// get a list of applications from marathon
 	Applications(url.Values) (*Applications, error)
+	// call the function for each application, decoding them one at a time
+	EachApplication(v url.Values, fn func(*Application) error) error
 	// get an application by name
 	Application(name string) (*Application, error)
 	// get an application by options
@@ -68,6 +71,8 @@ type Marathon interface {
 	ApplicationByVersion(name, version string) (*Application, error)
 	// wait of application
 	WaitOnApplication(name string, timeout time.Duration) error
+	// bring the applications to their desired state
+	Apply(ctx context.Context, desired []*Application, opts *ApplyOptions) (*ApplyPlan, error)
 
 	// -- PODS ---
 	// whether which version of Marathon supports pods
@@ -138,6 +143,8 @@ type Marathon interface {
 	HasGroup(name string) (bool, error)
 	// wait for an group to be deployed
 	WaitOnGroup(name string, timeout time.Duration) error
+	// bring the applications of a group to their desired state
+	ApplyGroup(ctx context.Context, group *Group, opts *ApplyOptions) (*ApplyPlan, error)
 
 	// --- DEPLOYMENTS ---
 
@@ -156,12 +163,18 @@ type Marathon interface {
 	Subscriptions() (*Subscriptions, error)
 	// add a events listener
 	AddEventsListener(filter int) (EventsChannel, error)
+	// add a events listener with the given queue options
+	AddEventsListenerWithOptions(filter int, options EventsListenerOptions) (EventsChannel, error)
+	// get the state of the queue of a events listener
+	EventsListenerStats(channel EventsChannel) (EventsListenerStats, bool)
 	// remove a events listener
 	RemoveEventsListener(channel EventsChannel)
 	// Subscribe a callback URL
 	Subscribe(string) error
 	// Unsubscribe a callback URL
 	Unsubscribe(string) error
+	// handle the events matching the listener ID filter and the event filters
+	OnEvent(filter int, handler func(*Event), filters ...EventFilter) (*EventListener, error)
 
 	// --- QUEUE ---
 	// get marathon launch queue
@@ -181,6 +194,14 @@ type Marathon interface {
 	Leader() (string, error)
 	// cause the current leader to abdicate
 	AbdicateLeader() (string, error)
+	// get the members of the cluster and their status
+	Members() []Member
+	// release all the resources held by the client
+	Close() error
+	// get a copy of the client whose calls are bound to the given context
+	WithContext(ctx context.Context) Marathon
+	// get a copy of the client which resolves the conflicts of its calls with locking deployments
+	WithConflictResolution(resolution *ConflictResolution) Marathon
 }
 
 var (
@@ -188,6 +209,8 @@ var (
 	ErrMarathonDown = errors.New("all the Marathon hosts are presently down")
 	// ErrTimeoutError is thrown when the operation has timed out
 	ErrTimeoutError = errors.New("the operation has timed out")
+	// ErrClientClosed is thrown when the client has already been closed
+	ErrClientClosed = errors.New("the client has been closed")
 )
 
 // EventsChannelContext holds contextual data for an EventsChannel.
@@ -195,26 +218,47 @@ type EventsChannelContext struct {
 	filter     int
 	done       chan struct{}
 	completion *sync.WaitGroup
+	queue      *eventsQueue
+	// internal marks the listeners of the client itself, which ride on the subscription without managing it
+	internal bool
 }
 
 type marathonClient struct {
-	sync.RWMutex
+	// the state shared with all the clients derived through WithContext
+	*clientState
 	// the configuration for the client
 	config Config
+	// the context the API calls of this client are bound to
+	ctx context.Context
+	// the marathon hosts
+	hosts *cluster
+	// the logger of the diagnostics
+	logger Logger
+	// the marathon HTTP client to ensure consistency in requests
+	client *httpClient
+	// the cache of the responses to GET requests, nil when disabled
+	cache *responseCache
+}
+
+// clientState holds the mutable state of a client, which is shared between
+// the client and all the copies derived from it through WithContext.
+type clientState struct {
+	sync.RWMutex
+	// the context of the background work of the client, it is cancelled on Close
+	background context.Context
+	// cancels the background context
+	cancel context.CancelFunc
+	// the flag set once the client has been closed, accessed atomically as the API calls check it while the
+	// lock may be held
+	closed int32
 	// the flag used to prevent multiple SSE subscriptions
 	subscribedToSSE bool
 	// the ip address of the client
 	ipAddress string
 	// the http server
 	eventsHTTP *http.Server
-	// the marathon hosts
-	hosts *cluster
 	// a map of service you wish to listen to
 	listeners map[EventsChannel]EventsChannelContext
-	// a custom logger for debug log messages
-	debugLog *log.Logger
-	// the marathon HTTP client to ensure consistency in requests
-	client *httpClient
 }
 
 type httpClient struct {
@@ -243,24 +287,210 @@ func NewClient(config Config) (Marathon, error) {
 	// step: setup shared client
 	client := &httpClient{config: config}
 
+	// step: discover the initial members, falling back to the configured url
+	marathonURL := config.URL
+	if config.MemberSource != nil {
+		members, err := discoverMembers(config.MemberSource)
+		switch {
+		case err == nil:
+			marathonURL = members
+		case marathonURL == "":
+			return nil, err
+		}
+	}
+
 	// step: create a new cluster
-	hosts, err := newCluster(client, config.URL, config.DCOSToken != "")
+	hosts, err := newCluster(client, marathonURL, config.DCOSToken != "" || isDCOSAuthenticator(config.Authenticator))
 	if err != nil {
 		return nil, err
 	}
 
-	debugLogOutput := config.LogOutput
-	if debugLogOutput == nil {
-		debugLogOutput = ioutil.Discard
+	background, cancel := context.WithCancel(context.Background())
+	marathon := &marathonClient{
+		clientState: &clientState{
+			background: background,
+			cancel:     cancel,
+			listeners:  make(map[EventsChannel]EventsChannelContext),
+		},
+		config: config,
+		ctx:    background,
+		hosts:  hosts,
+		logger: newLogger(config),
+		client: client,
+	}
+
+	// step: keep the members up to date
+	if config.MemberSource != nil {
+		go marathon.refreshMembers()
+	}
+
+	// step: cache the responses, invalidating them on the events affecting them
+	if config.Cache != nil {
+		marathon.cache = newResponseCache(*config.Cache)
+		if config.Cache.InvalidateOnEvents {
+			events, err := marathon.AddEventsListener(cacheEventsFilter)
+			if err != nil {
+				marathon.Close()
+				return nil, err
+			}
+			go marathon.invalidateOnEvents(events)
+		}
+	}
+
+	return marathon, nil
+}
+
+// WithContext returns a copy of the client whose API calls are bound to the given context. Cancelling the
+// context aborts any in-flight request, stops the failover to other cluster members and ends any pending WaitOn* call.
+// The copy shares the cluster members and the event listeners with the client it was derived from.
+//		ctx:		the context to bind the API calls to
+func (r *marathonClient) WithContext(ctx context.Context) Marathon {
+	return r.withContext(ctx)
+}
+
+func (r *marathonClient) withContext(ctx context.Context) *marathonClient {
+	if ctx == nil {
+		panic("nil context")
+	}
+	client := *r
+	client.ctx = ctx
+
+	return &client
+}
+
+// WithConflictResolution returns a copy of the client which resolves the conflicts of its updates, scalings and
+// deletions with locking deployments as given, instead of as configured by Config.ConflictResolution. The copy
+// shares the cluster members and the event listeners with the client it was derived from.
+//		resolution:	the resolution of the conflicts, nil returns the conflicts to the caller
+func (r *marathonClient) WithConflictResolution(resolution *ConflictResolution) Marathon {
+	client := *r
+	client.config.ConflictResolution = resolution
+
+	return &client
+}
+
+// detached returns a copy of the client which is not bound to the context of the caller, but to the lifetime of
+// the client. It is used for background work, like consuming the event stream, which outlives the call that started it.
+func (r *marathonClient) detached() *marathonClient {
+	return r.withContext(r.background)
+}
+
+// Close releases all the resources held by the client: it removes the callback subscription and shuts down the
+// events HTTP server, stops consuming the event stream, stops the background health checks and member discovery,
+// and closes the channels of all the events listeners. Calls made through the client or its copies afterwards fail
+// with ErrClientClosed.
+func (r *marathonClient) Close() error {
+	r.Lock()
+	if r.isClosed() {
+		r.Unlock()
+		return nil
+	}
+	atomic.StoreInt32(&r.closed, 1)
+	listeners := r.listeners
+	r.listeners = make(map[EventsChannel]EventsChannelContext)
+	eventsHTTP := r.eventsHTTP
+	r.Unlock()
+
+	var err error
+	if eventsHTTP != nil {
+		// step: remove ourselves from the events callback and stop receiving events, the client being closed already
+		err = r.detached().performAPICall("DELETE", subscriptionPath(r.SubscriptionURL()), nil, nil)
+
+		ctx, cancel := context.WithTimeout(context.Background(), eventsShutdownTimeout)
+		if shutdownErr := eventsHTTP.Shutdown(ctx); err == nil {
+			err = shutdownErr
+		}
+		cancel()
+	}
+
+	// step: stop all the background work
+	r.cancel()
+	r.hosts.close()
+
+	// step: wait for pending deliveries to be abandoned and close the channels
+	for channel, listener := range listeners {
+		close(listener.done)
+		listener.completion.Wait()
+		close(channel)
+	}
+
+	return err
+}
+
+// waitUntil evaluates the condition right away, then every PollingWaitTime until it is met or fails. The first
+// evaluation is not bound to the timeout, so that a zero timeout checks the condition once. The next ones are
+// handed a copy of the client bound to the timeout, so that in-flight requests are aborted once it expires. All
+// bypass the cache of the responses so that the state they poll is current. It returns ErrTimeoutError when the
+// timeout expires and the context error when the client context is done.
+// While the client receives events, the watch is handed the events of interest to waits: those it reports
+// relevant trigger an immediate evaluation of the condition, and an error reported by it ends the wait.
+//		timeout:	the maximum time to wait
+//		watch:		the function watching the events, optional
+//		condition:	the condition to wait for
+func (r *marathonClient) waitUntil(timeout time.Duration, watch eventsWatch, condition func(client *marathonClient) (bool, error)) error {
+	var events EventsChannel
+	if watch != nil {
+		if events = r.waitEvents(); events != nil {
+			defer r.RemoveEventsListener(events)
+		}
 	}
 
-	return &marathonClient{
-		config:    config,
-		listeners: make(map[EventsChannel]EventsChannelContext),
-		hosts:     hosts,
-		debugLog:  log.New(debugLogOutput, "", 0),
-		client:    client,
-	}, nil
+	// step: the condition may be met already, it is evaluated once the events are listened to so that none
+	// making it met is missed
+	client := r.withContext(r.ctx)
+	client.cache = nil
+	if done, err := condition(client); err != nil || done {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(r.ctx, timeout)
+	defer cancel()
+	client = r.withContext(ctx)
+	client.cache = nil
+
+	ticker := time.NewTicker(r.config.PollingWaitTime)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return r.waitError()
+		case <-ticker.C:
+		case event, ok := <-events:
+			if !ok {
+				// step: the client has been closed, the context is done as well
+				events = nil
+				continue
+			}
+			relevant, err := watch(client, event)
+			if err != nil {
+				return err
+			}
+			if !relevant {
+				continue
+			}
+		}
+
+		done, err := condition(client)
+		if ctx.Err() != nil {
+			return r.waitError()
+		}
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+	}
+}
+
+// waitError returns the error of an expired wait, i.e. the context error when the client context is done
+// and ErrTimeoutError otherwise
+func (r *marathonClient) waitError() error {
+	if err := r.ctx.Err(); err != nil {
+		return err
+	}
+	return ErrTimeoutError
 }
 
 // GetMarathonURL retrieves the marathon url
@@ -296,10 +526,82 @@ func (r *marathonClient) apiDelete(path string, post, result interface{}) error
 	return r.apiCall("DELETE", path, post, result)
 }
 
+// apiCall performs an API call, which fails with ErrClientClosed once the client, or the client it was copied
+// from, has been closed
 func (r *marathonClient) apiCall(method, path string, body, result interface{}) error {
-	const deploymentHeader = "Marathon-Deployment-Id"
+	if r.isClosed() {
+		return ErrClientClosed
+	}
+	err := r.performAPICall(method, path, body, result)
+	// step: the calls aborted by Close fail as the calls made afterwards
+	if err != nil && err == r.ctx.Err() && r.isClosed() {
+		return ErrClientClosed
+	}
+
+	return err
+}
+
+// isClosed checks if the client has been closed
+func (r *marathonClient) isClosed() bool {
+	return atomic.LoadInt32(&r.closed) == 1
+}
+
+// performAPICall performs an API call, reporting it to the metrics and invalidating the cached responses it affects
+func (r *marathonClient) performAPICall(method, path string, body, result interface{}) error {
+	call := APICallInfo{Method: method, Path: pathTemplate(path)}
+
+	// step: the requests of the call are sent with the context of its span
+	ctx, span := r.client.metrics().StartAPICall(r.ctx, method, call.Path)
+	client := r
+	if ctx != r.ctx {
+		client = r.withContext(ctx)
+	}
+
+	start := time.Now()
+	err := client.sendAPICall(method, path, body, result, &call)
+	call.Duration = time.Since(start)
+	call.Err = err
+	span.End(call)
+
+	// step: the changes made through the client affect the cached responses of the resources they touch
+	if r.cache != nil && method != "GET" && method != "HEAD" {
+		r.cache.invalidateWrite(path)
+	}
+
+	return err
+}
+
+// sendAPICall performs the API call, retrying it according to the retry policy
+//		method:		the HTTP method
+//		path:		the path of the API call
+//		body:		the request to send as json, if any
+//		result:		the value to unmarshal the response into, if any
+//		call:		the description of the call, updated after each request
+func (r *marathonClient) sendAPICall(method, path string, body, result interface{}, call *APICallInfo) error {
+	reauthenticated := false
+
+	// step: serve a fresh cached response, or revalidate an expired one. The responses processed one item at a
+	// time are not cached, so they don't have to be held in memory.
+	var cached *cacheEntry
+	var generation uint64
+	_, streamed := result.(responseStream)
+	cacheable := r.cache != nil && method == "GET" && !streamed && r.cache.ttl(path) > 0
+	if cacheable {
+		generation = r.cache.currentGeneration()
+		entry, fresh := r.cache.get(path)
+		if fresh {
+			call.Cached = true
+			return decodeResponse(bytes.NewReader(entry.body), entry.header, result)
+		}
+		cached = entry
+	}
+
+	for attempt := 1; ; attempt++ {
+		// step: give up once the context has been cancelled or has expired
+		if err := r.ctx.Err(); err != nil {
+			return err
+		}
 
-	for {
 		// step: marshall the request to json
 		var requestBody []byte
 		var err error
@@ -315,58 +617,110 @@ func (r *marathonClient) apiCall(method, path string, body, result interface{})
 			return err
 		}
 
+		if cached != nil {
+			if cached.etag != "" {
+				request.Header.Set("If-None-Match", cached.etag)
+			}
+			if cached.lastModified != "" {
+				request.Header.Set("If-Modified-Since", cached.lastModified)
+			}
+		}
+
 		// step: perform the API request
+		start := time.Now()
 		response, err := r.client.Do(request)
 
+		sent := APICallInfo{Method: method, Path: call.Path, Member: member, Retries: attempt - 1,
+			Duration: time.Since(start), Err: err}
+		if response != nil {
+			sent.StatusCode = response.StatusCode
+		}
+		r.client.metrics().ObserveRequest(sent)
+		call.Member, call.StatusCode, call.Retries = sent.Member, sent.StatusCode, sent.Retries
+
 		if err != nil {
+			// step: a cancelled request is not the fault of the member, so don't mark it down
+			if ctxErr := r.ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
 			r.hosts.markDown(member)
+			if !r.config.RetryPolicy.retryable(attempt, method, nil, err) {
+				return err
+			}
 			// step: attempt the request on another member
-			r.debugLog.Printf("apiCall(): request failed on host: %s, error: %s, trying another\n", member, err)
+			r.logger.Warn("apiCall(): request failed, trying another member", "method", method, "url", logURL(request.URL),
+				"member", member, "duration", sent.Duration, "error", err)
+			if err := r.sleep(r.config.RetryPolicy.backoff(attempt, nil)); err != nil {
+				return err
+			}
 			continue
 		}
 		defer response.Body.Close()
 
+		// step: keep track of the leader for routing the next requests
+		r.trackLeader(request, response)
+
+		// step: decode a successful response directly from the body
+		if response.StatusCode >= 200 && response.StatusCode <= 299 {
+			r.logger.Debug("apiCall(): request completed", "method", method, "url", logURL(request.URL), "member", member,
+				"status", response.StatusCode, "duration", sent.Duration, "header", logHeader(request.Header),
+				"request", logPayload(requestBody))
+
+			if !cacheable {
+				return decodeResponse(response.Body, response.Header, result)
+			}
+			// step: decode the response while it is read, keeping a copy of the body to cache
+			buffer := new(bytes.Buffer)
+			if err := decodeResponse(io.TeeReader(response.Body, buffer), response.Header, result); err != nil {
+				return err
+			}
+			if _, err := io.Copy(buffer, response.Body); err != nil {
+				return err
+			}
+			r.cache.put(path, response, buffer.Bytes(), generation)
+			return nil
+		}
+
+		// step: the cached response is still valid
+		if response.StatusCode == http.StatusNotModified && cached != nil {
+			r.cache.revalidated(path, cached)
+			call.Cached = true
+			return decodeResponse(bytes.NewReader(cached.body), cached.header, result)
+		}
+
 		// step: read the response body
 		respBody, err := ioutil.ReadAll(response.Body)
 		if err != nil {
 			return err
 		}
 
-		if len(requestBody) > 0 {
-			r.debugLog.Printf("apiCall(): %v %v %s returned %v %s\n", request.Method, request.URL.String(), requestBody, response.Status, oneLogLine(respBody))
-		} else {
-			r.debugLog.Printf("apiCall(): %v %v returned %v %s\n", request.Method, request.URL.String(), response.Status, oneLogLine(respBody))
-		}
-
-		// step: check for a successfull response
-		if response.StatusCode >= 200 && response.StatusCode <= 299 {
-
-			if result != nil {
-				// If we have a deployment ID header and no response body, give them that
-				deploymentID := response.Header.Get(deploymentHeader)
-				if len(respBody) == 0 && deploymentID != "" {
-					d := DeploymentID{
-						DeploymentID: deploymentID,
-					}
-					switch result.(type) {
-					case *DeploymentID:
-						*result.(*DeploymentID) = d
-					}
-
-				} else {
-					if err := json.Unmarshal(respBody, result); err != nil {
-						return fmt.Errorf("failed to unmarshal response from Marathon: %s", err)
-					}
-				}
-			}
-			return nil
+		r.logger.Debug("apiCall(): request failed", "method", method, "url", logURL(request.URL), "member", member,
+			"status", response.StatusCode, "duration", sent.Duration, "header", logHeader(request.Header),
+			"request", logPayload(requestBody), "response", logPayload(respBody))
+
+		// step: retry once with fresh credentials if the current ones have been rejected, which doesn't count
+		// as an attempt of the retry policy
+		if response.StatusCode == http.StatusUnauthorized && !reauthenticated && r.config.Authenticator != nil &&
+			r.config.Authenticator.Refresh(request) {
+			reauthenticated = true
+			r.logger.Info("apiCall(): request unauthorized, retrying with fresh credentials", "method", method,
+				"url", logURL(request.URL), "member", member)
+			attempt--
+			continue
 		}
 
 		// step: if the member node returns a >= 500 && <= 599 we should try another node?
 		if response.StatusCode >= 500 && response.StatusCode <= 599 {
 			// step: mark the host as down
 			r.hosts.markDown(member)
-			r.debugLog.Printf("apiCall(): request failed, host: %s, status: %d, trying another\n", member, response.StatusCode)
+			if !r.config.RetryPolicy.retryable(attempt, method, response, nil) {
+				return NewAPIError(response.StatusCode, respBody)
+			}
+			r.logger.Warn("apiCall(): request failed, trying another member", "method", method, "url", logURL(request.URL),
+				"member", member, "status", response.StatusCode, "duration", sent.Duration)
+			if err := r.sleep(r.config.RetryPolicy.backoff(attempt, response)); err != nil {
+				return err
+			}
 			continue
 		}
 
@@ -374,26 +728,104 @@ func (r *marathonClient) apiCall(method, path string, body, result interface{})
 	}
 }
 
+// responseStream is implemented by the results of API calls which decode the response on their own, e.g. to
+// process its items one at a time
+type responseStream interface {
+	// decode decodes the response, it returns the errors of the processing of the items as they are
+	decode(decoder *json.Decoder) error
+}
+
+// decodeResponse decodes a successful response into the result, streaming it from the body
+//		body:		the body of the response
+//		header:		the header of the response
+//		result:		the value to decode the response into, nil discards it
+func decodeResponse(body io.Reader, header http.Header, result interface{}) error {
+	const deploymentHeader = "Marathon-Deployment-Id"
+
+	if result == nil {
+		// step: consume the body so the connection can be reused
+		_, err := io.Copy(ioutil.Discard, body)
+		return err
+	}
+
+	if stream, ok := result.(responseStream); ok {
+		return stream.decode(json.NewDecoder(body))
+	}
+
+	err := json.NewDecoder(body).Decode(result)
+	if err == io.EOF {
+		// If we have a deployment ID header and no response body, give them that
+		if deploymentID := header.Get(deploymentHeader); deploymentID != "" {
+			if id, ok := result.(*DeploymentID); ok {
+				*id = DeploymentID{DeploymentID: deploymentID}
+			}
+			return nil
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal response from Marathon: %s", err)
+	}
+	return nil
+}
+
+// trackLeader updates the leader of the cluster from the leader header of the response. A followed redirect
+// or an unavailable member indicate that the leader has changed, so it will be looked up again.
+func (r *marathonClient) trackLeader(request *http.Request, response *http.Response) {
+	const leaderHeader = "X-Marathon-Leader"
+
+	if !r.config.RouteToLeader {
+		return
+	}
+	switch {
+	case response.Header.Get(leaderHeader) != "":
+		r.hosts.setLeader(response.Header.Get(leaderHeader))
+	case response.StatusCode == http.StatusServiceUnavailable:
+		r.hosts.invalidateLeader()
+	case response.Request != nil && response.Request.URL.Host != request.URL.Host:
+		r.hosts.invalidateLeader()
+	}
+}
+
+// sleep waits for the given duration, returning early with the context error when the context is done
+func (r *marathonClient) sleep(duration time.Duration) error {
+	if duration <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-r.ctx.Done():
+		return r.ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
 // buildAPIRequest creates a default API request.
 // It fails when there is no available member in the cluster anymore or when the request can not be built.
 func (r *marathonClient) buildAPIRequest(method, path string, reader io.Reader) (request *http.Request, member string, err error) {
 	// Grab a member from the cluster
-	member, err = r.hosts.getMember()
+	member, err = r.hosts.getMemberFor(r.ctx, method)
 	if err != nil {
 		return nil, "", ErrMarathonDown
 	}
 
-	// Build the HTTP request to Marathon
-	request, err = r.client.buildMarathonRequest(method, member, path, reader)
+	// Build the HTTP request to Marathon, the authentication is bound to the context of the call as well
+	request, err = r.client.buildMarathonRequest(r.ctx, method, member, path, reader)
 	if err != nil {
+		if _, ok := err.(*AuthenticationError); ok {
+			return nil, member, err
+		}
 		return nil, member, newRequestError{err}
 	}
 	return request, member, nil
 }
 
-// buildMarathonRequest creates a new HTTP request and configures it according to the *httpClient configuration.
-// The path must not contain a leading "/", otherwise buildMarathonRequest will panic.
-func (rc *httpClient) buildMarathonRequest(method string, member string, path string, reader io.Reader) (request *http.Request, err error) {
+// buildMarathonRequest creates a new HTTP request bound to the context and configures it according to the
+// *httpClient configuration. The path must not contain a leading "/", otherwise buildMarathonRequest will panic.
+func (rc *httpClient) buildMarathonRequest(ctx context.Context, method string, member string, path string, reader io.Reader) (request *http.Request, err error) {
 	if strings.HasPrefix(path, "/") {
 		panic(fmt.Sprintf("Path '%s' must not start with a leading slash", path))
 	}
@@ -406,14 +838,11 @@ func (rc *httpClient) buildMarathonRequest(method string, member string, path st
 	if err != nil {
 		return nil, err
 	}
+	request = request.WithContext(ctx)
 
-	// Add any basic auth and the content headers
-	if rc.config.HTTPBasicAuthUser != "" && rc.config.HTTPBasicPassword != "" {
-		request.SetBasicAuth(rc.config.HTTPBasicAuthUser, rc.config.HTTPBasicPassword)
-	}
-
-	if rc.config.DCOSToken != "" {
-		request.Header.Add("Authorization", "token="+rc.config.DCOSToken)
+	// Add any credentials and the content headers
+	if err := rc.authenticate(request); err != nil {
+		return nil, err
 	}
 
 	request.Header.Add("Content-Type", "application/json")
@@ -422,8 +851,31 @@ func (rc *httpClient) buildMarathonRequest(method string, member string, path st
 	return request, nil
 }
 
+// authenticate adds the credentials to the request, either from the authenticator or from the basic auth and
+// the DC/OS token of the configuration. Any failure is returned as an *AuthenticationError.
+func (rc *httpClient) authenticate(request *http.Request) error {
+	if rc.config.Authenticator == nil {
+		if rc.config.HTTPBasicAuthUser != "" && rc.config.HTTPBasicPassword != "" {
+			request.SetBasicAuth(rc.config.HTTPBasicAuthUser, rc.config.HTTPBasicPassword)
+		}
+		if rc.config.DCOSToken != "" {
+			request.Header.Add("Authorization", "token="+rc.config.DCOSToken)
+		}
+		return nil
+	}
+
+	if err := rc.config.Authenticator.Authenticate(request); err != nil {
+		if _, ok := err.(*AuthenticationError); ok {
+			return err
+		}
+		return &AuthenticationError{Err: err}
+	}
+	return nil
+}
+
+// Do sends the request through the interceptors of the configuration
 func (rc *httpClient) Do(request *http.Request) (response *http.Response, err error) {
-	return rc.config.HTTPClient.Do(request)
+	return chainInterceptors(rc.config.HTTPClient.Do, rc.config.Interceptors)(request)
 }
 
 var oneLogLineRegex = regexp.MustCompile(`(?m)^\s*`)
@@ -17,10 +17,14 @@ limitations under the License.
 package marathon
 
 import (
+	"context"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewClient(t *testing.T) {
@@ -135,6 +139,150 @@ func TestAPIRequest(t *testing.T) {
 	}
 }
 
+func TestWithContext(t *testing.T) {
+	endpoint := newFakeMarathonEndpoint(t, nil)
+	defer endpoint.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client := endpoint.Client.WithContext(ctx)
+	_, err := client.Applications(nil)
+	assert.NoError(t, err)
+
+	cancel()
+	_, err = client.Applications(nil)
+	assert.Equal(t, context.Canceled, err)
+
+	// the cancellation must neither mark members down nor affect the original client
+	hosts := endpoint.Client.(*marathonClient).hosts
+	assert.Equal(t, hosts.size(), len(hosts.activeMembers()))
+	_, err = endpoint.Client.Applications(nil)
+	assert.NoError(t, err)
+}
+
+func TestWithContextWaitOn(t *testing.T) {
+	clientCfg := NewDefaultConfig()
+	clientCfg.PollingWaitTime = 10 * time.Millisecond
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &clientCfg})
+	defer endpoint.Close()
+
+	// the fake deployment never finishes, so only the timeout or the context can end the wait
+	err := endpoint.Client.WaitOnDeployment(fakeDeploymentID, 50*time.Millisecond)
+	assert.Equal(t, ErrTimeoutError, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = endpoint.Client.WithContext(ctx).WaitOnDeployment(fakeDeploymentID, time.Minute)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestCloseSSE(t *testing.T) {
+	clientCfg := NewDefaultConfig()
+	clientCfg.EventsTransport = EventsTransportSSE
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &clientCfg})
+	defer endpoint.Close()
+
+	events, err := endpoint.Client.AddEventsListener(EventIDApplications)
+	require.NoError(t, err)
+	time.Sleep(SSEConnectWaitTime)
+	copied := endpoint.Client.WithContext(context.Background())
+
+	assert.NoError(t, endpoint.Client.Close())
+	_, more := <-events
+	assert.False(t, more, "the events channel should have been closed")
+
+	_, err = endpoint.Client.AddEventsListener(EventIDApplications)
+	assert.Equal(t, ErrClientClosed, err)
+	_, err = endpoint.Client.Applications(nil)
+	assert.Equal(t, ErrClientClosed, err)
+	_, err = copied.Applications(nil)
+	assert.Equal(t, ErrClientClosed, err)
+	assert.NoError(t, endpoint.Client.Close())
+
+	// closing the client must not have marked any member down
+	hosts := endpoint.Client.(*marathonClient).hosts
+	assert.Equal(t, hosts.size(), len(hosts.activeMembers()))
+}
+
+func TestCloseInFlight(t *testing.T) {
+	received := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(received)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	config := NewDefaultConfig()
+	config.URL = server.URL
+	client, err := NewClient(config)
+	require.NoError(t, err)
+
+	result := make(chan error)
+	go func() {
+		_, err := client.Applications(nil)
+		result <- err
+	}()
+	<-received
+
+	assert.NoError(t, client.Close())
+	assert.Equal(t, ErrClientClosed, <-result)
+}
+
+func TestCloseHungMember(t *testing.T) {
+	pinged := make(chan struct{}, 1)
+	aborted := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// step: the member never answers the health checks
+		if r.URL.Path == "/ping" {
+			pinged <- struct{}{}
+			<-r.Context().Done()
+			aborted <- struct{}{}
+		}
+	}))
+	defer server.Close()
+	defer server.CloseClientConnections()
+
+	config := NewDefaultConfig()
+	config.URL = server.URL
+	client, err := NewClient(config)
+	require.NoError(t, err)
+	hosts := client.(*marathonClient).hosts
+	hosts.markDown(server.URL)
+	<-pinged
+
+	// step: the pending health check is aborted
+	assert.NoError(t, client.Close())
+	select {
+	case <-aborted:
+	case <-time.After(time.Second):
+		assert.Fail(t, "the health check was not aborted in time")
+	}
+}
+
+func TestCloseCallback(t *testing.T) {
+	clientCfg := NewDefaultConfig()
+	clientCfg.EventsInterface = "lo"
+	clientCfg.EventsPort = 0
+	clientCfg.CallbackURL = "http://localhost:9292"
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{
+		client: &clientCfg,
+		server: &serverConfig{scope: "close"},
+	})
+	defer endpoint.Close()
+
+	events, err := endpoint.Client.AddEventsListener(EventIDApplications)
+	require.NoError(t, err)
+
+	assert.NoError(t, endpoint.Client.Close())
+	_, more := <-events
+	assert.False(t, more, "the events channel should have been closed")
+
+	unsubscribeKey := fakeResponseMapKey("DELETE", "/v2/eventSubscriptions?callbackUrl=http://localhost:9292/event", "close")
+	indices := endpoint.Server.fakeRespIndices
+	indices.Lock()
+	defer indices.Unlock()
+	assert.Equal(t, 1, indices.m[unsubscribeKey])
+}
+
 func TestBuildApiRequestFailure(t *testing.T) {
 	tests := []struct {
 		name              string
@@ -17,7 +17,10 @@ limitations under the License.
 package marathon
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"net"
 	"net/url"
 	"strings"
 	"sync"
@@ -29,6 +32,12 @@ const (
 	memberStatusDown = 1
 )
 
+// leaderRetryInterval is the time to wait after a failed lookup of the leader before looking it up again
+const leaderRetryInterval = 5 * time.Second
+
+// healthCheckTimeout is the time a member down has to answer a health check
+const healthCheckTimeout = 5 * time.Second
+
 // the status of a member node
 type memberStatus int
 
@@ -39,6 +48,20 @@ type cluster struct {
 	members []*member
 	// the marathon HTTP client to ensure consistency in requests
 	client *httpClient
+	// whether the members are DCOS endpoints
+	isDCOS bool
+	// the endpoint of the member which is the current leader, if known
+	leader string
+	// whether the leader has to be looked up again before routing the next request to it
+	leaderStale bool
+	// closed once the running lookup of the leader completes, nil when none is running
+	leaderLookup chan struct{}
+	// the time before which the leader is not looked up again, after a failed lookup
+	leaderRetry time.Time
+	// the index of the member which served the last balanced read
+	lastRead int
+	// closed when the cluster is no longer used
+	done chan struct{}
 }
 
 // member represents an individual endpoint
@@ -47,12 +70,34 @@ type member struct {
 	endpoint string
 	// the status of the host
 	status memberStatus
+	// closed when the member is removed from the cluster
+	removed chan struct{}
 }
 
 // newCluster returns a new marathon cluster
 func newCluster(client *httpClient, marathonURL string, isDCOS bool) (*cluster, error) {
-	// step: extract and basic validate the endpoints
+	endpoints, err := parseEndpoints(marathonURL, isDCOS)
+	if err != nil {
+		return nil, err
+	}
+
 	var members []*member
+	for _, endpoint := range endpoints {
+		members = append(members, newMember(endpoint))
+	}
+
+	return &cluster{
+		client:      client,
+		members:     members,
+		isDCOS:      isDCOS,
+		leaderStale: true,
+		done:        make(chan struct{}),
+	}, nil
+}
+
+// parseEndpoints extracts and validates the endpoints of the comma separated marathon urls
+func parseEndpoints(marathonURL string, isDCOS bool) ([]string, error) {
+	var endpoints []string
 	var defaultProto string
 
 	for _, endpoint := range strings.Split(marathonURL, ",") {
@@ -89,14 +134,51 @@ func newCluster(client *httpClient, marathonURL string, isDCOS bool) (*cluster,
 			u.Path = defaultDCOSPath
 		}
 
-		// step: create a new node for this endpoint
-		members = append(members, &member{endpoint: u.String()})
+		endpoints = append(endpoints, u.String())
 	}
 
-	return &cluster{
-		client:  client,
-		members: members,
-	}, nil
+	return endpoints, nil
+}
+
+// newMember creates a new active member for the endpoint
+func newMember(endpoint string) *member {
+	return &member{endpoint: endpoint, removed: make(chan struct{})}
+}
+
+// setMembers replaces the members of the cluster by the given comma separated marathon urls. Members which
+// are still part of the cluster keep their status, the health checks of the removed ones are stopped.
+func (c *cluster) setMembers(marathonURL string) error {
+	endpoints, err := parseEndpoints(marathonURL, c.isDCOS)
+	if err != nil {
+		return err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	current := make(map[string]*member)
+	for _, n := range c.members {
+		current[n.endpoint] = n
+	}
+
+	var members []*member
+	for _, endpoint := range endpoints {
+		if n, found := current[endpoint]; found {
+			members = append(members, n)
+			delete(current, endpoint)
+		} else {
+			members = append(members, newMember(endpoint))
+		}
+	}
+	for _, n := range current {
+		close(n.removed)
+		if n.endpoint == c.leader {
+			c.leader = ""
+			c.leaderStale = true
+		}
+	}
+	c.members = members
+
+	return nil
 }
 
 // retrieve the current member, i.e. the current endpoint in use
@@ -112,15 +194,165 @@ func (c *cluster) getMember() (string, error) {
 	return "", ErrMarathonDown
 }
 
+// getMemberFor retrieves the member a request of the given method should be sent to. Unless routing to the
+// leader is enabled, this is the current member. Otherwise reads are balanced over the followers if requested,
+// and all other requests go to the leader, falling back to the current member while the leader is unknown.
+func (c *cluster) getMemberFor(ctx context.Context, method string) (string, error) {
+	if !c.client.config.RouteToLeader {
+		return c.getMember()
+	}
+
+	if c.client.config.BalanceReads && (method == "GET" || method == "HEAD") {
+		if follower, found := c.nextFollower(); found {
+			return follower, nil
+		}
+	}
+
+	c.awaitLeader(ctx)
+
+	c.RLock()
+	defer c.RUnlock()
+	for _, n := range c.members {
+		if n.status == memberStatusUp && n.endpoint == c.leader {
+			return n.endpoint, nil
+		}
+	}
+	for _, n := range c.members {
+		if n.status == memberStatusUp {
+			return n.endpoint, nil
+		}
+	}
+
+	return "", ErrMarathonDown
+}
+
+// nextFollower picks the next active member which is not the leader in a round robin fashion
+func (c *cluster) nextFollower() (string, bool) {
+	c.Lock()
+	defer c.Unlock()
+	if c.leaderStale {
+		return "", false
+	}
+	for i := 1; i <= len(c.members); i++ {
+		index := (c.lastRead + i) % len(c.members)
+		if n := c.members[index]; n.status == memberStatusUp && n.endpoint != c.leader {
+			c.lastRead = index
+			return n.endpoint, true
+		}
+	}
+
+	return "", false
+}
+
+// awaitLeader looks up the leader when it is stale. Only one lookup runs at a time, the concurrent callers wait
+// for its outcome, and the leader is not looked up again for a while after a failed lookup.
+func (c *cluster) awaitLeader(ctx context.Context) {
+	c.Lock()
+	if !c.leaderStale || time.Now().Before(c.leaderRetry) {
+		c.Unlock()
+		return
+	}
+	if lookup := c.leaderLookup; lookup != nil {
+		c.Unlock()
+		select {
+		case <-lookup:
+		case <-ctx.Done():
+		}
+		return
+	}
+	lookup := make(chan struct{})
+	c.leaderLookup = lookup
+	c.Unlock()
+
+	found := c.refreshLeader(ctx)
+
+	c.Lock()
+	defer c.Unlock()
+	// step: a cancelled lookup says nothing about the availability of the leader
+	if !found && ctx.Err() == nil {
+		c.leaderRetry = time.Now().Add(leaderRetryInterval)
+	}
+	c.leaderLookup = nil
+	close(lookup)
+}
+
+// refreshLeader looks up the current leader on the current member, it returns whether the leader is a member
+func (c *cluster) refreshLeader(ctx context.Context) bool {
+	endpoint, err := c.getMember()
+	if err != nil {
+		return false
+	}
+	request, err := c.client.buildMarathonRequest(ctx, "GET", endpoint, marathonAPILeader, nil)
+	if err != nil {
+		return false
+	}
+	response, err := c.client.Do(request)
+	if err != nil {
+		return false
+	}
+	defer response.Body.Close()
+
+	var leader struct {
+		Leader string `json:"leader"`
+	}
+	if response.StatusCode != 200 || json.NewDecoder(response.Body).Decode(&leader) != nil {
+		return false
+	}
+	return c.setLeader(leader.Leader)
+}
+
+// setLeader records the leader, given as host and port with an optional scheme, as reported by Marathon. The
+// leader stays stale when it is not one of the members, it returns whether it is.
+func (c *cluster) setLeader(leader string) bool {
+	if leader == "" {
+		return false
+	}
+	if i := strings.Index(leader, "://"); i >= 0 {
+		leader = leader[i+3:]
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	c.leader = ""
+	c.leaderStale = true
+	for _, n := range c.members {
+		u, err := url.Parse(n.endpoint)
+		if err != nil {
+			continue
+		}
+		// a member without an explicit port is matched by its host name only
+		_, _, err = net.SplitHostPort(u.Host)
+		if u.Host == leader || (err != nil && strings.HasPrefix(leader, u.Host+":")) {
+			c.leader = n.endpoint
+			c.leaderStale = false
+			c.leaderRetry = time.Time{}
+			return true
+		}
+	}
+	return false
+}
+
+// invalidateLeader forces the leader to be looked up again before routing the next request to it
+func (c *cluster) invalidateLeader() {
+	c.Lock()
+	defer c.Unlock()
+	c.leaderStale = true
+}
+
 // markDown marks down the current endpoint
 func (c *cluster) markDown(endpoint string) {
 	c.Lock()
 	defer c.Unlock()
+	if endpoint == c.leader {
+		c.leader = ""
+		c.leaderStale = true
+	}
 	for _, n := range c.members {
 		// step: check if this is the node and it's marked as up - The double  checking on the
 		// nodes status ensures the multiple calls don't create multiple checks
 		if n.status == memberStatusUp && n.endpoint == endpoint {
 			n.status = memberStatusDown
+			c.client.metrics().MemberDown(endpoint)
 			go c.healthCheckNode(n)
 			break
 		}
@@ -129,16 +361,31 @@ func (c *cluster) markDown(endpoint string) {
 
 // healthCheckNode performs a health check on the node and when active updates the status
 func (c *cluster) healthCheckNode(node *member) {
+	// step: a hung health check is aborted once the node is removed or the cluster is closed
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-node.removed:
+		case <-c.done:
+		case <-ctx.Done():
+		}
+		cancel()
+	}()
+
 	// step: wait for the node to become active ... we are assuming a /ping is enough here
 	for {
-		req, err := c.client.buildMarathonRequest("GET", node.endpoint, "ping", nil)
-		if err == nil {
-			res, err := c.client.Do(req)
-			if err == nil && res.StatusCode == 200 {
-				break
-			}
+		if c.ping(ctx, node) {
+			break
+		}
+		select {
+		case <-node.removed:
+			// step: the node is no longer part of the cluster
+			return
+		case <-c.done:
+			return
+		case <-time.After(time.Duration(5 * time.Second)):
 		}
-		<-time.After(time.Duration(5 * time.Second))
 	}
 	// step: mark the node as active again
 	c.Lock()
@@ -146,6 +393,34 @@ func (c *cluster) healthCheckNode(node *member) {
 	node.status = memberStatusUp
 }
 
+// ping checks if the node answers the health check in time
+func (c *cluster) ping(ctx context.Context, node *member) bool {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	req, err := c.client.buildMarathonRequest(ctx, "GET", node.endpoint, "ping", nil)
+	if err != nil {
+		return false
+	}
+	res, err := c.client.Do(req)
+	if err != nil {
+		return false
+	}
+	res.Body.Close()
+	return res.StatusCode == 200
+}
+
+// close stops the health checks of all the members
+func (c *cluster) close() {
+	c.Lock()
+	defer c.Unlock()
+	select {
+	case <-c.done:
+	default:
+		close(c.done)
+	}
+}
+
 // activeMembers returns a list of active members
 func (c *cluster) activeMembers() []string {
 	return c.membersList(memberStatusUp)
@@ -172,9 +447,27 @@ func (c *cluster) membersList(status memberStatus) []string {
 
 // size returns the size of the cluster
 func (c *cluster) size() int {
+	c.RLock()
+	defer c.RUnlock()
 	return len(c.members)
 }
 
+// snapshot returns the current members of the cluster and their status
+func (c *cluster) snapshot() []Member {
+	c.RLock()
+	defer c.RUnlock()
+	var list []Member
+	for _, m := range c.members {
+		list = append(list, Member{
+			Endpoint: m.endpoint,
+			Up:       m.status == memberStatusUp,
+			Leader:   m.endpoint == c.leader,
+		})
+	}
+
+	return list
+}
+
 // String returns a string representation
 func (m member) String() string {
 	status := "UP"
@@ -17,11 +17,19 @@ limitations under the License.
 package marathon
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSize(t *testing.T) {
@@ -94,6 +102,149 @@ func TestMarkDown(t *testing.T) {
 	assert.Equal(t, len(cluster.activeMembers()), 3)
 }
 
+func TestGetMemberForLeader(t *testing.T) {
+	var servers []*httptest.Server
+	var endpoints []string
+	leader := ""
+	for i := 0; i < 3; i++ {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// keep members which were marked down from coming back during the test
+			if r.URL.Path == "/ping" {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			fmt.Fprintf(w, `{"leader": "%s"}`, leader)
+		}))
+		defer server.Close()
+		servers = append(servers, server)
+		endpoints = append(endpoints, server.URL)
+	}
+	leaderURL, err := url.Parse(servers[1].URL)
+	require.NoError(t, err)
+	leader = leaderURL.Host
+
+	config := Config{HTTPClient: http.DefaultClient, RouteToLeader: true, BalanceReads: true}
+	cluster, err := newCluster(&httpClient{config: config}, strings.Join(endpoints, ","), false)
+	require.NoError(t, err)
+
+	member, err := cluster.getMemberFor(context.Background(), "POST")
+	assert.NoError(t, err)
+	assert.Equal(t, servers[1].URL, member)
+
+	// reads are balanced over the followers
+	reads := map[string]int{}
+	for i := 0; i < 4; i++ {
+		member, err := cluster.getMemberFor(context.Background(), "GET")
+		assert.NoError(t, err)
+		reads[member]++
+	}
+	assert.Equal(t, map[string]int{servers[0].URL: 2, servers[2].URL: 2}, reads)
+
+	// writes fall back to the current member while the leader is down
+	cluster.markDown(servers[1].URL)
+	member, err = cluster.getMemberFor(context.Background(), "PUT")
+	assert.NoError(t, err)
+	assert.Equal(t, servers[0].URL, member)
+}
+
+func TestGetMemberForLeaderLookups(t *testing.T) {
+	var lookups int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&lookups, 1)
+		<-release
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	config := Config{HTTPClient: http.DefaultClient, RouteToLeader: true}
+	cluster, err := newCluster(&httpClient{config: config}, server.URL, false)
+	require.NoError(t, err)
+
+	// step: the concurrent callers share a single lookup
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			member, err := cluster.getMemberFor(context.Background(), "POST")
+			assert.NoError(t, err)
+			assert.Equal(t, server.URL, member)
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&lookups))
+
+	// step: the leader is not looked up again right after a failed lookup
+	_, err = cluster.getMemberFor(context.Background(), "POST")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&lookups))
+	assert.True(t, cluster.leaderStale)
+
+	cluster.leaderRetry = time.Now()
+	_, err = cluster.getMemberFor(context.Background(), "POST")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&lookups))
+}
+
+func TestGetMemberForWithoutLeaderRouting(t *testing.T) {
+	cluster, err := newStandardCluster("http://127.0.0.1:3000,127.0.0.2:3000")
+	require.NoError(t, err)
+	cluster.setLeader("127.0.0.2:3000")
+
+	member, err := cluster.getMemberFor(context.Background(), "POST")
+	assert.NoError(t, err)
+	assert.Equal(t, "http://127.0.0.1:3000", member)
+}
+
+func TestSetLeader(t *testing.T) {
+	cluster, err := newStandardCluster("http://127.0.0.1:3000,http://marathon,127.0.0.3:3000")
+	require.NoError(t, err)
+	assert.True(t, cluster.leaderStale)
+
+	cluster.setLeader("127.0.0.3:3000")
+	assert.False(t, cluster.leaderStale)
+	assert.Equal(t, "http://127.0.0.3:3000", cluster.leader)
+
+	cluster.setLeader("http://marathon:8080")
+	assert.Equal(t, "http://marathon", cluster.leader)
+
+	assert.False(t, cluster.setLeader("127.0.0.4:3000"))
+	assert.Equal(t, "", cluster.leader)
+	assert.True(t, cluster.leaderStale)
+
+	cluster.setLeader("127.0.0.1:3000")
+	cluster.invalidateLeader()
+	assert.True(t, cluster.leaderStale)
+}
+
+func TestSetMembers(t *testing.T) {
+	cluster, err := newStandardCluster("http://127.0.0.1:3000,127.0.0.2:3000,127.0.0.3:3000")
+	require.NoError(t, err)
+	cluster.setLeader("127.0.0.3:3000")
+	cluster.members[1].status = memberStatusDown
+	removed := cluster.members[2]
+
+	err = cluster.setMembers("http://127.0.0.2:3000,127.0.0.4:3000")
+	assert.NoError(t, err)
+	assert.Equal(t, []Member{
+		{Endpoint: "http://127.0.0.2:3000", Up: false},
+		{Endpoint: "http://127.0.0.4:3000", Up: true},
+	}, cluster.snapshot())
+	assert.True(t, cluster.leaderStale)
+
+	select {
+	case <-removed.removed:
+	default:
+		assert.Fail(t, "the removed member has not been signalled")
+	}
+
+	assert.Error(t, cluster.setMembers("http://,"))
+	assert.Equal(t, 2, cluster.size())
+}
+
 func TestValidClusterHosts(t *testing.T) {
 	cs := []struct {
 		URL    string
@@ -27,6 +27,17 @@ const defaultPollingWaitTime = 500 * time.Millisecond
 
 const defaultDCOSPath = "marathon"
 
+const eventsShutdownTimeout = 10 * time.Second
+
+// deploymentOutcomeTimeout is how long a wait on a deployment which has vanished awaits the event of its outcome
+const deploymentOutcomeTimeout = 2 * time.Second
+
+var defaultEventsStreamBackoff = &RetryPolicy{
+	InitialBackoff: time.Second,
+	MaxBackoff:     30 * time.Second,
+	Jitter:         0.2,
+}
+
 // EventsTransport describes which transport should be used to deliver Marathon events
 type EventsTransport int
 
@@ -40,6 +51,12 @@ type Config struct {
 	EventsPort int
 	// the interface we should be listening on for events
 	EventsInterface string
+	// EventsStreamIdleTimeout is the time without any received event after which the SSE stream is considered
+	// stale and reconnected, zero disables the detection
+	EventsStreamIdleTimeout time.Duration
+	// EventsStreamBackoff controls the delays between failed attempts to reconnect the SSE stream, only its
+	// backoff settings apply as the attempts are not limited. Defaults to backing off from 1 to 30 seconds.
+	EventsStreamBackoff *RetryPolicy
 	// HTTPBasicAuthUser is the http basic auth
 	HTTPBasicAuthUser string
 	// HTTPBasicPassword is the http basic password
@@ -48,12 +65,38 @@ type Config struct {
 	CallbackURL string
 	// DCOSToken for DCOS environment, This will override the Authorization header
 	DCOSToken string
-	// LogOutput the output for debug log messages
+	// Authenticator adds the credentials to the requests, it takes precedence over HTTPBasicAuthUser,
+	// HTTPBasicPassword and DCOSToken
+	Authenticator Authenticator
+	// LogOutput the output for debug log messages, written as lines of text unless a Logger is set
 	LogOutput io.Writer
+	// Logger receives the leveled diagnostics of the client, e.g. a *slog.Logger. It takes precedence over LogOutput.
+	Logger Logger
 	// HTTPClient is the http client
 	HTTPClient *http.Client
+	// Interceptors wrap the sending of the API requests in the given order, the first one seeing the requests
+	// first. They don't apply to the SSE event stream.
+	Interceptors []Interceptor
 	// wait time (in milliseconds) between repetitive requests to the API during polling
 	PollingWaitTime time.Duration
+	// MemberSource discovers the Marathon members, which then take precedence over the ones given in URL
+	MemberSource MemberSource
+	// MemberRefreshInterval is the interval the members are discovered again in, defaults to 30 seconds
+	MemberRefreshInterval time.Duration
+	// RouteToLeader sends the API requests directly to the Marathon leader instead of having them
+	// proxied by the first available member
+	RouteToLeader bool
+	// BalanceReads spreads the read-only requests over the available non-leader members, requires RouteToLeader
+	BalanceReads bool
+	// RetryPolicy controls the retries of API calls failing on a member, nil retries immediately on all members
+	RetryPolicy *RetryPolicy
+	// Metrics receives the measurements of the API calls, member failures and events, nil disables them
+	Metrics Metrics
+	// Cache caches the responses to GET requests, nil disables the cache
+	Cache *CachePolicy
+	// ConflictResolution is the default resolution of the conflicts of updates, scalings and deletions with locking
+	// deployments, which WithConflictResolution overrides per call, nil returns the conflicts to the caller
+	ConflictResolution *ConflictResolution
 }
 
 // NewDefaultConfig create a default client config
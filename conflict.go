@@ -0,0 +1,145 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import "time"
+
+const defaultConflictTimeout = 5 * time.Minute
+
+// ConflictPolicy decides how the deployments locking an application, group or pod are dealt with
+type ConflictPolicy int
+
+const (
+	// ConflictFail returns the 409 Conflict error to the caller
+	ConflictFail ConflictPolicy = iota
+	// ConflictWait waits for the locking deployments to finish
+	ConflictWait
+	// ConflictRollback rolls the locking deployments back and waits for the rollbacks to finish
+	ConflictRollback
+)
+
+// ConflictResolution controls how the updates, scalings and deletions of applications, groups and pods
+// locked by running deployments are resolved. Once the locking deployments are out of the way, the call is
+// made again.
+type ConflictResolution struct {
+	// Policy decides how the locking deployments are dealt with
+	Policy ConflictPolicy
+	// Timeout bounds the time spent on resolving the conflicts of a call, defaults to 5 minutes
+	Timeout time.Duration
+}
+
+// resolveConflicts makes the call, resolving the conflicts with the deployments locking the entity as
+// configured by the ConflictResolution
+//		call:		the API call to make
+func (r *marathonClient) resolveConflicts(call func() error) error {
+	err := call()
+	resolution := r.config.ConflictResolution
+	if resolution == nil || resolution.Policy == ConflictFail {
+		return err
+	}
+
+	timeout := resolution.Timeout
+	if timeout <= 0 {
+		timeout = defaultConflictTimeout
+	}
+	deadline := time.Now().Add(timeout)
+
+	var rolledBack []string
+	for {
+		conflict := deploymentConflict(err)
+		if conflict == nil {
+			return err
+		}
+		// step: deployments locking the entity again once rolled back won't be resolved by another round
+		if rolledBack != nil && sameIDs(rolledBack, conflict.DeploymentIDs) {
+			return err
+		}
+		rolledBack = nil
+
+		for _, id := range conflict.DeploymentIDs {
+			remaining := deadline.Sub(time.Now())
+			if remaining <= 0 {
+				return err
+			}
+
+			if resolution.Policy == ConflictRollback {
+				rolledBack = conflict.DeploymentIDs
+				rollback, rollbackErr := r.DeleteDeployment(id, false)
+				if rollbackErr != nil {
+					// step: the deployment may have finished in the meantime
+					if apiErr, ok := rollbackErr.(*APIError); ok && apiErr.ErrCode == ErrCodeNotFound {
+						continue
+					}
+					return rollbackErr
+				}
+				id = rollback.DeploymentID
+			}
+
+			r.logger.Info("resolveConflicts(): waiting for the deployment", "deployment", id)
+			if waitErr := r.WaitOnDeployment(id, remaining); waitErr != nil {
+				// step: a failed deployment doesn't lock anymore either
+				if _, failed := waitErr.(*DeploymentFailedError); !failed {
+					return waitErr
+				}
+			}
+		}
+
+		// step: pause between the rounds, so a conflict outlasting its deployments doesn't flood Marathon
+		remaining := deadline.Sub(time.Now())
+		if remaining <= 0 {
+			return err
+		}
+		pause := r.config.PollingWaitTime
+		if pause > remaining {
+			pause = remaining
+		}
+		select {
+		case <-r.ctx.Done():
+			return r.ctx.Err()
+		case <-time.After(pause):
+		}
+
+		err = call()
+	}
+}
+
+// sameIDs checks if the two lists hold the same IDs, in any order
+func sameIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	ids := make(map[string]bool, len(a))
+	for _, id := range a {
+		ids[id] = true
+	}
+	for _, id := range b {
+		if !ids[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// deploymentConflict returns the details of a 409 Conflict error caused by locking deployments, if it is one
+func deploymentConflict(err error) *DeploymentConflictError {
+	apiErr, ok := err.(*APIError)
+	if !ok || apiErr.ErrCode != ErrCodeAppLocked {
+		return nil
+	}
+	conflict, _ := apiErr.detail.(*DeploymentConflictError)
+	return conflict
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fakeLockingDeploymentID = "97c136bf-5a28-4821-9d94-480d9fbb01c8"
+
+func newConflictEndpoint(t *testing.T, resolution *ConflictResolution) *endpoint {
+	return newScopedConflictEndpoint(t, resolution, "conflict")
+}
+
+func newScopedConflictEndpoint(t *testing.T, resolution *ConflictResolution, scope string) *endpoint {
+	config := NewDefaultConfig()
+	config.ConflictResolution = resolution
+	return newFakeMarathonEndpoint(t, &configContainer{
+		client: &config,
+		server: &serverConfig{scope: scope},
+	})
+}
+
+func rollbacks(endpoint *endpoint, scope string) int {
+	key := fakeResponseMapKey("DELETE", "/v2/deployments/"+fakeLockingDeploymentID, scope)
+	indices := endpoint.Server.fakeRespIndices
+	indices.Lock()
+	defer indices.Unlock()
+	return indices.m[key]
+}
+
+func TestConflictFail(t *testing.T) {
+	endpoint := newConflictEndpoint(t, &ConflictResolution{Policy: ConflictFail})
+	defer endpoint.Close()
+
+	_, err := endpoint.Client.ScaleApplicationInstances(fakeAppName, 2, false)
+	require.Error(t, err)
+	conflict := deploymentConflict(err)
+	require.NotNil(t, conflict)
+	assert.Equal(t, []string{fakeLockingDeploymentID}, conflict.DeploymentIDs)
+}
+
+func TestConflictWait(t *testing.T) {
+	endpoint := newConflictEndpoint(t, &ConflictResolution{Policy: ConflictWait})
+	defer endpoint.Close()
+
+	id, err := endpoint.Client.ScaleApplicationInstances(fakeAppName, 2, false)
+	require.NoError(t, err)
+	assert.Equal(t, "83b215a6-4e26-4e44-9333-5c385eda6438", id.DeploymentID)
+	assert.Equal(t, 0, rollbacks(endpoint, "conflict"))
+}
+
+func TestConflictRollback(t *testing.T) {
+	endpoint := newConflictEndpoint(t, &ConflictResolution{Policy: ConflictRollback})
+	defer endpoint.Close()
+
+	id, err := endpoint.Client.UpdateApplication(NewDockerApplication().Name(fakeAppName), false)
+	require.NoError(t, err)
+	assert.Equal(t, "83b215a6-4e26-4e44-9333-5c385eda6438", id.DeploymentID)
+	assert.Equal(t, 1, rollbacks(endpoint, "conflict"))
+}
+
+func TestConflictRollbackPersistent(t *testing.T) {
+	endpoint := newScopedConflictEndpoint(t, &ConflictResolution{Policy: ConflictRollback}, "conflict-persistent")
+	defer endpoint.Close()
+
+	_, err := endpoint.Client.UpdateApplication(NewDockerApplication().Name(fakeAppName), false)
+	require.Error(t, err)
+	conflict := deploymentConflict(err)
+	require.NotNil(t, conflict)
+	assert.Equal(t, []string{fakeLockingDeploymentID}, conflict.DeploymentIDs)
+	assert.Equal(t, 1, rollbacks(endpoint, "conflict-persistent"))
+}
+
+func TestConflictResolutionPerCall(t *testing.T) {
+	endpoint := newConflictEndpoint(t, nil)
+	defer endpoint.Close()
+
+	client := endpoint.Client.WithConflictResolution(&ConflictResolution{Policy: ConflictRollback})
+	id, err := client.UpdateApplication(NewDockerApplication().Name(fakeAppName), false)
+	require.NoError(t, err)
+	assert.Equal(t, "83b215a6-4e26-4e44-9333-5c385eda6438", id.DeploymentID)
+	assert.Equal(t, 1, rollbacks(endpoint, "conflict"))
+}
+
+func TestConflictResolutionPerCallOverridesDefault(t *testing.T) {
+	endpoint := newConflictEndpoint(t, &ConflictResolution{Policy: ConflictWait})
+	defer endpoint.Close()
+
+	_, err := endpoint.Client.WithConflictResolution(nil).ScaleApplicationInstances(fakeAppName, 2, false)
+	require.NotNil(t, deploymentConflict(err))
+}
+
+func TestSameIDs(t *testing.T) {
+	assert.True(t, sameIDs([]string{"a", "b"}, []string{"b", "a"}))
+	assert.False(t, sameIDs([]string{"a", "b"}, []string{"a"}))
+	assert.False(t, sameIDs([]string{"a", "b"}, []string{"a", "c"}))
+}
+
+func TestDeploymentConflict(t *testing.T) {
+	assert.Nil(t, deploymentConflict(nil))
+	assert.Nil(t, deploymentConflict(ErrTimeoutError))
+	assert.Nil(t, deploymentConflict(NewAPIError(http.StatusConflict, []byte(`{"message": "An app with id [/app] already exists."}`))))
+	assert.NotNil(t, deploymentConflict(NewAPIError(http.StatusConflict, []byte(`{"message": "App is locked", "deployments": [{"id": "1"}]}`))))
+}
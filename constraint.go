@@ -0,0 +1,262 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+)
+
+// ConstraintOperator is the operator of a placement constraint
+type ConstraintOperator string
+
+const (
+	// ConstraintUnique places each task on an agent with a distinct value of the field
+	ConstraintUnique ConstraintOperator = "UNIQUE"
+	// ConstraintCluster places the tasks on the agents sharing the value of the field
+	ConstraintCluster ConstraintOperator = "CLUSTER"
+	// ConstraintGroupBy spreads the tasks evenly across the values of the field
+	ConstraintGroupBy ConstraintOperator = "GROUP_BY"
+	// ConstraintLike places the tasks on the agents whose field matches a regular expression
+	ConstraintLike ConstraintOperator = "LIKE"
+	// ConstraintUnlike places the tasks on the agents whose field does not match a regular expression
+	ConstraintUnlike ConstraintOperator = "UNLIKE"
+	// ConstraintMaxPer limits the number of tasks per value of the field
+	ConstraintMaxPer ConstraintOperator = "MAX_PER"
+	// ConstraintIs places the tasks on the agents whose field equals the value
+	ConstraintIs ConstraintOperator = "IS"
+)
+
+// constraintArities are the numbers of fields of the constraints by operator
+var constraintArities = map[ConstraintOperator][]int{
+	ConstraintUnique:  {2},
+	ConstraintCluster: {2, 3},
+	ConstraintGroupBy: {2, 3},
+	ConstraintLike:    {3},
+	ConstraintUnlike:  {3},
+	ConstraintMaxPer:  {3},
+	ConstraintIs:      {3},
+}
+
+// Constraint is a placement constraint of an application, a pod or a persistent volume, encoded by Marathon as
+// an array of two or three strings, e.g. ["hostname", "UNIQUE"] or ["rack_id", "GROUP_BY", "3"]
+type Constraint struct {
+	// Field is the agent attribute or the hostname
+	Field string
+	// Operator is the operator
+	Operator ConstraintOperator
+	// Value is the value of the operator, empty for the operators taking none
+	Value string
+}
+
+// Unique creates a constraint placing each task on an agent with a distinct value of the field
+//		field:		the attribute or the hostname
+func Unique(field string) Constraint {
+	return Constraint{Field: field, Operator: ConstraintUnique}
+}
+
+// Cluster creates a constraint placing the tasks on the agents sharing the value of the field
+//		field:		the attribute or the hostname
+//		value:		the value to share, empty to let Marathon pick the value of the first agent
+func Cluster(field, value string) Constraint {
+	return Constraint{Field: field, Operator: ConstraintCluster, Value: value}
+}
+
+// GroupBy creates a constraint spreading the tasks evenly across the values of the field
+//		field:		the attribute or the hostname
+//		groups:		the number of values, zero to let Marathon guess it from the offers
+func GroupBy(field string, groups int) Constraint {
+	constraint := Constraint{Field: field, Operator: ConstraintGroupBy}
+	if groups != 0 {
+		constraint.Value = strconv.Itoa(groups)
+	}
+
+	return constraint
+}
+
+// Like creates a constraint placing the tasks on the agents whose field matches the regular expression
+//		field:		the attribute or the hostname
+//		regex:		the regular expression, matched against the whole value
+func Like(field, regex string) Constraint {
+	return Constraint{Field: field, Operator: ConstraintLike, Value: regex}
+}
+
+// Unlike creates a constraint placing the tasks on the agents whose field does not match the regular expression
+//		field:		the attribute or the hostname
+//		regex:		the regular expression, matched against the whole value
+func Unlike(field, regex string) Constraint {
+	return Constraint{Field: field, Operator: ConstraintUnlike, Value: regex}
+}
+
+// MaxPer creates a constraint limiting the number of tasks per value of the field
+//		field:		the attribute or the hostname
+//		max:		the maximum number of tasks per value
+func MaxPer(field string, max int) Constraint {
+	return Constraint{Field: field, Operator: ConstraintMaxPer, Value: strconv.Itoa(max)}
+}
+
+// Is creates a constraint placing the tasks on the agents whose field equals the value
+//		field:		the attribute or the hostname
+//		value:		the value of the field
+func Is(field, value string) Constraint {
+	return Constraint{Field: field, Operator: ConstraintIs, Value: value}
+}
+
+// ParseConstraint parses and validates a constraint from its array form
+//		fields:		the field, the operator and the optional value of the constraint
+func ParseConstraint(fields []string) (Constraint, error) {
+	if len(fields) < 2 || len(fields) > 3 {
+		return Constraint{}, errors.New("Each constraint must have either 2 or 3 fields")
+	}
+	constraint := Constraint{Field: fields[0], Operator: ConstraintOperator(fields[1])}
+	if len(fields) == 3 {
+		constraint.Value = fields[2]
+	}
+	arities, found := constraintArities[constraint.Operator]
+	if !found {
+		return Constraint{}, errors.New("Constraint operator must be one of the following: [CLUSTER, GROUP_BY, IS, LIKE, MAX_PER, UNIQUE, UNLIKE]")
+	}
+	if !containsInt(arities, len(fields)) {
+		return Constraint{}, fmt.Errorf("Constraint with operator %s must have %s fields", constraint.Operator, joinInts(arities, " or "))
+	}
+	if len(fields) == 3 {
+		if err := constraint.validateValue(); err != nil {
+			return Constraint{}, err
+		}
+	}
+
+	return constraint, nil
+}
+
+// ParseConstraints parses and validates the constraints of an application, a pod placement or a persistent volume
+//		constraints:	the constraints in their array form, nil when unset
+func ParseConstraints(constraints *[][]string) ([]Constraint, error) {
+	if constraints == nil {
+		return nil, nil
+	}
+	var parsed []Constraint
+	for _, fields := range *constraints {
+		constraint, err := ParseConstraint(fields)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, constraint)
+	}
+
+	return parsed, nil
+}
+
+// Validate checks the operator, the arity and the value of the constraint, e.g. the regular expressions of the
+// LIKE and UNLIKE operators
+func (c Constraint) Validate() error {
+	_, err := ParseConstraint(c.Strings())
+	return err
+}
+
+// Strings returns the array form of the constraint, as sent to Marathon
+func (c Constraint) Strings() []string {
+	if c.Value == "" {
+		return []string{c.Field, string(c.Operator)}
+	}
+	return []string{c.Field, string(c.Operator), c.Value}
+}
+
+// String returns the constraint as displayed by Marathon, e.g. hostname:UNIQUE
+func (c Constraint) String() string {
+	if c.Value == "" {
+		return c.Field + ":" + string(c.Operator)
+	}
+	return c.Field + ":" + string(c.Operator) + ":" + c.Value
+}
+
+// MarshalJSON encodes the constraint in its array form
+func (c Constraint) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.Strings())
+}
+
+// UnmarshalJSON decodes and validates a constraint from its array form
+func (c *Constraint) UnmarshalJSON(data []byte) error {
+	var fields []string
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	constraint, err := ParseConstraint(fields)
+	if err != nil {
+		return err
+	}
+	*c = constraint
+
+	return nil
+}
+
+// validateValue checks the value of the operators taking one
+func (c Constraint) validateValue() error {
+	switch c.Operator {
+	case ConstraintLike, ConstraintUnlike:
+		if !validPattern(c.Value) {
+			return fmt.Errorf("'%s' is not a valid regular expression", c.Value)
+		}
+	case ConstraintMaxPer, ConstraintGroupBy:
+		if number, err := strconv.Atoi(c.Value); err != nil || number < 1 {
+			return fmt.Errorf("Value for operator %s must be a positive integer, got: %s", c.Operator, c.Value)
+		}
+	}
+
+	return nil
+}
+
+// validPattern checks the syntax of a pattern of the LIKE and UNLIKE operators. Marathon compiles them as Java
+// regular expressions, a superset of the syntax of the regexp package barring a few details, so only the errors
+// which are errors in Java as well invalidate them: the lookarounds, backreferences, possessive quantifiers and
+// the like which the regexp package rejects are left to Marathon
+func validPattern(pattern string) bool {
+	_, err := regexp.Compile("^(" + pattern + ")$")
+	if err == nil {
+		return true
+	}
+	syntaxErr, ok := err.(*syntax.Error)
+	if !ok {
+		return false
+	}
+	switch syntaxErr.Code {
+	case syntax.ErrMissingBracket, syntax.ErrMissingParen, syntax.ErrUnexpectedParen,
+		syntax.ErrMissingRepeatArgument, syntax.ErrTrailingBackslash:
+		return false
+	case syntax.ErrInvalidCharRange:
+		// step: the Java character classes, e.g. \p{javaLowerCase}, are reported as ranges
+		return strings.HasPrefix(syntaxErr.Expr, `\p`) || strings.HasPrefix(syntaxErr.Expr, `\P`)
+	}
+	return true
+}
+
+// appendConstraints appends the array forms of the constraints
+func appendConstraints(list *[][]string, constraints []Constraint) *[][]string {
+	c := [][]string{}
+	if list != nil {
+		c = *list
+	}
+	for _, constraint := range constraints {
+		c = append(c, constraint.Strings())
+	}
+
+	return &c
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConstraintConstructors(t *testing.T) {
+	application := new(Application).Name("app").Command("sleep 60").AddConstraints(
+		Unique("hostname"),
+		Cluster("rack_id", "rack-1"),
+		GroupBy("zone", 3),
+		GroupBy("zone", 0),
+		Like("rack_id", "rack-[1-3]"),
+		Unlike("hostname", "^db"),
+		MaxPer("rack_id", 2),
+		Is("os", "linux"),
+	)
+	assert.Equal(t, &[][]string{
+		{"hostname", "UNIQUE"},
+		{"rack_id", "CLUSTER", "rack-1"},
+		{"zone", "GROUP_BY", "3"},
+		{"zone", "GROUP_BY"},
+		{"rack_id", "LIKE", "rack-[1-3]"},
+		{"hostname", "UNLIKE", "^db"},
+		{"rack_id", "MAX_PER", "2"},
+		{"os", "IS", "linux"},
+	}, application.Constraints)
+	assert.NoError(t, application.Validate())
+
+	// step: the typed and the untyped constraints can be mixed
+	placement := NewPodPlacement().AddConstraint("hostname", "UNIQUE").AddConstraints(MaxPer("rack_id", 1))
+	assert.Equal(t, &[][]string{{"hostname", "UNIQUE"}, {"rack_id", "MAX_PER", "1"}}, placement.Constraints)
+
+	volume := new(PersistentVolume).AddConstraints(Like("path", "/mnt/ssd-.*"))
+	assert.Equal(t, &[][]string{{"path", "LIKE", "/mnt/ssd-.*"}}, volume.Constraints)
+}
+
+func TestConstraintJSON(t *testing.T) {
+	content, err := json.Marshal([]Constraint{Unique("hostname"), GroupBy("zone", 3)})
+	require.NoError(t, err)
+	assert.Equal(t, `[["hostname","UNIQUE"],["zone","GROUP_BY","3"]]`, string(content))
+
+	var constraints []Constraint
+	require.NoError(t, json.Unmarshal(content, &constraints))
+	assert.Equal(t, []Constraint{Unique("hostname"), GroupBy("zone", 3)}, constraints)
+
+	assert.Error(t, json.Unmarshal([]byte(`[["hostname","GROUPBY"]]`), &constraints))
+	assert.Equal(t, "hostname:GROUP_BY:3", GroupBy("hostname", 3).String())
+}
+
+func TestParseConstraints(t *testing.T) {
+	constraints, err := ParseConstraints(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, constraints)
+
+	constraints, err = ParseConstraints(&[][]string{{"hostname", "UNIQUE"}, {"rack_id", "LIKE", "rack-[1-3]"}})
+	require.NoError(t, err)
+	assert.Equal(t, []Constraint{Unique("hostname"), Like("rack_id", "rack-[1-3]")}, constraints)
+
+	for _, fields := range [][]string{
+		{"hostname"},
+		{"hostname", "EQUALS", "a"},
+		{"hostname", "UNIQUE", "a"},
+		{"hostname", "LIKE"},
+		{"hostname", "MAX_PER", "0"},
+	} {
+		_, err := ParseConstraint(fields)
+		assert.Error(t, err, "%v should be invalid", fields)
+	}
+
+	assert.NoError(t, Like("rack_id", "rack-[1-3]").Validate())
+	err = Unlike("rack_id", "rack-[1-3").Validate()
+	if assert.Error(t, err) {
+		assert.Equal(t, "'rack-[1-3' is not a valid regular expression", err.Error())
+	}
+	assert.Error(t, MaxPer("rack_id", -1).Validate())
+}
+
+func TestConstraintJavaPatterns(t *testing.T) {
+	// step: the Java syntax unknown to the regexp package is left to Marathon
+	for _, pattern := range []string{`rack-(?!3)\d`, `(a)\1`, `(?<=db-)\d+`, `(?<name>a)\k<name>`, `a*+`, `\p{javaLowerCase}+`} {
+		assert.NoError(t, Like("rack_id", pattern).Validate(), "%s should be valid", pattern)
+	}
+	// step: the errors in both syntaxes are reported
+	for _, pattern := range []string{`rack-[1-3`, `rack)`, `(rack`, `*rack`, `rack\`, `[z-a]`} {
+		assert.Error(t, Unlike("rack_id", pattern).Validate(), "%s should be invalid", pattern)
+	}
+}
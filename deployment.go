@@ -135,30 +135,28 @@ func (r *marathonClient) HasDeployment(id string) (bool, error) {
 //  version:		the version of the application
 // 	timeout:		the timeout to wait for the deployment to take, otherwise return an error
 func (r *marathonClient) WaitOnDeployment(id string, timeout time.Duration) error {
-	if found, err := r.HasDeployment(id); err != nil {
-		return err
-	} else if !found {
-		return nil
-	}
-
-	nowTime := time.Now()
-	stopTime := nowTime.Add(timeout)
 	if timeout <= 0 {
-		stopTime = nowTime.Add(time.Duration(900) * time.Second)
+		timeout = time.Duration(900) * time.Second
 	}
 
-	// step: a somewhat naive implementation, but it will work
-	for {
-		if time.Now().After(stopTime) {
-			return ErrTimeoutError
+	// step: a failed deployment vanishes from the deployments as well, so while the events are received, the wait
+	// only ends on its disappearance once its success has been received, or after a while without its outcome
+	succeeded := false
+	var vanished time.Time
+	return r.waitUntil(timeout, watchDeployment(id, &succeeded), func(client *marathonClient) (bool, error) {
+		if succeeded {
+			return true, nil
 		}
-		found, err := r.HasDeployment(id)
-		if err != nil {
-			return err
+		found, err := client.HasDeployment(id)
+		if err != nil || found {
+			return false, err
 		}
-		if !found {
-			return nil
+		if !r.receivesEvents() {
+			return true, nil
 		}
-		time.Sleep(r.config.PollingWaitTime)
-	}
+		if vanished.IsZero() {
+			vanished = time.Now()
+		}
+		return time.Since(vanished) >= deploymentOutcomeTimeout, nil
+	})
 }
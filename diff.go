@@ -0,0 +1,276 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ChangeType is the type of a change between two definitions
+type ChangeType string
+
+const (
+	// ChangeAdded is a value set in the new definition only
+	ChangeAdded ChangeType = "added"
+	// ChangeRemoved is a value set in the old definition only
+	ChangeRemoved ChangeType = "removed"
+	// ChangeModified is a value which differs between the definitions
+	ChangeModified ChangeType = "modified"
+)
+
+// applicationServerFields are the fields of the applications populated by Marathon, which are ignored when
+// comparing them
+var applicationServerFields = []string{"tasks", "tasksRunning", "tasksStaged", "tasksHealthy", "tasksUnhealthy",
+	"taskStats", "deployments", "readinessCheckResults", "lastTaskFailure", "version", "versionInfo"}
+
+// applicationMapFields are the fields of the applications holding maps, whose keys are compared one by one and
+// replaced as a whole
+var applicationMapFields = map[string]bool{"env": true, "labels": true, "ipAddress.labels": true}
+
+// podServerFields are the fields of the pods populated by Marathon, which are ignored when comparing them
+var podServerFields = []string{"version"}
+
+// podMapFields are the fields of the pods holding maps, whose keys are compared one by one
+var podMapFields = map[string]bool{"labels": true, "environment": true, "secrets": true}
+
+// Change is a change between two definitions
+type Change struct {
+	// Path is the JSON path of the value, e.g. "container.docker.image" or "env.KEY"
+	Path string
+	// Type is the type of the change
+	Type ChangeType
+	// Old is the JSON value in the old definition, nil when added
+	Old interface{}
+	// New is the JSON value in the new definition, nil when removed
+	New interface{}
+}
+
+// DefinitionDiff is the list of changes between two definitions
+type DefinitionDiff struct {
+	// Changes are the changes, sorted by path
+	Changes []Change
+	// the IDs of the old and the new definitions
+	oldID string
+	newID string
+}
+
+// Empty checks if the definitions are equivalent
+func (d *DefinitionDiff) Empty() bool {
+	return len(d.Changes) == 0
+}
+
+// Paths returns the JSON paths of the changes
+func (d *DefinitionDiff) Paths() []string {
+	var paths []string
+	for _, change := range d.Changes {
+		paths = append(paths, change.Path)
+	}
+	return paths
+}
+
+// String renders the changes in the unified diff format, with a hunk per change
+func (d *DefinitionDiff) String() string {
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "--- %s\n+++ %s\n", d.oldID, d.newID)
+	for _, change := range d.Changes {
+		fmt.Fprintf(&buffer, "@@ %s @@\n", change.Path)
+		if change.Type != ChangeAdded {
+			writeDiffLines(&buffer, "-", change.Old)
+		}
+		if change.Type != ChangeRemoved {
+			writeDiffLines(&buffer, "+", change.New)
+		}
+	}
+	return buffer.String()
+}
+
+// DiffApplications compares the definitions of two applications field by field, ignoring the fields populated by
+// Marathon, like the tasks or the version. As when updating an application, the fields left unset (nil) in the new
+// definition are kept, while the emptied ones (e.g. with EmptyArgs or EmptyLabels) are removed; the arrays and the
+// maps are replaced as a whole, and an empty value is equivalent to a missing one.
+//		a:		the old definition, e.g. the current one
+//		b:		the new definition, e.g. the one about to be updated
+func DiffApplications(a, b *Application) (*DefinitionDiff, error) {
+	differ := definitionDiffer{maps: applicationMapFields, partial: true}
+	return differ.diffDefinitions(a.ID, b.ID, a, b, applicationServerFields)
+}
+
+// DiffPods compares the definitions of two pods field by field, ignoring the version. As pods are replaced as a
+// whole when updated, the fields missing from the new definition are removed.
+//		a:		the old definition, e.g. the current one
+//		b:		the new definition, e.g. the one about to be updated
+func DiffPods(a, b *Pod) (*DefinitionDiff, error) {
+	differ := definitionDiffer{maps: podMapFields}
+	return differ.diffDefinitions(a.ID, b.ID, a, b, podServerFields)
+}
+
+// definitionDiffer compares the JSON encodings of definitions
+type definitionDiffer struct {
+	// maps are the JSON paths of the objects which are maps
+	maps map[string]bool
+	// partial keeps the values missing from the new definition
+	partial bool
+}
+
+// diffDefinitions compares two definitions, ignoring the given fields
+func (d definitionDiffer) diffDefinitions(oldID, newID string, a, b interface{}, ignored []string) (*DefinitionDiff, error) {
+	oldFields, err := definitionFields(a)
+	if err != nil {
+		return nil, err
+	}
+	newFields, err := definitionFields(b)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range ignored {
+		delete(oldFields, field)
+		delete(newFields, field)
+	}
+
+	return &DefinitionDiff{
+		Changes: d.diff("", oldFields, newFields),
+		oldID:   oldID,
+		newID:   newID,
+	}, nil
+}
+
+// diff returns the changes between two JSON values
+//		path:		the JSON path of the values
+//		old:		the old value
+//		new:		the new value
+func (d definitionDiffer) diff(path string, old, new interface{}) []Change {
+	oldObject, oldIsObject := old.(map[string]interface{})
+	newObject, newIsObject := new.(map[string]interface{})
+	switch {
+	case new == nil && d.partial:
+		return nil
+	case d.maps[path] && (oldIsObject || old == nil) && (newIsObject || new == nil):
+		// step: compare the keys of the maps, which are replaced as a whole
+		differ := d
+		differ.partial = false
+		return differ.diffObjects(path, oldObject, newObject)
+	case oldIsObject && newIsObject:
+		return d.diffObjects(path, oldObject, newObject)
+	case isEmptyValue(old) && isEmptyValue(new):
+		return nil
+	case isEmptyValue(old):
+		return []Change{{Path: path, Type: ChangeAdded, New: new}}
+	case isEmptyValue(new):
+		return []Change{{Path: path, Type: ChangeRemoved, Old: old}}
+	}
+
+	if d.equal(old, new) {
+		return nil
+	}
+	return []Change{{Path: path, Type: ChangeModified, Old: old, New: new}}
+}
+
+// diffObjects returns the changes between the values of two JSON objects
+func (d definitionDiffer) diffObjects(path string, oldObject, newObject map[string]interface{}) []Change {
+	keys := make(map[string]bool)
+	for key := range oldObject {
+		keys[key] = true
+	}
+	for key := range newObject {
+		keys[key] = true
+	}
+	var sorted []string
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	var changes []Change
+	for _, key := range sorted {
+		changes = append(changes, d.diff(joinJSONPath(path, key), oldObject[key], newObject[key])...)
+	}
+	return changes
+}
+
+// equal checks if two JSON values are equivalent. With partial comparisons, the values missing from the elements
+// of the new arrays are ignored, e.g. the service ports assigned by Marathon.
+func (d definitionDiffer) equal(old, new interface{}) bool {
+	oldArray, oldIsArray := old.([]interface{})
+	newArray, newIsArray := new.([]interface{})
+	if !d.partial || !oldIsArray || !newIsArray {
+		return reflect.DeepEqual(old, new)
+	}
+	if len(oldArray) != len(newArray) {
+		return false
+	}
+	differ := definitionDiffer{partial: true}
+	for index := range newArray {
+		if len(differ.diff("", oldArray[index], newArray[index])) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// isEmptyValue checks if a JSON value is missing or empty
+func isEmptyValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// definitionFields returns the fields of the JSON encoding of a definition
+func definitionFields(definition interface{}) (map[string]interface{}, error) {
+	content, err := json.Marshal(definition)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(content, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// joinJSONPath appends the key to the JSON path
+func joinJSONPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// writeDiffLines writes the indented JSON encoding of the value, each line prefixed
+func writeDiffLines(buffer *bytes.Buffer, prefix string, value interface{}) {
+	var encoded bytes.Buffer
+	encoder := json.NewEncoder(&encoded)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		fmt.Fprintf(buffer, "%s%v\n", prefix, value)
+		return
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(encoded.String(), "\n"), "\n") {
+		buffer.WriteString(prefix + line + "\n")
+	}
+}
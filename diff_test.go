@@ -0,0 +1,94 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffApplications(t *testing.T) {
+	a := NewDockerApplication().Name("/app").Count(2).CPU(0.5).AddArgs("--port", "8080").
+		AddLabel("tier", "frontend").AddLabel("team", "web")
+	a.Container.Docker.Container("python:3")
+	a.Tasks = []*Task{{ID: "app.1"}}
+	a.Version = "2014-09-12T23:28:21.737Z"
+
+	// step: the runtime fields and the unset ones are ignored
+	b := NewDockerApplication().Name("/app")
+	b.Version = "2014-08-18T22:36:41.451Z"
+	diff, err := DiffApplications(a, b)
+	require.NoError(t, err)
+	assert.True(t, diff.Empty())
+
+	b.CPU(1).EmptyArgs().AddLabel("tier", "backend").AddLabel("owner", "ops")
+	b.Container.Docker.Container("python:3.6")
+	diff, err = DiffApplications(a, b)
+	require.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Path: "args", Type: ChangeRemoved, Old: []interface{}{"--port", "8080"}},
+		{Path: "container.docker.image", Type: ChangeModified, Old: "python:3", New: "python:3.6"},
+		{Path: "cpus", Type: ChangeModified, Old: 0.5, New: float64(1)},
+		{Path: "labels.owner", Type: ChangeAdded, New: "ops"},
+		{Path: "labels.team", Type: ChangeRemoved, Old: "web"},
+		{Path: "labels.tier", Type: ChangeModified, Old: "frontend", New: "backend"},
+	}, diff.Changes)
+	assert.Equal(t, `--- /app
++++ /app
+@@ args @@
+-[
+-  "--port",
+-  "8080"
+-]
+@@ container.docker.image @@
+-"python:3"
++"python:3.6"
+@@ cpus @@
+-0.5
++1
+@@ labels.owner @@
++"ops"
+@@ labels.team @@
+-"web"
+@@ labels.tier @@
+-"frontend"
++"backend"
+`, diff.String())
+
+	// step: the emptied values match the missing ones
+	a.Args = nil
+	a.EmptyLabels()
+	b.EmptyLabels()
+	diff, err = DiffApplications(a, b)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"container.docker.image", "cpus"}, diff.Paths())
+}
+
+func TestDiffPods(t *testing.T) {
+	a := NewPod().Name("/pod").Count(1).AddLabel("tier", "frontend").AddEnvironment("KEY", "value")
+	a.Version = "2014-09-12T23:28:21.737Z"
+	b := NewPod().Name("/pod").Count(2).AddEnvironment("KEY", "value")
+
+	diff, err := DiffPods(a, b)
+	require.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Path: "labels.tier", Type: ChangeRemoved, Old: "frontend"},
+		{Path: "scaling.instances", Type: ChangeModified, Old: float64(1), New: float64(2)},
+	}, diff.Changes)
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strings"
+	"time"
+)
+
+const defaultMemberRefreshInterval = 30 * time.Second
+
+// MemberSource discovers the endpoints of the Marathon members, in the same form as the URL of the Config
+type MemberSource func() ([]string, error)
+
+// Member describes a member of the Marathon cluster as seen by the client
+type Member struct {
+	// Endpoint is the URL of the member
+	Endpoint string
+	// Up indicates whether the member is considered available
+	Up bool
+	// Leader indicates whether the member is the last known leader
+	Leader bool
+}
+
+// SRVMemberSource discovers the Marathon members through a DNS SRV lookup
+//		service:	the service name, e.g. "marathon"
+//		proto:		the protocol, e.g. "tcp"
+//		name:		the domain name to look up
+//		scheme:		the scheme of the member endpoints, i.e. "http" or "https"
+func SRVMemberSource(service, proto, name, scheme string) MemberSource {
+	return func() ([]string, error) {
+		_, records, err := net.LookupSRV(service, proto, name)
+		if err != nil {
+			return nil, err
+		}
+
+		var endpoints []string
+		for _, record := range records {
+			host := strings.TrimSuffix(record.Target, ".")
+			endpoints = append(endpoints, fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(host, fmt.Sprint(record.Port))))
+		}
+
+		return endpoints, nil
+	}
+}
+
+// FileMemberSource discovers the Marathon members from a file, listing the endpoints separated by commas or
+// new lines. Blank lines and lines starting with '#' are ignored.
+//		path:		the path of the file
+func FileMemberSource(path string) MemberSource {
+	return func() ([]string, error) {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		var endpoints []string
+		for _, line := range strings.Split(string(content), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			for _, endpoint := range strings.Split(line, ",") {
+				if endpoint = strings.TrimSpace(endpoint); endpoint != "" {
+					endpoints = append(endpoints, endpoint)
+				}
+			}
+		}
+
+		return endpoints, nil
+	}
+}
+
+// Members retrieves the current members of the cluster and their status
+func (r *marathonClient) Members() []Member {
+	return r.hosts.snapshot()
+}
+
+// discoverMembers queries the member source for the members of the cluster
+func discoverMembers(source MemberSource) (string, error) {
+	endpoints, err := source()
+	if err != nil {
+		return "", err
+	}
+	if len(endpoints) == 0 {
+		return "", errors.New("the member source returned no endpoints")
+	}
+
+	return strings.Join(endpoints, ","), nil
+}
+
+// refreshMembers periodically updates the members of the cluster from the member source. On failure the
+// current members are kept.
+func (r *marathonClient) refreshMembers() {
+	interval := r.config.MemberRefreshInterval
+	if interval <= 0 {
+		interval = defaultMemberRefreshInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		members, err := discoverMembers(r.config.MemberSource)
+		if err == nil {
+			err = r.hosts.setMembers(members)
+		}
+		if err != nil {
+			r.logger.Warn("refreshMembers(): failed to discover the members", "error", err)
+		}
+	}
+}
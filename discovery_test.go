@@ -0,0 +1,94 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileMemberSource(t *testing.T) {
+	file, err := ioutil.TempFile("", "members")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString("# marathon members\nhttp://127.0.0.1:8080\n\n 127.0.0.2:8080 , 127.0.0.3:8080\n")
+	require.NoError(t, err)
+	file.Close()
+
+	endpoints, err := FileMemberSource(file.Name())()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"http://127.0.0.1:8080", "127.0.0.2:8080", "127.0.0.3:8080"}, endpoints)
+
+	_, err = FileMemberSource(file.Name() + ".missing")()
+	assert.Error(t, err)
+}
+
+func TestMemberSource(t *testing.T) {
+	var lock sync.Mutex
+	endpoints := []string{"http://127.0.0.1:8080", "http://127.0.0.2:8080"}
+	source := func() ([]string, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		return endpoints, nil
+	}
+
+	config := NewDefaultConfig()
+	config.URL = ""
+	config.MemberSource = source
+	config.MemberRefreshInterval = 10 * time.Millisecond
+	client, err := NewClient(config)
+	require.NoError(t, err)
+	assert.Equal(t, []Member{
+		{Endpoint: "http://127.0.0.1:8080", Up: true},
+		{Endpoint: "http://127.0.0.2:8080", Up: true},
+	}, client.Members())
+
+	lock.Lock()
+	endpoints = []string{"http://127.0.0.2:8080", "http://127.0.0.3:8080"}
+	lock.Unlock()
+	for i := 0; i < 100 && client.Members()[1].Endpoint != "http://127.0.0.3:8080"; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, []Member{
+		{Endpoint: "http://127.0.0.2:8080", Up: true},
+		{Endpoint: "http://127.0.0.3:8080", Up: true},
+	}, client.Members())
+}
+
+func TestMemberSourceFailure(t *testing.T) {
+	config := NewDefaultConfig()
+	config.MemberSource = func() ([]string, error) {
+		return nil, errors.New("no members")
+	}
+
+	// the configured url serves as fallback
+	client, err := NewClient(config)
+	require.NoError(t, err)
+	assert.Equal(t, []Member{{Endpoint: "http://127.0.0.1:8080", Up: true}}, client.Members())
+
+	config.URL = ""
+	_, err = NewClient(config)
+	assert.Error(t, err)
+}
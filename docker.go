@@ -105,6 +105,13 @@ func (p *PersistentVolume) AddConstraint(constraints ...string) *PersistentVolum
 	return p
 }
 
+// AddConstraints adds typed constraints, e.g. Unique("hostname") or Like("rack_id", "rack-[1-3]")
+//		constraints:	the constraints to add
+func (p *PersistentVolume) AddConstraints(constraints ...Constraint) *PersistentVolume {
+	p.Constraints = appendConstraints(p.Constraints, constraints)
+	return p
+}
+
 // EmptyConstraints explicitly empties constraints -- use this if you need to empty
 // constraints of an application that already has constraints set (setting constraints to nil will
 // keep the current value)
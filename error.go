@@ -56,20 +56,86 @@ func (e *InvalidEndpointError) Error() string {
 
 // newInvalidEndpointError creates a new error
 func newInvalidEndpointError(message string, args ...interface{}) error {
-	return &InvalidEndpointError{message: fmt.Sprintf(message, args)}
+	return &InvalidEndpointError{message: fmt.Sprintf(message, args...)}
 }
 
-// APIError represents a generic API error.
+var (
+	// ErrBadRequest matches the API errors of code ErrCodeBadRequest with errors.Is
+	ErrBadRequest = &APIError{ErrCode: ErrCodeBadRequest, message: "bad request"}
+	// ErrUnauthorized matches the API errors of code ErrCodeUnauthorized with errors.Is
+	ErrUnauthorized = &APIError{ErrCode: ErrCodeUnauthorized, message: "unauthorized"}
+	// ErrForbidden matches the API errors of code ErrCodeForbidden with errors.Is
+	ErrForbidden = &APIError{ErrCode: ErrCodeForbidden, message: "forbidden"}
+	// ErrNotFound matches the API errors of code ErrCodeNotFound with errors.Is
+	ErrNotFound = &APIError{ErrCode: ErrCodeNotFound, message: "not found"}
+	// ErrDuplicateID matches the API errors of code ErrCodeDuplicateID with errors.Is
+	ErrDuplicateID = &APIError{ErrCode: ErrCodeDuplicateID, message: "duplicate ID"}
+	// ErrAppLocked matches the API errors of code ErrCodeAppLocked with errors.Is
+	ErrAppLocked = &APIError{ErrCode: ErrCodeAppLocked, message: "locked by a deployment"}
+	// ErrInvalidBean matches the API errors of code ErrCodeInvalidBean with errors.Is
+	ErrInvalidBean = &APIError{ErrCode: ErrCodeInvalidBean, message: "invalid entity"}
+	// ErrServer matches the API errors of code ErrCodeServer with errors.Is
+	ErrServer = &APIError{ErrCode: ErrCodeServer, message: "server error"}
+)
+
+// APIError represents a generic API error. The details Marathon gives on some errors are available through
+// errors.As as *ValidationError or *DeploymentConflictError.
 type APIError struct {
 	// ErrCode specifies the nature of the error.
 	ErrCode int
 	message string
+	// detail is the typed error holding the details of the error, if any
+	detail error
 }
 
 func (e *APIError) Error() string {
 	return fmt.Sprintf("Marathon API error: %s", e.message)
 }
 
+// Is makes any API error match the API error sentinel of its code, e.g. ErrNotFound
+func (e *APIError) Is(target error) bool {
+	sentinel, ok := target.(*APIError)
+	return ok && sentinel.ErrCode == e.ErrCode
+}
+
+// Unwrap returns the typed details of the error, if any
+func (e *APIError) Unwrap() error {
+	return e.detail
+}
+
+// Violation is a constraint violated by an entity sent to Marathon
+type Violation struct {
+	// Path is the path of the attribute in violation, e.g. "/container/docker/image"
+	Path string
+	// Errors describes the violations of the attribute
+	Errors []string
+}
+
+// ValidationError details a 400 Bad Request or 422 Unprocessable Entity error caused by an invalid entity
+type ValidationError struct {
+	Message    string
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	var violations []string
+	for _, violation := range e.Violations {
+		violations = append(violations, fmt.Sprintf("path: '%s' errors: %s", violation.Path, strings.Join(violation.Errors, ", ")))
+	}
+	return fmt.Sprintf("%s (%s)", e.Message, strings.Join(violations, "; "))
+}
+
+// DeploymentConflictError details a 409 Conflict error caused by the deployments locking the entity
+type DeploymentConflictError struct {
+	Message string
+	// DeploymentIDs are the IDs of the deployments locking the entity
+	DeploymentIDs []string
+}
+
+func (e *DeploymentConflictError) Error() string {
+	return fmt.Sprintf("%s (locking deployment IDs: %s)", e.Message, strings.Join(e.DeploymentIDs, ", "))
+}
+
 // NewAPIError creates a new APIError instance from the given response code and content.
 func NewAPIError(code int, content []byte) error {
 	var errDef errorDefinition
@@ -100,15 +166,24 @@ type errorDefinition interface {
 	errCode() int
 }
 
+// detailedErrorDefinition is implemented by the error definitions providing typed details
+type detailedErrorDefinition interface {
+	detail() error
+}
+
 func parseContent(errDef errorDefinition, content []byte) error {
 	// If the content cannot be JSON-unmarshalled, we assume that it's not JSON
 	// and encode it into the APIError instance as-is.
 	errMessage := string(content)
+	var detail error
 	if err := json.Unmarshal(content, errDef); err == nil {
 		errMessage = errDef.message()
+		if detailed, ok := errDef.(detailedErrorDefinition); ok {
+			detail = detailed.detail()
+		}
 	}
 
-	return &APIError{message: errMessage, ErrCode: errDef.errCode()}
+	return &APIError{message: errMessage, ErrCode: errDef.errCode(), detail: detail}
 }
 
 type simpleErrDef struct {
@@ -151,6 +226,18 @@ func (def *badRequestDef) errCode() int {
 	return ErrCodeBadRequest
 }
 
+func (def *badRequestDef) detail() error {
+	if len(def.Details) == 0 {
+		return nil
+	}
+
+	err := &ValidationError{Message: def.Message}
+	for _, detail := range def.Details {
+		err.Violations = append(err.Violations, Violation{Path: detail.Path, Errors: detail.Errors})
+	}
+	return err
+}
+
 type conflictDef struct {
 	Message     string `json:"message"`
 	Deployments []struct {
@@ -180,6 +267,18 @@ func (def *conflictDef) errCode() int {
 	return ErrCodeAppLocked
 }
 
+func (def *conflictDef) detail() error {
+	if len(def.Deployments) == 0 {
+		return nil
+	}
+
+	err := &DeploymentConflictError{Message: def.Message}
+	for _, deployment := range def.Deployments {
+		err.DeploymentIDs = append(err.DeploymentIDs, deployment.ID)
+	}
+	return err
+}
+
 type unprocessableEntityDetails []struct {
 	// Used in Marathon >= 1.0.0-RC1.
 	detailDescription
@@ -223,3 +322,23 @@ func (def *unprocessableEntityDef) message() string {
 func (def *unprocessableEntityDef) errCode() int {
 	return ErrCodeInvalidBean
 }
+
+func (def *unprocessableEntityDef) detail() error {
+	details := def.Details
+	if len(def.Errors) > 0 {
+		details = def.Errors
+	}
+	if len(details) == 0 {
+		return nil
+	}
+
+	err := &ValidationError{Message: def.Message}
+	for _, detail := range details {
+		if len(detail.Attribute) > 0 {
+			err.Violations = append(err.Violations, Violation{Path: detail.Attribute, Errors: []string{detail.Error}})
+		} else {
+			err.Violations = append(err.Violations, Violation{Path: detail.Path, Errors: detail.Errors})
+		}
+	}
+	return err
+}
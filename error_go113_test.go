@@ -0,0 +1,44 @@
+//go:build go1.13
+// +build go1.13
+
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIErrorsWithErrorsPackage(t *testing.T) {
+	err := fmt.Errorf("updating: %w", NewAPIError(http.StatusConflict, []byte(`{"message":"App is locked", "deployments": [{"id": "1"}]}`)))
+	assert.True(t, errors.Is(err, ErrAppLocked))
+	assert.False(t, errors.Is(err, ErrNotFound))
+
+	var conflictErr *DeploymentConflictError
+	if assert.True(t, errors.As(err, &conflictErr)) {
+		assert.Equal(t, []string{"1"}, conflictErr.DeploymentIDs)
+	}
+
+	var validationErr *ValidationError
+	assert.False(t, errors.As(err, &validationErr))
+	assert.True(t, errors.As(NewAPIError(http.StatusBadRequest, []byte(content400())), &validationErr))
+}
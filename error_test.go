@@ -186,3 +186,57 @@ func content422V1() string {
 	]
 }`
 }
+
+func TestAPIErrorIs(t *testing.T) {
+	err := NewAPIError(http.StatusNotFound, []byte(`{"message": "App '/foo' does not exist"}`))
+	apiErr := err.(*APIError)
+	assert.True(t, apiErr.Is(ErrNotFound))
+	assert.False(t, apiErr.Is(ErrForbidden))
+	assert.False(t, apiErr.Is(ErrTimeoutError))
+	assert.Nil(t, apiErr.Unwrap())
+
+	apiErr = NewAPIError(http.StatusServiceUnavailable, nil).(*APIError)
+	assert.True(t, apiErr.Is(ErrServer))
+}
+
+func TestAPIErrorDetails(t *testing.T) {
+	apiErr := NewAPIError(http.StatusBadRequest, []byte(content400())).(*APIError)
+	validationErr, ok := apiErr.Unwrap().(*ValidationError)
+	if assert.True(t, ok) {
+		assert.Equal(t, "Invalid JSON", validationErr.Message)
+		assert.Equal(t, []Violation{
+			{Path: "/id", Errors: []string{"error.expected.jsstring", "error.something.else"}},
+			{Path: "/name", Errors: []string{"error.not.inventive"}},
+		}, validationErr.Violations)
+	}
+
+	for _, content := range []string{content422("details"), content422("errors")} {
+		apiErr = NewAPIError(422, []byte(content)).(*APIError)
+		validationErr, ok = apiErr.Unwrap().(*ValidationError)
+		if assert.True(t, ok) {
+			assert.Equal(t, []Violation{
+				{Path: "upgradeStrategy.minimumHealthCapacity", Errors: []string{"is greater than 1"}},
+				{Path: "foobar", Errors: []string{"foo does not have enough bar"}},
+			}, validationErr.Violations)
+		}
+	}
+
+	apiErr = NewAPIError(422, []byte(content422V1())).(*APIError)
+	validationErr, ok = apiErr.Unwrap().(*ValidationError)
+	if assert.True(t, ok) {
+		assert.Equal(t, Violation{Path: "/value", Errors: []string{"service port conflict app /app1", "service port conflict app /app2"}}, validationErr.Violations[1])
+	}
+
+	apiErr = NewAPIError(http.StatusConflict, []byte(`{"message":"App is locked", "deployments": [{"id": "1"}, {"id": "2"}]}`)).(*APIError)
+	conflictErr, ok := apiErr.Unwrap().(*DeploymentConflictError)
+	if assert.True(t, ok) {
+		assert.Equal(t, []string{"1", "2"}, conflictErr.DeploymentIDs)
+		assert.Equal(t, "App is locked (locking deployment IDs: 1, 2)", conflictErr.Error())
+	}
+
+	apiErr = NewAPIError(http.StatusConflict, []byte(`{"message": "An app with id [/existing_app] already exists."}`)).(*APIError)
+	assert.Nil(t, apiErr.Unwrap())
+
+	apiErr = NewAPIError(422, []byte(`{"message": "Object is not valid"}`)).(*APIError)
+	assert.Nil(t, apiErr.Unwrap())
+}
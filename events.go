@@ -16,7 +16,10 @@ limitations under the License.
 
 package marathon
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+)
 
 // EventType is a wrapper for a marathon event
 type EventType struct {
@@ -58,14 +61,42 @@ const (
 	EventIDDeploymentInfo
 	// EventIDDeploymentStepSuccess is the event listener ID for the corresponding event.
 	EventIDDeploymentStepSuccess
-	// EventIDDeploymentStepFailed is the event listener ID for the corresponding event.
-	EventIDDeploymentStepFailed
+	// EventIDDeploymentStepFailure is the event listener ID for the corresponding event.
+	EventIDDeploymentStepFailure
 	// EventIDAppTerminated is the event listener ID for the corresponding event.
 	EventIDAppTerminated
+	// EventIDStreamReconnected is the event listener ID for the synthetic event sent by the client once the
+	// event stream has been re-established.
+	EventIDStreamReconnected
+	// EventIDInstanceChanged is the event listener ID for the corresponding event.
+	EventIDInstanceChanged
+	// EventIDInstanceHealthChanged is the event listener ID for the corresponding event.
+	EventIDInstanceHealthChanged
+	// EventIDUnknownInstanceTerminated is the event listener ID for the corresponding event.
+	EventIDUnknownInstanceTerminated
+	// EventIDUnhealthyInstanceKill is the event listener ID for the corresponding event, as well as for its
+	// predecessor 'unhealthy_task_kill_event'.
+	EventIDUnhealthyInstanceKill
+	// EventIDPodCreated is the event listener ID for the corresponding event.
+	EventIDPodCreated
+	// EventIDPodUpdated is the event listener ID for the corresponding event.
+	EventIDPodUpdated
+	// EventIDPodDeleted is the event listener ID for the corresponding event.
+	EventIDPodDeleted
+	// EventIDUnknown is the event listener ID for the events of a type unknown to the client, which are
+	// delivered undecoded as EventUnknown.
+	EventIDUnknown
 	//EventIDApplications comprises all listener IDs for application events.
 	EventIDApplications = EventIDStatusUpdate | EventIDChangedHealthCheck | EventIDFailedHealthCheck | EventIDAppTerminated
 	//EventIDSubscriptions comprises all listener IDs for subscription events.
 	EventIDSubscriptions = EventIDSubscription | EventIDUnsubscribed | EventIDStreamAttached | EventIDStreamDetached
+	//EventIDInstances comprises all listener IDs for instance events.
+	EventIDInstances = EventIDInstanceChanged | EventIDInstanceHealthChanged | EventIDUnknownInstanceTerminated | EventIDUnhealthyInstanceKill
+	//EventIDPods comprises all listener IDs for pod events.
+	EventIDPods = EventIDPodCreated | EventIDPodUpdated | EventIDPodDeleted
+	// EventIDDeploymentStepFailed is the former name of EventIDDeploymentStepFailure.
+	// Deprecated: use EventIDDeploymentStepFailure.
+	EventIDDeploymentStepFailed = EventIDDeploymentStepFailure
 )
 
 var (
@@ -74,25 +105,33 @@ var (
 
 func init() {
 	eventTypesMap = map[string]int{
-		"api_post_event":              EventIDAPIRequest,
-		"status_update_event":         EventIDStatusUpdate,
-		"framework_message_event":     EventIDFrameworkMessage,
-		"subscribe_event":             EventIDSubscription,
-		"unsubscribe_event":           EventIDUnsubscribed,
-		"event_stream_attached":       EventIDStreamAttached,
-		"event_stream_detached":       EventIDStreamDetached,
-		"add_health_check_event":      EventIDAddHealthCheck,
-		"remove_health_check_event":   EventIDRemoveHealthCheck,
-		"failed_health_check_event":   EventIDFailedHealthCheck,
-		"health_status_changed_event": EventIDChangedHealthCheck,
-		"group_change_success":        EventIDGroupChangeSuccess,
-		"group_change_failed":         EventIDGroupChangeFailed,
-		"deployment_success":          EventIDDeploymentSuccess,
-		"deployment_failed":           EventIDDeploymentFailed,
-		"deployment_info":             EventIDDeploymentInfo,
-		"deployment_step_success":     EventIDDeploymentStepSuccess,
-		"deployment_step_failure":     EventIDDeploymentStepFailed,
-		"app_terminated_event":        EventIDAppTerminated,
+		"api_post_event":                    EventIDAPIRequest,
+		"status_update_event":               EventIDStatusUpdate,
+		"framework_message_event":           EventIDFrameworkMessage,
+		"subscribe_event":                   EventIDSubscription,
+		"unsubscribe_event":                 EventIDUnsubscribed,
+		"event_stream_attached":             EventIDStreamAttached,
+		"event_stream_detached":             EventIDStreamDetached,
+		"add_health_check_event":            EventIDAddHealthCheck,
+		"remove_health_check_event":         EventIDRemoveHealthCheck,
+		"failed_health_check_event":         EventIDFailedHealthCheck,
+		"health_status_changed_event":       EventIDChangedHealthCheck,
+		"group_change_success":              EventIDGroupChangeSuccess,
+		"group_change_failed":               EventIDGroupChangeFailed,
+		"deployment_success":                EventIDDeploymentSuccess,
+		"deployment_failed":                 EventIDDeploymentFailed,
+		"deployment_info":                   EventIDDeploymentInfo,
+		"deployment_step_success":           EventIDDeploymentStepSuccess,
+		"deployment_step_failure":           EventIDDeploymentStepFailure,
+		"app_terminated_event":              EventIDAppTerminated,
+		"instance_changed_event":            EventIDInstanceChanged,
+		"instance_health_changed_event":     EventIDInstanceHealthChanged,
+		"unknown_instance_terminated_event": EventIDUnknownInstanceTerminated,
+		"unhealthy_instance_kill_event":     EventIDUnhealthyInstanceKill,
+		"unhealthy_task_kill_event":         EventIDUnhealthyInstanceKill,
+		"pod_created_event":                 EventIDPodCreated,
+		"pod_updated_event":                 EventIDPodUpdated,
+		"pod_deleted_event":                 EventIDPodDeleted,
 	}
 }
 
@@ -149,6 +188,89 @@ type EventAppTerminated struct {
 	AppID     string `json:"appId"`
 }
 
+/* --- Instances --- */
+
+// EventInstanceChanged describes an 'instance_changed_event' event.
+type EventInstanceChanged struct {
+	EventType      string `json:"eventType"`
+	Timestamp      string `json:"timestamp,omitempty"`
+	InstanceID     string `json:"instanceId"`
+	Condition      string `json:"condition"`
+	RunSpecID      string `json:"runSpecId"`
+	AgentID        string `json:"agentId,omitempty"`
+	Host           string `json:"host"`
+	RunSpecVersion string `json:"runSpecVersion,omitempty"`
+}
+
+// EventInstanceHealthChanged describes an 'instance_health_changed_event' event.
+type EventInstanceHealthChanged struct {
+	EventType      string `json:"eventType"`
+	Timestamp      string `json:"timestamp,omitempty"`
+	InstanceID     string `json:"instanceId"`
+	RunSpecID      string `json:"runSpecId"`
+	RunSpecVersion string `json:"runSpecVersion,omitempty"`
+	// Healthy is nil when the health of the instance is unknown
+	Healthy *bool `json:"healthy"`
+}
+
+// EventUnknownInstanceTerminated describes an 'unknown_instance_terminated_event' event.
+type EventUnknownInstanceTerminated struct {
+	EventType  string `json:"eventType"`
+	Timestamp  string `json:"timestamp,omitempty"`
+	InstanceID string `json:"instanceId"`
+	RunSpecID  string `json:"runSpecId"`
+	Condition  string `json:"condition"`
+}
+
+// EventUnhealthyInstanceKill describes an 'unhealthy_instance_kill_event' event, or an
+// 'unhealthy_task_kill_event' event for Marathon before 1.4.
+type EventUnhealthyInstanceKill struct {
+	EventType  string `json:"eventType"`
+	Timestamp  string `json:"timestamp,omitempty"`
+	AppID      string `json:"appId"`
+	TaskID     string `json:"taskId"`
+	InstanceID string `json:"instanceId,omitempty"`
+	Version    string `json:"version,omitempty"`
+	Reason     string `json:"reason"`
+	Host       string `json:"host"`
+	SlaveID    string `json:"slaveId,omitempty"`
+}
+
+/* --- Pods --- */
+
+// EventPodCreated describes a 'pod_created_event' event.
+type EventPodCreated struct {
+	EventType string `json:"eventType"`
+	Timestamp string `json:"timestamp"`
+	ClientIP  string `json:"clientIp"`
+	URI       string `json:"uri"`
+}
+
+// EventPodUpdated describes a 'pod_updated_event' event.
+type EventPodUpdated struct {
+	EventType string `json:"eventType"`
+	Timestamp string `json:"timestamp"`
+	ClientIP  string `json:"clientIp"`
+	URI       string `json:"uri"`
+}
+
+// EventPodDeleted describes a 'pod_deleted_event' event.
+type EventPodDeleted struct {
+	EventType string `json:"eventType"`
+	Timestamp string `json:"timestamp"`
+	ClientIP  string `json:"clientIp"`
+	URI       string `json:"uri"`
+}
+
+/* --- Unknown --- */
+
+// EventUnknown holds an event of a type unknown to the client, e.g. one introduced by a newer Marathon version.
+type EventUnknown struct {
+	EventType string
+	// Raw is the undecoded JSON content of the event
+	Raw json.RawMessage
+}
+
 /* --- Framework Message --- */
 
 // EventFrameworkMessage describes a 'framework_message_event' event.
@@ -192,6 +314,16 @@ type EventStreamDetached struct {
 	Timestamp     string `json:"timestamp"`
 }
 
+// EventStreamReconnected describes an 'event_stream_reconnected' event. It is not sent by Marathon but by the
+// client, once the event stream has been re-established after it broke or went stale. Marathon may not replay
+// the events emitted in between, so listeners should resynchronize their state.
+type EventStreamReconnected struct {
+	EventType string `json:"eventType"`
+	Timestamp string `json:"timestamp"`
+	// LastEventID is the ID of the last event received before the reconnection, if any
+	LastEventID string `json:"lastEventId,omitempty"`
+}
+
 /* --- Health Checks --- */
 
 // EventAddHealthCheck describes an 'add_health_check_event' event.
@@ -358,6 +490,20 @@ func GetEvent(eventType string) (*Event, error) {
 			event.Event = new(EventDeploymentStepFailure)
 		case "app_terminated_event":
 			event.Event = new(EventAppTerminated)
+		case "instance_changed_event":
+			event.Event = new(EventInstanceChanged)
+		case "instance_health_changed_event":
+			event.Event = new(EventInstanceHealthChanged)
+		case "unknown_instance_terminated_event":
+			event.Event = new(EventUnknownInstanceTerminated)
+		case "unhealthy_instance_kill_event", "unhealthy_task_kill_event":
+			event.Event = new(EventUnhealthyInstanceKill)
+		case "pod_created_event":
+			event.Event = new(EventPodCreated)
+		case "pod_updated_event":
+			event.Event = new(EventPodUpdated)
+		case "pod_deleted_event":
+			event.Event = new(EventPodDeleted)
 		}
 		return event, nil
 	}
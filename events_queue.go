@@ -0,0 +1,175 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import "sync"
+
+const defaultEventsQueueSize = 100
+
+// EventsOverflowPolicy decides what happens to the events of a listener whose queue is full
+type EventsOverflowPolicy int
+
+const (
+	// EventsOverflowBlock holds up the dispatching of further events until the listener has caught up
+	EventsOverflowBlock EventsOverflowPolicy = iota
+	// EventsOverflowDropOldest discards the oldest queued event to make room for the new one
+	EventsOverflowDropOldest
+	// EventsOverflowDropNewest discards the new event
+	EventsOverflowDropNewest
+	// EventsOverflowDisconnect removes the listener, closing its channel
+	EventsOverflowDisconnect
+)
+
+// EventsListenerOptions configures how the events are queued for a listener
+type EventsListenerOptions struct {
+	// QueueSize is the number of events queued for the listener until the overflow policy applies, defaults to 100
+	QueueSize int
+	// Overflow is the policy applied when the queue is full, defaults to EventsOverflowBlock
+	Overflow EventsOverflowPolicy
+}
+
+// EventsListenerStats describes the state of the queue of a listener
+type EventsListenerStats struct {
+	// Queued is the number of events waiting to be received from the channel
+	Queued int
+	// Delivered is the number of events received from the channel
+	Delivered uint64
+	// Dropped is the number of events discarded by the overflow policy
+	Dropped uint64
+}
+
+// eventsQueue is the bounded queue of the events of a listener, which are delivered in order by a single
+// go routine
+type eventsQueue struct {
+	sync.Mutex
+	events    []*Event
+	size      int
+	overflow  EventsOverflowPolicy
+	delivered uint64
+	dropped   uint64
+	// ready is signaled when an event has been queued
+	ready chan struct{}
+	// space is signaled when an event has been taken from the queue
+	space chan struct{}
+}
+
+func newEventsQueue(options EventsListenerOptions) *eventsQueue {
+	size := options.QueueSize
+	if size <= 0 {
+		size = defaultEventsQueueSize
+	}
+
+	return &eventsQueue{
+		size:     size,
+		overflow: options.Overflow,
+		ready:    make(chan struct{}, 1),
+		space:    make(chan struct{}, 1),
+	}
+}
+
+// push queues the event, applying the overflow policy when the queue is full. It returns false when the
+// listener has to be disconnected.
+//		event:		the event to queue
+//		done:		closed when the listener is removed
+func (q *eventsQueue) push(event *Event, done <-chan struct{}) bool {
+	for {
+		q.Lock()
+		if len(q.events) < q.size {
+			q.events = append(q.events, event)
+			q.Unlock()
+			signal(q.ready)
+			return true
+		}
+
+		switch q.overflow {
+		case EventsOverflowDropOldest:
+			q.events[0] = nil
+			q.events = append(q.events[1:], event)
+			q.dropped++
+			q.Unlock()
+			return true
+		case EventsOverflowDropNewest:
+			q.dropped++
+			q.Unlock()
+			return true
+		case EventsOverflowDisconnect:
+			q.dropped++
+			q.Unlock()
+			return false
+		}
+		q.Unlock()
+
+		// step: wait for the listener to catch up
+		select {
+		case <-q.space:
+		case <-done:
+			return true
+		}
+	}
+}
+
+// deliver sends the queued events to the channel until the listener is removed
+//		channel:	the channel of the listener
+//		done:		closed when the listener is removed
+func (q *eventsQueue) deliver(channel EventsChannel, done <-chan struct{}) {
+	for {
+		q.Lock()
+		if len(q.events) == 0 {
+			q.Unlock()
+			select {
+			case <-q.ready:
+				continue
+			case <-done:
+				return
+			}
+		}
+		event := q.events[0]
+		q.events[0] = nil
+		q.events = q.events[1:]
+		q.Unlock()
+		signal(q.space)
+
+		select {
+		case channel <- event:
+			q.Lock()
+			q.delivered++
+			q.Unlock()
+		case <-done:
+			return
+		}
+	}
+}
+
+// stats returns the current state of the queue
+func (q *eventsQueue) stats() EventsListenerStats {
+	q.Lock()
+	defer q.Unlock()
+
+	return EventsListenerStats{
+		Queued:    len(q.events),
+		Delivered: q.delivered,
+		Dropped:   q.dropped,
+	}
+}
+
+// signal notifies the waiter on the channel, if not notified already
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
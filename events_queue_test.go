@@ -0,0 +1,122 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func queuedEvents(count int) []*Event {
+	var events []*Event
+	for i := 0; i < count; i++ {
+		events = append(events, &Event{ID: EventIDStatusUpdate, Name: string('a' + rune(i))})
+	}
+	return events
+}
+
+func eventNames(q *eventsQueue) []string {
+	var names []string
+	for _, event := range q.events {
+		names = append(names, event.Name)
+	}
+	return names
+}
+
+func TestEventsQueueOverflow(t *testing.T) {
+	done := make(chan struct{})
+	events := queuedEvents(3)
+
+	q := newEventsQueue(EventsListenerOptions{QueueSize: 2, Overflow: EventsOverflowDropOldest})
+	for _, event := range events {
+		assert.True(t, q.push(event, done))
+	}
+	assert.Equal(t, []string{"b", "c"}, eventNames(q))
+	assert.Equal(t, EventsListenerStats{Queued: 2, Dropped: 1}, q.stats())
+
+	q = newEventsQueue(EventsListenerOptions{QueueSize: 2, Overflow: EventsOverflowDropNewest})
+	for _, event := range events {
+		assert.True(t, q.push(event, done))
+	}
+	assert.Equal(t, []string{"a", "b"}, eventNames(q))
+	assert.Equal(t, uint64(1), q.stats().Dropped)
+
+	q = newEventsQueue(EventsListenerOptions{QueueSize: 2, Overflow: EventsOverflowDisconnect})
+	assert.True(t, q.push(events[0], done))
+	assert.True(t, q.push(events[1], done))
+	assert.False(t, q.push(events[2], done))
+
+	q = newEventsQueue(EventsListenerOptions{})
+	assert.Equal(t, defaultEventsQueueSize, q.size)
+	assert.Equal(t, EventsOverflowBlock, q.overflow)
+}
+
+func TestEventsQueueBlock(t *testing.T) {
+	done := make(chan struct{})
+	events := queuedEvents(3)
+
+	q := newEventsQueue(EventsListenerOptions{QueueSize: 1})
+	require.True(t, q.push(events[0], done))
+
+	pushed := make(chan bool)
+	go func() {
+		pushed <- q.push(events[1], done)
+	}()
+	select {
+	case <-pushed:
+		require.Fail(t, "the push did not block on a full queue")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	channel := make(EventsChannel)
+	go q.deliver(channel, done)
+	defer close(done)
+
+	// step: the events are delivered in order, unblocking the pending push
+	for _, expected := range events[:2] {
+		select {
+		case event := <-channel:
+			assert.Equal(t, expected.Name, event.Name)
+		case <-time.After(time.Second):
+			require.Fail(t, "did not receive event in time")
+		}
+	}
+	assert.True(t, <-pushed)
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, EventsListenerStats{Delivered: 2}, q.stats())
+}
+
+func TestEventsQueueBlockRemoved(t *testing.T) {
+	done := make(chan struct{})
+	q := newEventsQueue(EventsListenerOptions{QueueSize: 1})
+	require.True(t, q.push(queuedEvents(1)[0], done))
+
+	pushed := make(chan bool)
+	go func() {
+		pushed <- q.push(queuedEvents(1)[0], done)
+	}()
+	close(done)
+
+	select {
+	case <-pushed:
+	case <-time.After(time.Second):
+		assert.Fail(t, "the push was not abandoned after the listener was removed")
+	}
+}
@@ -156,51 +156,38 @@ func (r *marathonClient) CreateGroup(group *Group) error {
 // 		group:			the identifier for the group
 //		timeout: 		a duration of time to wait before considering it failed (all tasks in all apps running defined as deployed)
 func (r *marathonClient) WaitOnGroup(name string, timeout time.Duration) error {
-	err := deadline(timeout, func(stop_channel chan bool) error {
-		var flick atomicSwitch
-		go func() {
-			<-stop_channel
-			close(stop_channel)
-			flick.SwitchOn()
-		}()
-		for !flick.IsSwitched() {
-			if group, err := r.Group(name); err != nil {
-				continue
-			} else {
-				allRunning := true
-				// for each of the application, check if the tasks and running
-				for _, appID := range group.Apps {
-					// Arrrgghhh!! .. so we can't use application instances from the Application struct like with app wait on as it
-					// appears the instance count is not set straight away!! .. it defaults to zero and changes probably at the
-					// dependencies gets deployed. Which is probably how it internally handles dependencies ..
-					// step: grab the application
-					application, err := r.Application(appID.ID)
-					if err != nil {
-						allRunning = false
-						break
-					}
-
-					if application.Tasks == nil {
-						allRunning = false
-					} else if len(application.Tasks) != *appID.Instances {
-						allRunning = false
-					} else if application.TasksRunning != *appID.Instances {
-						allRunning = false
-					} else if len(application.DeploymentIDs()) > 0 {
-						allRunning = false
-					}
-				}
-				// has anyone toggle the flag?
-				if allRunning {
-					return nil
-				}
-			}
-			time.Sleep(r.config.PollingWaitTime)
-		}
-		return nil
+	return r.waitUntil(timeout, watchRunSpecs(matchGroup(name)), func(client *marathonClient) (bool, error) {
+		return client.groupDeployed(name), nil
 	})
+}
+
+// groupDeployed checks if all the applications of the group are deployed, i.e. all their tasks are running
+func (r *marathonClient) groupDeployed(name string) bool {
+	group, err := r.Group(name)
+	if err != nil {
+		return false
+	}
+
+	// for each of the application, check if the tasks and running
+	for _, appID := range group.Apps {
+		// Arrrgghhh!! .. so we can't use application instances from the Application struct like with app wait on as it
+		// appears the instance count is not set straight away!! .. it defaults to zero and changes probably at the
+		// dependencies gets deployed. Which is probably how it internally handles dependencies ..
+		// step: grab the application
+		application, err := r.Application(appID.ID)
+		if err != nil {
+			return false
+		}
+
+		if application.Tasks == nil ||
+			len(application.Tasks) != *appID.Instances ||
+			application.TasksRunning != *appID.Instances ||
+			len(application.DeploymentIDs()) > 0 {
+			return false
+		}
+	}
 
-	return err
+	return true
 }
 
 // DeleteGroup deletes a group from marathon
@@ -212,7 +199,7 @@ func (r *marathonClient) DeleteGroup(name string, force bool) (*DeploymentID, er
 	if force {
 		path += "?force=true"
 	}
-	if err := r.apiDelete(path, nil, version); err != nil {
+	if err := r.resolveConflicts(func() error { return r.apiDelete(path, nil, version) }); err != nil {
 		return nil, err
 	}
 
@@ -229,7 +216,7 @@ func (r *marathonClient) UpdateGroup(name string, group *Group, force bool) (*De
 	if force {
 		path += "?force=true"
 	}
-	if err := r.apiPut(path, group, deploymentID); err != nil {
+	if err := r.resolveConflicts(func() error { return r.apiPut(path, group, deploymentID) }); err != nil {
 		return nil, err
 	}
 
@@ -82,6 +82,7 @@ func (r *marathonClient) Leader() (string, error) {
 	if err := r.apiGet(marathonAPILeader, nil, &leader); err != nil {
 		return "", err
 	}
+	r.hosts.setLeader(leader.Leader)
 
 	return leader.Leader, nil
 }
@@ -0,0 +1,863 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// IndexLabel indexes the applications and the pods by their labels, as "key=value"
+	IndexLabel = "label"
+	// IndexHost indexes the tasks by the host they run on
+	IndexHost = "host"
+	// IndexAppID indexes the tasks and the deployments by the IDs of their applications, e.g. "/prod/web"
+	IndexAppID = "appId"
+	// IndexAppPrefix indexes the applications, the pods, the tasks and the deployments by the groups of the IDs of
+	// their applications, e.g. "/", "/prod/" and "/prod/web/" for "/prod/web/api"
+	IndexAppPrefix = "appPrefix"
+
+	// informerEventsFilter comprises the listener IDs of the events the informer keeps its state current with
+	informerEventsFilter = EventIDAPIRequest | EventIDApplications | EventIDPods | EventIDGroupChangeSuccess |
+		cacheDeploymentEvents | EventIDStreamReconnected
+)
+
+// ErrInformerStarted is returned when configuring an informer which has already been started
+var ErrInformerStarted = errors.New("the informer has already been started")
+
+// informerAppsEmbed and informerAppEmbed are the fields embedded in the applications, the same when listing and
+// refreshing them so that an unchanged application is equal either way. The tasks are kept apart.
+var (
+	informerAppsEmbed = []string{"apps.counts", "apps.deployments", "apps.lastTaskFailure", "apps.readiness"}
+	informerAppEmbed  = []string{"app.counts", "app.deployments", "app.lastTaskFailure", "app.readiness", "app.tasks"}
+)
+
+// informerTerminalStates are the task states of the tasks which are gone
+var informerTerminalStates = map[string]bool{
+	"TASK_FINISHED":         true,
+	"TASK_FAILED":           true,
+	"TASK_KILLED":           true,
+	"TASK_LOST":             true,
+	"TASK_ERROR":            true,
+	"TASK_DROPPED":          true,
+	"TASK_GONE":             true,
+	"TASK_GONE_BY_OPERATOR": true,
+	"TASK_UNKNOWN":          true,
+}
+
+// InformerOptions configures an informer
+type InformerOptions struct {
+	// ResyncPeriod is the interval of the full lists reconciling the state with Marathon, e.g. to recover from
+	// failed refreshes. Zero disables them, leaving only the one following a reconnection of the event stream.
+	ResyncPeriod time.Duration
+	// Events configures the queue of the events of the informer
+	Events EventsListenerOptions
+	// ErrorHandler is called with the errors of the refreshes and the resyncs, which are otherwise ignored
+	ErrorHandler func(err error)
+}
+
+// InformerHandler is notified of the changes of the state of an informer. The objects are an *Application, a *Pod,
+// a *Task or a *Deployment and are shared with the informer, so they must not be modified. The methods are called
+// one at a time, from the go routine of the informer.
+type InformerHandler interface {
+	// OnAdd is called when an object has been added
+	OnAdd(obj interface{})
+	// OnUpdate is called when an object has changed
+	OnUpdate(oldObj, newObj interface{})
+	// OnDelete is called when an object has been removed
+	OnDelete(obj interface{})
+}
+
+// InformerHandlerFuncs is an InformerHandler made of optional functions
+type InformerHandlerFuncs struct {
+	AddFunc    func(obj interface{})
+	UpdateFunc func(oldObj, newObj interface{})
+	DeleteFunc func(obj interface{})
+}
+
+// OnAdd calls AddFunc, if any
+func (h InformerHandlerFuncs) OnAdd(obj interface{}) {
+	if h.AddFunc != nil {
+		h.AddFunc(obj)
+	}
+}
+
+// OnUpdate calls UpdateFunc, if any
+func (h InformerHandlerFuncs) OnUpdate(oldObj, newObj interface{}) {
+	if h.UpdateFunc != nil {
+		h.UpdateFunc(oldObj, newObj)
+	}
+}
+
+// OnDelete calls DeleteFunc, if any
+func (h InformerHandlerFuncs) OnDelete(obj interface{}) {
+	if h.DeleteFunc != nil {
+		h.DeleteFunc(obj)
+	}
+}
+
+// IndexFunc returns the values an object is indexed by, nil when the index does not apply to the object
+type IndexFunc func(obj interface{}) []string
+
+// Informer mirrors the applications, the pods, the tasks and the deployments of Marathon in memory. It lists them
+// once started, keeps them current from the events of Marathon and periodically resyncs them. The getters are safe
+// for concurrent use, and the objects they return must not be modified.
+type Informer struct {
+	sync.RWMutex
+	client  Marathon
+	options InformerOptions
+	// the objects by kind and ID, e.g. "app:/prod/web"
+	objects map[string]interface{}
+	// the index functions by name
+	indexers map[string]IndexFunc
+	// the keys of the objects by index name and value
+	indices  map[string]map[string]map[string]bool
+	handlers []InformerHandler
+	events   EventsChannel
+	started  bool
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// informerChange is a change of the state of an informer, to be notified to the handlers
+type informerChange struct {
+	oldObj interface{}
+	newObj interface{}
+}
+
+// NewInformer creates an informer of the state of Marathon, with the default indices
+//		client:		the client of Marathon, which needs an events transport
+//		options:	the options of the informer
+func NewInformer(client Marathon, options InformerOptions) *Informer {
+	return &Informer{
+		client:  client,
+		options: options,
+		objects: make(map[string]interface{}),
+		indexers: map[string]IndexFunc{
+			IndexLabel:     indexLabel,
+			IndexHost:      indexHost,
+			IndexAppID:     indexAppID,
+			IndexAppPrefix: indexAppPrefix,
+		},
+		indices: make(map[string]map[string]map[string]bool),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// AddIndexer adds an index to the informer, which has not been started yet
+//		name:		the name of the index
+//		indexer:	the function returning the values an object is indexed by
+func (i *Informer) AddIndexer(name string, indexer IndexFunc) error {
+	i.Lock()
+	defer i.Unlock()
+
+	if i.started {
+		return ErrInformerStarted
+	}
+	if _, found := i.indexers[name]; found {
+		return fmt.Errorf("the index: %s already exists", name)
+	}
+	i.indexers[name] = indexer
+
+	return nil
+}
+
+// AddHandler registers a handler of the changes of the state. A handler added before the informer has been started
+// is notified of the objects of the initial list, one added afterwards only of the following changes.
+//		handler:	the handler of the changes
+func (i *Informer) AddHandler(handler InformerHandler) {
+	i.Lock()
+	defer i.Unlock()
+
+	i.handlers = append(i.handlers, handler)
+}
+
+// Start lists the state of Marathon, then keeps it current until the informer is closed
+func (i *Informer) Start() error {
+	i.Lock()
+	if i.started {
+		i.Unlock()
+		return ErrInformerStarted
+	}
+	i.started = true
+	i.Unlock()
+
+	// step: listen to the events before listing, so that no change is missed
+	events, err := i.client.AddEventsListenerWithOptions(informerEventsFilter, i.options.Events)
+	if err != nil {
+		i.reset()
+		return err
+	}
+	i.Lock()
+	i.events = events
+	i.Unlock()
+
+	if err := i.resync(); err != nil {
+		i.client.RemoveEventsListener(events)
+		i.reset()
+		return err
+	}
+	go i.run()
+
+	return nil
+}
+
+// reset allows the informer to be started again after it failed to start
+func (i *Informer) reset() {
+	i.Lock()
+	defer i.Unlock()
+	i.events = nil
+	i.started = false
+}
+
+// Close stops keeping the state current, the getters keep returning the last known state
+func (i *Informer) Close() {
+	i.stopOnce.Do(func() {
+		close(i.stop)
+		i.RLock()
+		events := i.events
+		i.RUnlock()
+		if events != nil {
+			i.client.RemoveEventsListener(events)
+		}
+	})
+}
+
+// Done returns a channel which is closed once the state is not kept current anymore, i.e. after the informer or
+// the client has been closed
+func (i *Informer) Done() <-chan struct{} {
+	return i.done
+}
+
+// Application returns the application of the given ID, if any
+func (i *Informer) Application(id string) (*Application, bool) {
+	obj, found := i.get(informerKey("app", id))
+	if !found {
+		return nil, false
+	}
+	return obj.(*Application), true
+}
+
+// Applications returns the applications, sorted by ID
+func (i *Informer) Applications() []*Application {
+	var applications []*Application
+	for _, obj := range i.list("app") {
+		applications = append(applications, obj.(*Application))
+	}
+	return applications
+}
+
+// Pod returns the pod of the given ID, if any
+func (i *Informer) Pod(id string) (*Pod, bool) {
+	obj, found := i.get(informerKey("pod", id))
+	if !found {
+		return nil, false
+	}
+	return obj.(*Pod), true
+}
+
+// Pods returns the pods, sorted by ID
+func (i *Informer) Pods() []*Pod {
+	var pods []*Pod
+	for _, obj := range i.list("pod") {
+		pods = append(pods, obj.(*Pod))
+	}
+	return pods
+}
+
+// Task returns the task of the given ID, if any
+func (i *Informer) Task(id string) (*Task, bool) {
+	obj, found := i.get(informerKey("task", id))
+	if !found {
+		return nil, false
+	}
+	return obj.(*Task), true
+}
+
+// Tasks returns the tasks, sorted by ID
+func (i *Informer) Tasks() []*Task {
+	var tasks []*Task
+	for _, obj := range i.list("task") {
+		tasks = append(tasks, obj.(*Task))
+	}
+	return tasks
+}
+
+// ApplicationTasks returns the tasks of the application of the given ID, sorted by ID
+func (i *Informer) ApplicationTasks(id string) []*Task {
+	var tasks []*Task
+	for _, obj := range i.ByIndex(IndexAppID, id) {
+		if task, ok := obj.(*Task); ok {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks
+}
+
+// Deployment returns the deployment of the given ID, if any
+func (i *Informer) Deployment(id string) (*Deployment, bool) {
+	obj, found := i.get(informerKey("deployment", id))
+	if !found {
+		return nil, false
+	}
+	return obj.(*Deployment), true
+}
+
+// Deployments returns the deployments, sorted by ID
+func (i *Informer) Deployments() []*Deployment {
+	var deployments []*Deployment
+	for _, obj := range i.list("deployment") {
+		deployments = append(deployments, obj.(*Deployment))
+	}
+	return deployments
+}
+
+// ByIndex returns the objects indexed by the value, sorted by kind and ID
+//		name:		the name of the index, e.g. IndexLabel
+//		value:		the value of the index, e.g. "tier=frontend"
+func (i *Informer) ByIndex(name, value string) []interface{} {
+	i.RLock()
+	defer i.RUnlock()
+
+	var keys []string
+	for key := range i.indices[name][value] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var objs []interface{}
+	for _, key := range keys {
+		objs = append(objs, i.objects[key])
+	}
+	return objs
+}
+
+// get returns the object of the key, if any
+func (i *Informer) get(key string) (interface{}, bool) {
+	i.RLock()
+	defer i.RUnlock()
+
+	obj, found := i.objects[key]
+	return obj, found
+}
+
+// list returns the objects of the kind, sorted by ID
+func (i *Informer) list(kind string) []interface{} {
+	i.RLock()
+	defer i.RUnlock()
+
+	var keys []string
+	for key := range i.objects {
+		if strings.HasPrefix(key, kind+":") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var objs []interface{}
+	for _, key := range keys {
+		objs = append(objs, i.objects[key])
+	}
+	return objs
+}
+
+// run keeps the state current until the informer or the client is closed
+func (i *Informer) run() {
+	defer close(i.done)
+
+	var resync <-chan time.Time
+	if i.options.ResyncPeriod > 0 {
+		ticker := time.NewTicker(i.options.ResyncPeriod)
+		defer ticker.Stop()
+		resync = ticker.C
+	}
+
+	for {
+		select {
+		case <-i.stop:
+			return
+		case event, ok := <-i.events:
+			if !ok {
+				return
+			}
+			i.handleEvent(event)
+		case <-resync:
+			if err := i.resync(); err != nil {
+				i.reportError(err)
+			}
+		}
+	}
+}
+
+// handleEvent applies the event to the state, refreshing the objects it is about
+func (i *Informer) handleEvent(event *Event) {
+	var err error
+	switch e := event.Event.(type) {
+	case *EventStreamReconnected:
+		// step: events may have been missed while the stream was down
+		err = i.resync()
+	case *EventGroupChangeSuccess:
+		// step: a group change may affect any application or pod of the group
+		err = i.resync()
+	case *EventStatusUpdate:
+		i.updateTask(e)
+	case *EventAppTerminated:
+		i.removeApplication(e.AppID)
+	case *EventPodCreated:
+		err = i.refreshPod(e.URI)
+	case *EventPodUpdated:
+		err = i.refreshPod(e.URI)
+	case *EventPodDeleted:
+		err = i.refreshPod(e.URI)
+	default:
+		if event.ID&cacheDeploymentEvents != 0 {
+			err = i.refreshDeployments()
+		}
+		for _, id := range eventAppIDs(event) {
+			if refreshErr := i.refreshRunSpec(id); refreshErr != nil {
+				err = refreshErr
+			}
+		}
+	}
+	if err != nil {
+		i.reportError(fmt.Errorf("failed to apply the event: %s, error: %s", event.Name, err))
+	}
+}
+
+// resync lists the state of Marathon and reconciles it with the known one
+func (i *Informer) resync() error {
+	applications, err := i.client.Applications(url.Values{"embed": informerAppsEmbed})
+	if err != nil {
+		return err
+	}
+	tasks, err := i.client.AllTasks(nil)
+	if err != nil {
+		return err
+	}
+	pods, err := i.client.GetAllPods()
+	if err != nil {
+		// step: Marathon before 1.4 has no pods
+		if apiErr, ok := err.(*APIError); !ok || apiErr.ErrCode != ErrCodeNotFound {
+			return err
+		}
+	}
+	deployments, err := i.client.Deployments()
+	if err != nil {
+		return err
+	}
+
+	objects := make(map[string]interface{})
+	for index := range applications.Apps {
+		application := &applications.Apps[index]
+		objects[informerKey("app", application.ID)] = application
+	}
+	for _, task := range tasks.Tasks {
+		task := task
+		objects[informerKey("task", task.ID)] = &task
+	}
+	for _, pod := range pods {
+		objects[informerKey("pod", pod.ID)] = pod
+	}
+	for _, deployment := range deployments {
+		objects[informerKey("deployment", deployment.ID)] = deployment
+	}
+
+	i.Lock()
+	var changes []informerChange
+	for key, obj := range i.objects {
+		if _, found := objects[key]; !found {
+			changes = append(changes, i.remove(key, obj))
+		}
+	}
+	for key, obj := range objects {
+		if change, changed := i.set(key, obj); changed {
+			changes = append(changes, change)
+		}
+	}
+	i.Unlock()
+
+	i.notify(changes)
+	return nil
+}
+
+// refreshRunSpec refreshes the pod or the application of the given ID
+func (i *Informer) refreshRunSpec(id string) error {
+	if _, found := i.Pod(id); found {
+		return i.refreshPod(buildPodURI(id))
+	}
+	return i.refreshApplication(id)
+}
+
+// refreshApplication retrieves the application of the given ID with its tasks, removing it if it is gone
+func (i *Informer) refreshApplication(id string) error {
+	application, err := i.client.ApplicationBy(id, &GetAppOpts{Embed: informerAppEmbed})
+	if err != nil {
+		if apiErr, ok := err.(*APIError); ok && apiErr.ErrCode == ErrCodeNotFound {
+			i.removeApplication(id)
+			return nil
+		}
+		return err
+	}
+	if application == nil {
+		return fmt.Errorf("no application in the response for: %s", id)
+	}
+
+	// step: the tasks are kept apart from the applications, as in the lists
+	tasks := application.Tasks
+	application.Tasks = nil
+
+	i.Lock()
+	var changes []informerChange
+	if change, changed := i.set(informerKey("app", application.ID), application); changed {
+		changes = append(changes, change)
+	}
+	if tasks != nil {
+		current := make(map[string]bool)
+		for _, task := range tasks {
+			key := informerKey("task", task.ID)
+			current[key] = true
+			if change, changed := i.set(key, task); changed {
+				changes = append(changes, change)
+			}
+		}
+		for key := range i.indices[IndexAppID][application.ID] {
+			if obj := i.objects[key]; !current[key] && strings.HasPrefix(key, "task:") {
+				changes = append(changes, i.remove(key, obj))
+			}
+		}
+	}
+	i.Unlock()
+
+	i.notify(changes)
+	return nil
+}
+
+// removeApplication removes the application of the given ID and its tasks
+func (i *Informer) removeApplication(id string) {
+	i.Lock()
+	var changes []informerChange
+	if obj, found := i.objects[informerKey("app", id)]; found {
+		changes = append(changes, i.remove(informerKey("app", id), obj))
+	}
+	for key := range i.indices[IndexAppID][id] {
+		if strings.HasPrefix(key, "task:") {
+			changes = append(changes, i.remove(key, i.objects[key]))
+		}
+	}
+	i.Unlock()
+
+	i.notify(changes)
+}
+
+// refreshPod retrieves the pod of the given URI, e.g. "/v2/pods/prod/web", removing it if it is gone
+func (i *Informer) refreshPod(uri string) error {
+	path := strings.TrimPrefix(strings.SplitN(uri, "?", 2)[0], "/")
+	if !strings.HasPrefix(path, marathonAPIPods+"/") {
+		return fmt.Errorf("unexpected pod uri: %s", uri)
+	}
+	id := "/" + strings.TrimPrefix(path, marathonAPIPods+"/")
+
+	var changes []informerChange
+	pod, err := i.client.GetPod(id)
+	if err != nil {
+		apiErr, ok := err.(*APIError)
+		if !ok || apiErr.ErrCode != ErrCodeNotFound {
+			return err
+		}
+		i.Lock()
+		if obj, found := i.objects[informerKey("pod", id)]; found {
+			changes = append(changes, i.remove(informerKey("pod", id), obj))
+		}
+		i.Unlock()
+	} else {
+		i.Lock()
+		if change, changed := i.set(informerKey("pod", pod.ID), pod); changed {
+			changes = append(changes, change)
+		}
+		i.Unlock()
+	}
+
+	i.notify(changes)
+	return nil
+}
+
+// refreshDeployments lists the deployments, replacing the known ones
+func (i *Informer) refreshDeployments() error {
+	deployments, err := i.client.Deployments()
+	if err != nil {
+		return err
+	}
+
+	i.Lock()
+	var changes []informerChange
+	current := make(map[string]bool)
+	for _, deployment := range deployments {
+		key := informerKey("deployment", deployment.ID)
+		current[key] = true
+		if change, changed := i.set(key, deployment); changed {
+			changes = append(changes, change)
+		}
+	}
+	for key, obj := range i.objects {
+		if strings.HasPrefix(key, "deployment:") && !current[key] {
+			changes = append(changes, i.remove(key, obj))
+		}
+	}
+	i.Unlock()
+
+	i.notify(changes)
+	return nil
+}
+
+// updateTask applies a 'status_update_event' event to the task it reports
+func (i *Informer) updateTask(event *EventStatusUpdate) {
+	key := informerKey("task", event.TaskID)
+
+	i.Lock()
+	var changes []informerChange
+	obj, found := i.objects[key]
+	if informerTerminalStates[event.TaskStatus] {
+		if found {
+			changes = append(changes, i.remove(key, obj))
+		}
+	} else {
+		// step: only the fields carried by the event are set, the others are filled by the next resync
+		task := &Task{ID: event.TaskID, AppID: event.AppID}
+		if found {
+			// step: copy the known task, which is shared with the handlers
+			known := *obj.(*Task)
+			task = &known
+		}
+		task.Host = event.Host
+		task.SlaveID = event.SlaveID
+		task.State = event.TaskStatus
+		task.IPAddresses = event.IPAddresses
+		if event.Ports != nil {
+			task.Ports = event.Ports
+		}
+		if event.Version != "" {
+			task.Version = event.Version
+		}
+		if change, changed := i.set(key, task); changed {
+			changes = append(changes, change)
+		}
+	}
+	i.Unlock()
+
+	i.notify(changes)
+}
+
+// set adds or replaces the object of the key, returning the change if the object differs from the known one. The
+// lock must be held.
+func (i *Informer) set(key string, obj interface{}) (informerChange, bool) {
+	known, found := i.objects[key]
+	if found && reflect.DeepEqual(known, obj) {
+		return informerChange{}, false
+	}
+	if found {
+		i.unindex(key, known)
+	}
+	i.objects[key] = obj
+	i.index(key, obj)
+
+	// step: the tasks known from the status events lack the fields the events don't carry, filling them is no change
+	if found && fillsTask(known, obj) {
+		return informerChange{}, false
+	}
+	return informerChange{oldObj: known, newObj: obj}, true
+}
+
+// fillsTask checks if the object is the known task with the fields the status events don't carry filled
+func fillsTask(known, obj interface{}) bool {
+	knownTask, ok := known.(*Task)
+	if !ok {
+		return false
+	}
+	task, ok := obj.(*Task)
+	if !ok {
+		return false
+	}
+
+	filled := *knownTask
+	if filled.StagedAt == "" {
+		filled.StagedAt = task.StagedAt
+	}
+	if filled.StartedAt == "" {
+		filled.StartedAt = task.StartedAt
+	}
+	if filled.HealthCheckResults == nil {
+		filled.HealthCheckResults = task.HealthCheckResults
+	}
+	if filled.ServicePorts == nil {
+		filled.ServicePorts = task.ServicePorts
+	}
+	if filled.Ports == nil {
+		filled.Ports = task.Ports
+	}
+	if filled.Version == "" {
+		filled.Version = task.Version
+	}
+	return reflect.DeepEqual(filled, *task)
+}
+
+// remove removes the object of the key, returning the change. The lock must be held.
+func (i *Informer) remove(key string, obj interface{}) informerChange {
+	i.unindex(key, obj)
+	delete(i.objects, key)
+
+	return informerChange{oldObj: obj}
+}
+
+// index adds the key of the object to the indices. The lock must be held.
+func (i *Informer) index(key string, obj interface{}) {
+	for name, indexer := range i.indexers {
+		for _, value := range indexer(obj) {
+			if i.indices[name] == nil {
+				i.indices[name] = make(map[string]map[string]bool)
+			}
+			if i.indices[name][value] == nil {
+				i.indices[name][value] = make(map[string]bool)
+			}
+			i.indices[name][value][key] = true
+		}
+	}
+}
+
+// unindex removes the key of the object from the indices. The lock must be held.
+func (i *Informer) unindex(key string, obj interface{}) {
+	for name, indexer := range i.indexers {
+		for _, value := range indexer(obj) {
+			delete(i.indices[name][value], key)
+			if len(i.indices[name][value]) == 0 {
+				delete(i.indices[name], value)
+			}
+		}
+	}
+}
+
+// notify hands the changes over to the handlers
+func (i *Informer) notify(changes []informerChange) {
+	if len(changes) == 0 {
+		return
+	}
+	i.RLock()
+	handlers := i.handlers
+	i.RUnlock()
+
+	for _, change := range changes {
+		for _, handler := range handlers {
+			switch {
+			case change.oldObj == nil:
+				handler.OnAdd(change.newObj)
+			case change.newObj == nil:
+				handler.OnDelete(change.oldObj)
+			default:
+				handler.OnUpdate(change.oldObj, change.newObj)
+			}
+		}
+	}
+}
+
+// reportError hands the error over to the error handler, if any
+func (i *Informer) reportError(err error) {
+	if i.options.ErrorHandler != nil {
+		i.options.ErrorHandler(err)
+	}
+}
+
+// informerKey returns the key of an object of the kind, e.g. "app:/prod/web"
+func informerKey(kind, id string) string {
+	return kind + ":" + id
+}
+
+// indexLabel returns the labels of the applications and the pods, as "key=value"
+func indexLabel(obj interface{}) []string {
+	var labels map[string]string
+	switch o := obj.(type) {
+	case *Application:
+		if o.Labels != nil {
+			labels = *o.Labels
+		}
+	case *Pod:
+		labels = o.Labels
+	}
+
+	var values []string
+	for key, value := range labels {
+		values = append(values, key+"="+value)
+	}
+	return values
+}
+
+// indexHost returns the host of the tasks
+func indexHost(obj interface{}) []string {
+	if task, ok := obj.(*Task); ok && task.Host != "" {
+		return []string{task.Host}
+	}
+	return nil
+}
+
+// indexAppID returns the IDs of the applications of the tasks and the deployments
+func indexAppID(obj interface{}) []string {
+	switch o := obj.(type) {
+	case *Task:
+		return []string{o.AppID}
+	case *Deployment:
+		return o.AffectedApps
+	}
+	return nil
+}
+
+// indexAppPrefix returns the groups of the IDs of the applications of the objects
+func indexAppPrefix(obj interface{}) []string {
+	var ids []string
+	switch o := obj.(type) {
+	case *Application:
+		ids = []string{o.ID}
+	case *Pod:
+		ids = []string{o.ID}
+	default:
+		ids = indexAppID(obj)
+	}
+
+	prefixes := make(map[string]bool)
+	for _, id := range ids {
+		for index := strings.Index(id, "/"); index >= 0; {
+			prefixes[id[:index+1]] = true
+			next := strings.Index(id[index+1:], "/")
+			if next < 0 {
+				break
+			}
+			index += next + 1
+		}
+	}
+
+	var values []string
+	for prefix := range prefixes {
+		values = append(values, prefix)
+	}
+	return values
+}
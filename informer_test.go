@@ -0,0 +1,352 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingHandler records the changes notified by an informer
+type recordingHandler struct {
+	sync.Mutex
+	added   []interface{}
+	updated []interface{}
+	deleted []interface{}
+}
+
+func (h *recordingHandler) OnAdd(obj interface{}) {
+	h.Lock()
+	defer h.Unlock()
+	h.added = append(h.added, obj)
+}
+
+func (h *recordingHandler) OnUpdate(oldObj, newObj interface{}) {
+	h.Lock()
+	defer h.Unlock()
+	h.updated = append(h.updated, newObj)
+}
+
+func (h *recordingHandler) OnDelete(obj interface{}) {
+	h.Lock()
+	defer h.Unlock()
+	h.deleted = append(h.deleted, obj)
+}
+
+func (h *recordingHandler) reset() {
+	h.Lock()
+	defer h.Unlock()
+	h.added, h.updated, h.deleted = nil, nil, nil
+}
+
+func newTestInformer(t *testing.T, endpoint *endpoint) (*Informer, *recordingHandler) {
+	handler := &recordingHandler{}
+	informer := NewInformer(endpoint.Client, InformerOptions{
+		ErrorHandler: func(err error) { assert.NoError(t, err) },
+	})
+	informer.AddHandler(handler)
+	require.NoError(t, informer.Start())
+	return informer, handler
+}
+
+func TestInformerSync(t *testing.T) {
+	endpoint := newSSEEndpoint(t)
+	defer endpoint.Close()
+	informer, handler := newTestInformer(t, endpoint)
+	defer informer.Close()
+
+	applications := informer.Applications()
+	if assert.Len(t, applications, 2) {
+		assert.Equal(t, "/fake-app", applications[0].ID)
+		assert.Equal(t, "/fake-app-broken", applications[1].ID)
+	}
+	application, found := informer.Application("/fake-app")
+	assert.True(t, found)
+	assert.Equal(t, applications[0], application)
+	assert.Len(t, informer.Tasks(), 2)
+	assert.Len(t, informer.ApplicationTasks("/bridged-webapp"), 2)
+	assert.Len(t, informer.Pods(), 1)
+	_, found = informer.Deployment("867ed450-f6a8-4d33-9b0e-e11c5513990b")
+	assert.True(t, found)
+	assert.Len(t, handler.added, 6)
+
+	// step: the default indices
+	if pods := informer.ByIndex(IndexLabel, "key=value"); assert.Len(t, pods, 1) {
+		assert.Equal(t, "/fake-pod", pods[0].(*Pod).ID)
+	}
+	assert.Len(t, informer.ByIndex(IndexHost, "10.141.141.10"), 2)
+	assert.Len(t, informer.ByIndex(IndexAppPrefix, "/"), 6)
+	if deployments := informer.ByIndex(IndexAppID, "/test"); assert.Len(t, deployments, 1) {
+		assert.IsType(t, &Deployment{}, deployments[0])
+	}
+
+	// step: an unchanged state is not notified again
+	handler.reset()
+	require.NoError(t, informer.resync())
+	assert.Empty(t, handler.added)
+	assert.Empty(t, handler.updated)
+	assert.Empty(t, handler.deleted)
+
+	assert.Equal(t, ErrInformerStarted, informer.AddIndexer("custom", indexHost))
+	assert.Equal(t, ErrInformerStarted, informer.Start())
+}
+
+// failingApplications fails the first listing of the applications
+type failingApplications struct {
+	Marathon
+	failed int32
+}
+
+func (f *failingApplications) Applications(query url.Values) (*Applications, error) {
+	if atomic.CompareAndSwapInt32(&f.failed, 0, 1) {
+		return nil, fmt.Errorf("listing failed")
+	}
+	return f.Marathon.Applications(query)
+}
+
+func TestInformerStartRetry(t *testing.T) {
+	endpoint := newSSEEndpoint(t)
+	defer endpoint.Close()
+	handler := &recordingHandler{}
+	informer := NewInformer(&failingApplications{Marathon: endpoint.Client}, InformerOptions{})
+	informer.AddHandler(handler)
+	defer informer.Close()
+
+	// step: a failed start can be retried
+	assert.Error(t, informer.Start())
+	assert.Empty(t, informer.Applications())
+	select {
+	case <-informer.Done():
+		t.Fatal("the informer was done after failing to start")
+	default:
+	}
+	require.NoError(t, informer.Start())
+	assert.Len(t, informer.Applications(), 2)
+	assert.Equal(t, ErrInformerStarted, informer.Start())
+}
+
+func TestInformerEvents(t *testing.T) {
+	endpoint := newSSEEndpoint(t)
+	defer endpoint.Close()
+	informer, handler := newTestInformer(t, endpoint)
+	defer informer.Close()
+	handler.reset()
+
+	// step: the task status updates
+	update := &EventStatusUpdate{
+		Timestamp:  "2014-03-01T23:29:30.158Z",
+		TaskID:     "fake-app.1",
+		TaskStatus: "TASK_STAGING",
+		AppID:      "/fake-app",
+		Host:       "10.141.141.11",
+	}
+	informer.handleEvent(&Event{ID: EventIDStatusUpdate, Event: update})
+	task, found := informer.Task("fake-app.1")
+	require.True(t, found)
+	assert.Equal(t, "TASK_STAGING", task.State)
+	assert.Empty(t, task.StagedAt)
+	assert.Len(t, handler.added, 1)
+
+	running := *update
+	running.TaskStatus = "TASK_RUNNING"
+	informer.handleEvent(&Event{ID: EventIDStatusUpdate, Event: &running})
+	task, _ = informer.Task("fake-app.1")
+	assert.Equal(t, "TASK_RUNNING", task.State)
+	assert.Empty(t, task.StartedAt)
+	assert.Len(t, handler.updated, 1)
+	assert.Len(t, informer.ByIndex(IndexHost, "10.141.141.11"), 1)
+
+	killed := *update
+	killed.TaskStatus = "TASK_KILLED"
+	informer.handleEvent(&Event{ID: EventIDStatusUpdate, Event: &killed})
+	_, found = informer.Task("fake-app.1")
+	assert.False(t, found)
+	assert.Len(t, handler.deleted, 1)
+	assert.Empty(t, informer.ByIndex(IndexHost, "10.141.141.11"))
+
+	// step: the applications are refreshed with their tasks
+	handler.reset()
+	informer.handleEvent(&Event{ID: EventIDChangedHealthCheck, Event: &EventHealthCheckChanged{AppID: "/fake-app"}})
+	application, found := informer.Application("/fake-app")
+	require.True(t, found)
+	assert.Nil(t, application.Tasks)
+	assert.Len(t, informer.ApplicationTasks("/toggle"), 2)
+	assert.Len(t, handler.added, 2)
+
+	// step: the terminated applications are removed with their tasks
+	handler.reset()
+	informer.handleEvent(&Event{ID: EventIDAppTerminated, Event: &EventAppTerminated{AppID: "/bridged-webapp"}})
+	assert.Empty(t, informer.ApplicationTasks("/bridged-webapp"))
+	assert.Len(t, handler.deleted, 2)
+
+	// step: the unknown objects are removed
+	informer.Lock()
+	informer.set(informerKey("app", "/gone"), &Application{ID: "/gone"})
+	informer.set(informerKey("pod", "/gone-pod"), &Pod{ID: "/gone-pod"})
+	informer.Unlock()
+	handler.reset()
+	informer.handleEvent(&Event{ID: EventIDAPIRequest, Event: &EventAPIRequest{AppDefinition: &Application{ID: "/gone"}}})
+	informer.handleEvent(&Event{ID: EventIDPodDeleted, Event: &EventPodDeleted{URI: "/v2/pods/gone-pod"}})
+	_, found = informer.Application("/gone")
+	assert.False(t, found)
+	_, found = informer.Pod("/gone-pod")
+	assert.False(t, found)
+	assert.Len(t, handler.deleted, 2)
+}
+
+func TestInformerPublishedEvents(t *testing.T) {
+	endpoint := newSSEEndpoint(t)
+	defer endpoint.Close()
+	informer, handler := newTestInformer(t, endpoint)
+	handler.reset()
+
+	time.Sleep(SSEConnectWaitTime)
+	endpoint.Server.PublishEvent(testCases.find("status_update_event").source)
+
+	deadline := time.Now().Add(eventPublishTimeout)
+	for {
+		if task, found := informer.Task("my-app_0-1396592784349"); found {
+			assert.Equal(t, "/my-app", task.AppID)
+			break
+		}
+		if time.Now().After(deadline) {
+			assert.Fail(t, "the task was not added in time")
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	informer.Close()
+	select {
+	case <-informer.Done():
+	case <-time.After(eventPublishTimeout):
+		assert.Fail(t, "the informer was not done in time")
+	}
+}
+
+func TestInformerRefreshUnchanged(t *testing.T) {
+	// step: Marathon embeds the last task failure of a single application by default, but not in the lists
+	const application = `{"id": "/web", "instances": 1, "tasksRunning": 1, "deployments": []`
+	const failure = `, "lastTaskFailure": {"appId": "/web", "taskId": "web.1", "state": "TASK_FAILED"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		embed := r.URL.Query()["embed"]
+		switch r.URL.Path {
+		case "/v2/apps":
+			if contains(embed, "apps.lastTaskFailure") {
+				fmt.Fprintf(w, `{"apps": [%s%s}]}`, application, failure)
+				return
+			}
+			fmt.Fprintf(w, `{"apps": [%s}]}`, application)
+		case "/v2/apps/web":
+			fmt.Fprintf(w, `{"app": %s%s, "tasks": []}}`, application, failure)
+		case "/v2/tasks":
+			w.Write([]byte(`{"tasks": []}`))
+		case "/v2/pods", "/v2/deployments":
+			w.Write([]byte(`[]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	config := NewDefaultConfig()
+	config.URL = server.URL
+	client, err := NewClient(config)
+	require.NoError(t, err)
+	defer client.Close()
+	informer := NewInformer(client, InformerOptions{})
+	handler := &recordingHandler{}
+	informer.AddHandler(handler)
+	require.NoError(t, informer.resync())
+	require.Len(t, handler.added, 1)
+
+	require.NoError(t, informer.refreshApplication("/web"))
+	assert.Empty(t, handler.updated)
+}
+
+func TestInformerResyncEventTask(t *testing.T) {
+	var listed int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/apps":
+			w.Write([]byte(`{"apps": []}`))
+		case "/v2/tasks":
+			if atomic.LoadInt32(&listed) == 0 {
+				w.Write([]byte(`{"tasks": []}`))
+				return
+			}
+			w.Write([]byte(`{"tasks": [{"id": "web.1", "appId": "/web", "host": "10.0.0.1", "slaveId": "agent-1",
+				"ports": [31000], "servicePorts": [10000], "state": "TASK_RUNNING", "version": "2017-01-01T00:00:00.000Z",
+				"stagedAt": "2017-01-01T00:00:01.000Z", "startedAt": "2017-01-01T00:00:02.000Z"}]}`))
+		case "/v2/pods", "/v2/deployments":
+			w.Write([]byte(`[]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	config := NewDefaultConfig()
+	config.URL = server.URL
+	client, err := NewClient(config)
+	require.NoError(t, err)
+	defer client.Close()
+	informer := NewInformer(client, InformerOptions{})
+	handler := &recordingHandler{}
+	informer.AddHandler(handler)
+	require.NoError(t, informer.resync())
+
+	// step: the task is known from its status update first
+	informer.handleEvent(&Event{ID: EventIDStatusUpdate, Event: &EventStatusUpdate{
+		Timestamp:  "2017-01-01T00:00:03.000Z",
+		TaskID:     "web.1",
+		AppID:      "/web",
+		Host:       "10.0.0.1",
+		SlaveID:    "agent-1",
+		Ports:      []int{31000},
+		TaskStatus: "TASK_RUNNING",
+		Version:    "2017-01-01T00:00:00.000Z",
+	}})
+	require.Len(t, handler.added, 1)
+
+	// step: the resyncs only fill the fields the event doesn't carry
+	atomic.StoreInt32(&listed, 1)
+	for i := 0; i < 2; i++ {
+		require.NoError(t, informer.resync())
+		assert.Empty(t, handler.updated)
+	}
+	task, found := informer.Task("web.1")
+	require.True(t, found)
+	assert.Equal(t, "2017-01-01T00:00:01.000Z", task.StagedAt)
+}
+
+func TestIndexAppPrefix(t *testing.T) {
+	prefixes := indexAppPrefix(&Task{AppID: "/prod/web/api"})
+	sort.Strings(prefixes)
+	assert.Equal(t, []string{"/", "/prod/", "/prod/web/"}, prefixes)
+	assert.Empty(t, indexAppPrefix(&Task{}))
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import "net/http"
+
+// RoundTripFunc sends a request to a Marathon member and returns its response
+type RoundTripFunc func(request *http.Request) (*http.Response, error)
+
+// Interceptor wraps the sending of the requests to Marathon, e.g. to add headers, trace or audit the requests or to
+// inject faults. It calls next to pass the request on, or returns a response or an error of its own.
+type Interceptor func(next RoundTripFunc) RoundTripFunc
+
+// HeaderInterceptor adds the given headers to every request
+//		header:		the headers to add
+func HeaderInterceptor(header http.Header) Interceptor {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(request *http.Request) (*http.Response, error) {
+			for name, values := range header {
+				for _, value := range values {
+					request.Header.Add(name, value)
+				}
+			}
+			return next(request)
+		}
+	}
+}
+
+// chainInterceptors wraps the round trip into the interceptors, the first interceptor seeing the request first
+//		roundTrip:		the function sending the requests
+//		interceptors:	the interceptors
+func chainInterceptors(roundTrip RoundTripFunc, interceptors []Interceptor) RoundTripFunc {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		roundTrip = interceptors[i](roundTrip)
+	}
+	return roundTrip
+}
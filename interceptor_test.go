@@ -0,0 +1,96 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingInterceptor appends its name to the calls before and after passing the request on
+func recordingInterceptor(name string, calls *[]string) Interceptor {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(request *http.Request) (*http.Response, error) {
+			*calls = append(*calls, name+" "+request.URL.Path)
+			response, err := next(request)
+			if err == nil {
+				*calls = append(*calls, name+" "+response.Status)
+			}
+			return response, err
+		}
+	}
+}
+
+func TestInterceptors(t *testing.T) {
+	var calls []string
+	var header http.Header
+
+	config := NewDefaultConfig()
+	config.Interceptors = []Interceptor{
+		recordingInterceptor("first", &calls),
+		HeaderInterceptor(http.Header{"X-Request-Source": []string{"test"}}),
+		recordingInterceptor("second", &calls),
+		func(next RoundTripFunc) RoundTripFunc {
+			return func(request *http.Request) (*http.Response, error) {
+				header = request.Header
+				return next(request)
+			}
+		},
+	}
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config})
+	defer endpoint.Close()
+
+	_, err := endpoint.Client.Ping()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"first /ping", "second /ping", "second 200 OK", "first 200 OK"}, calls)
+	assert.Equal(t, "test", header.Get("X-Request-Source"))
+	assert.Equal(t, "application/json", header.Get("Accept"))
+}
+
+func TestInterceptorsFaultInjection(t *testing.T) {
+	config := NewDefaultConfig()
+	config.Interceptors = []Interceptor{
+		func(next RoundTripFunc) RoundTripFunc {
+			return func(request *http.Request) (*http.Response, error) {
+				return &http.Response{
+					Status:     "404 Not Found",
+					StatusCode: http.StatusNotFound,
+					Header:     http.Header{},
+					Body:       ioutil.NopCloser(bytes.NewBufferString(`{"message": "injected"}`)),
+					Request:    request,
+				}, nil
+			}
+		},
+	}
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config})
+	defer endpoint.Close()
+
+	_, err := endpoint.Client.Applications(nil)
+	if assert.Error(t, err) {
+		apiErr, ok := err.(*APIError)
+		if assert.True(t, ok) {
+			assert.Equal(t, ErrCodeNotFound, apiErr.ErrCode)
+			assert.Contains(t, apiErr.Error(), "injected")
+		}
+	}
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// EventFilter decides whether an event is handed over to the handler of an EventListener
+type EventFilter func(event *Event) bool
+
+// EventListener is the registration of an event handler, events are handed over to the handler one at a time
+type EventListener struct {
+	client  Marathon
+	channel EventsChannel
+	done    chan struct{}
+}
+
+// Close stops the delivery of events to the handler
+func (l *EventListener) Close() {
+	l.client.RemoveEventsListener(l.channel)
+}
+
+// Done returns a channel which is closed once the handler will not be called anymore, i.e. after the listener
+// or the client has been closed
+func (l *EventListener) Done() <-chan struct{} {
+	return l.done
+}
+
+// AppIDPrefix matches the events concerning at least one application whose ID starts with the given prefix.
+// Events without any application, e.g. 'deployment_failed', never match.
+//		prefix:		the prefix of the application IDs, e.g. "/prod/"
+func AppIDPrefix(prefix string) EventFilter {
+	return func(event *Event) bool {
+		for _, id := range eventAppIDs(event) {
+			if strings.HasPrefix(id, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// TaskState matches the 'status_update_event' events reporting one of the given task states, e.g. "TASK_FAILED".
+// Other events never match.
+//		states:		the task states to match
+func TaskState(states ...string) EventFilter {
+	return func(event *Event) bool {
+		update, ok := event.Event.(*EventStatusUpdate)
+		if !ok {
+			return false
+		}
+		for _, state := range states {
+			if update.TaskStatus == state {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// OnEvent calls the handler for each event matching the listener ID filter and all the event filters
+//		filter:		the listener IDs of the events, e.g. EventIDApplications
+//		handler:	the function handling the events
+//		filters:	the additional filters the events have to match
+func (r *marathonClient) OnEvent(filter int, handler func(*Event), filters ...EventFilter) (*EventListener, error) {
+	channel, err := r.AddEventsListener(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	listener := &EventListener{
+		client:  r,
+		channel: channel,
+		done:    make(chan struct{}),
+	}
+	go func() {
+		defer close(listener.done)
+		for event := range channel {
+			if matchEvent(event, filters) {
+				handler(event)
+			}
+		}
+	}()
+
+	return listener, nil
+}
+
+// matchEvent checks if the event matches all the filters
+func matchEvent(event *Event, filters []EventFilter) bool {
+	for _, filter := range filters {
+		if !filter(event) {
+			return false
+		}
+	}
+	return true
+}
+
+// eventAppIDs returns the IDs of the applications the event is about
+func eventAppIDs(event *Event) []string {
+	switch e := event.Event.(type) {
+	case *EventAPIRequest:
+		if e.AppDefinition != nil {
+			return []string{e.AppDefinition.ID}
+		}
+	case *EventStatusUpdate:
+		return []string{e.AppID}
+	case *EventAppTerminated:
+		return []string{e.AppID}
+	case *EventAddHealthCheck:
+		return []string{e.AppID}
+	case *EventRemoveHealthCheck:
+		return []string{e.AppID}
+	case *EventFailedHealthCheck:
+		return []string{e.AppID}
+	case *EventHealthCheckChanged:
+		return []string{e.AppID}
+	case *EventInstanceChanged:
+		return []string{e.RunSpecID}
+	case *EventInstanceHealthChanged:
+		return []string{e.RunSpecID}
+	case *EventUnknownInstanceTerminated:
+		return []string{e.RunSpecID}
+	case *EventUnhealthyInstanceKill:
+		return []string{e.AppID}
+	case *EventDeploymentSuccess:
+		return deploymentAppIDs(nil, e.Plan)
+	case *EventDeploymentInfo:
+		return deploymentAppIDs(e.CurrentStep, e.Plan)
+	case *EventDeploymentStepSuccess:
+		return deploymentAppIDs(e.CurrentStep, e.Plan)
+	case *EventDeploymentStepFailure:
+		return deploymentAppIDs(e.CurrentStep, e.Plan)
+	}
+	return nil
+}
+
+// deploymentAppIDs returns the IDs of the applications affected by the actions of a deployment
+func deploymentAppIDs(current *StepActions, plan *DeploymentPlan) []string {
+	steps := []*StepActions{current}
+	if plan != nil {
+		steps = append(steps, plan.Steps...)
+	}
+
+	var ids []string
+	for _, step := range steps {
+		if step == nil {
+			continue
+		}
+		for _, action := range step.Actions {
+			ids = append(ids, action.App)
+		}
+	}
+	return ids
+}
+
+var (
+	eventIDsByType     map[reflect.Type]int
+	eventIDsByTypeOnce sync.Once
+)
+
+// eventIDOf returns the listener ID of the events decoded into the given type, e.g. *EventStatusUpdate
+func eventIDOf(eventType reflect.Type) (int, error) {
+	eventIDsByTypeOnce.Do(func() {
+		eventIDsByType = make(map[reflect.Type]int)
+		for name := range eventTypesMap {
+			if event, err := GetEvent(name); err == nil && event.Event != nil {
+				eventIDsByType[reflect.TypeOf(event.Event)] |= event.ID
+			}
+		}
+		// step: the client synthesizes this event, it is never decoded
+		eventIDsByType[reflect.TypeOf(&EventStreamReconnected{})] = EventIDStreamReconnected
+	})
+
+	id, found := eventIDsByType[eventType]
+	if !found {
+		return 0, fmt.Errorf("the type: %s is not a Marathon event", eventType)
+	}
+	return id, nil
+}
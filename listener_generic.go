@@ -0,0 +1,45 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import "reflect"
+
+// Subscribe calls the handler for each event of type T matching the filters, T being the pointer to an
+// event struct, e.g.
+//
+//	marathon.Subscribe(client, func(event *marathon.EventStatusUpdate) {
+//		...
+//	}, marathon.AppIDPrefix("/prod/"))
+//
+//		client:		the client to receive the events from
+//		handler:	the function handling the events
+//		filters:	the additional filters the events have to match
+func Subscribe[T any](client Marathon, handler func(T), filters ...EventFilter) (*EventListener, error) {
+	id, err := eventIDOf(reflect.TypeOf((*T)(nil)).Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	return client.OnEvent(id, func(event *Event) {
+		if typed, ok := event.Event.(T); ok {
+			handler(typed)
+		}
+	}, filters...)
+}
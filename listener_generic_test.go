@@ -0,0 +1,111 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscribeTyped(t *testing.T) {
+	endpoint := newSSEEndpoint(t)
+	defer endpoint.Close()
+
+	changes := make(chan *EventHealthCheckChanged, 10)
+	listener, err := Subscribe(endpoint.Client, func(event *EventHealthCheckChanged) {
+		changes <- event
+	}, AppIDPrefix("/my-app"))
+	require.NoError(t, err)
+	defer listener.Close()
+
+	time.Sleep(SSEConnectWaitTime)
+	for _, testCase := range testCases {
+		endpoint.Server.PublishEvent(testCase.source)
+	}
+
+	select {
+	case event := <-changes:
+		assert.Equal(t, testCases.find("health_status_changed_event").expectation, event)
+	case <-time.After(eventPublishTimeout):
+		assert.Fail(t, "did not receive event in time")
+	}
+
+	_, err = Subscribe(endpoint.Client, func(*Application) {})
+	assert.Error(t, err)
+}
+
+func TestSubscribeStatusUpdate(t *testing.T) {
+	endpoint := newSSEEndpoint(t)
+	defer endpoint.Close()
+
+	updates := make(chan *EventStatusUpdate, 10)
+	listener, err := Subscribe(endpoint.Client, func(event *EventStatusUpdate) {
+		updates <- event
+	}, AppIDPrefix("/my-"), TaskState("TASK_RUNNING"))
+	require.NoError(t, err)
+
+	time.Sleep(SSEConnectWaitTime)
+	for _, testCase := range testCases {
+		endpoint.Server.PublishEvent(testCase.source)
+	}
+
+	select {
+	case event := <-updates:
+		assert.Equal(t, testCases.find("status_update_event").expectation, event)
+	case <-time.After(eventPublishTimeout):
+		assert.Fail(t, "did not receive event in time")
+	}
+
+	listener.Close()
+	select {
+	case <-listener.Done():
+	case <-time.After(eventPublishTimeout):
+		assert.Fail(t, "the listener was not done in time")
+	}
+	assert.Len(t, updates, 0)
+}
+
+func TestSubscribeMismatchedEvent(t *testing.T) {
+	endpoint := newSSEEndpoint(t)
+	defer endpoint.Close()
+
+	updates := make(chan *EventStatusUpdate, 10)
+	listener, err := Subscribe(endpoint.Client, func(event *EventStatusUpdate) {
+		updates <- event
+	})
+	require.NoError(t, err)
+	defer listener.Close()
+
+	// step: an event whose type doesn't match its ID is skipped rather than panicking
+	client := endpoint.Client.(*marathonClient)
+	client.dispatchEvent(&Event{ID: EventIDStatusUpdate, Name: "status_update_event", Event: &EventAppTerminated{}})
+	update := &EventStatusUpdate{AppID: "/my-app"}
+	client.dispatchEvent(&Event{ID: EventIDStatusUpdate, Name: "status_update_event", Event: update})
+
+	select {
+	case event := <-updates:
+		assert.Equal(t, update, event)
+	case <-time.After(eventPublishTimeout):
+		assert.Fail(t, "did not receive event in time")
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSSEEndpoint(t *testing.T) *endpoint {
+	config := NewDefaultConfig()
+	config.EventsTransport = EventsTransportSSE
+	return newFakeMarathonEndpoint(t, &configContainer{client: &config})
+}
+
+func TestOnEventFiltered(t *testing.T) {
+	endpoint := newSSEEndpoint(t)
+	defer endpoint.Close()
+
+	events := make(chan *Event, 10)
+	listener, err := endpoint.Client.OnEvent(EventIDApplications, func(event *Event) {
+		events <- event
+	}, AppIDPrefix("/other-app"))
+	require.NoError(t, err)
+	defer listener.Close()
+
+	time.Sleep(SSEConnectWaitTime)
+	endpoint.Server.PublishEvent(testCases.find("status_update_event").source)
+
+	select {
+	case event := <-events:
+		assert.Fail(t, "received an event not matching the filter", "%s", event)
+	case <-time.After(eventPublishTimeout):
+	}
+}
+
+func TestEventFilters(t *testing.T) {
+	statusUpdate := &Event{ID: EventIDStatusUpdate, Event: &EventStatusUpdate{AppID: "/prod/web", TaskStatus: "TASK_FAILED"}}
+	deploymentInfo := &Event{ID: EventIDDeploymentInfo, Event: &EventDeploymentInfo{
+		Plan: &DeploymentPlan{Steps: []*StepActions{
+			{Actions: []struct {
+				Action string `json:"action"`
+				Type   string `json:"type"`
+				App    string `json:"app"`
+			}{{Action: "ScaleApplication", App: "/prod/db"}}},
+		}},
+	}}
+	deploymentFailed := &Event{ID: EventIDDeploymentFailed, Event: &EventDeploymentFailed{ID: "1"}}
+
+	prod := AppIDPrefix("/prod/")
+	assert.True(t, prod(statusUpdate))
+	assert.True(t, prod(deploymentInfo))
+	assert.False(t, prod(deploymentFailed))
+	assert.False(t, AppIDPrefix("/dev/")(statusUpdate))
+
+	failed := TaskState("TASK_FAILED", "TASK_KILLED")
+	assert.True(t, failed(statusUpdate))
+	assert.False(t, failed(deploymentInfo))
+	assert.False(t, TaskState("TASK_RUNNING")(statusUpdate))
+
+	assert.True(t, matchEvent(statusUpdate, nil))
+	assert.True(t, matchEvent(statusUpdate, []EventFilter{prod, failed}))
+	assert.False(t, matchEvent(deploymentInfo, []EventFilter{prod, failed}))
+}
+
+func TestEventIDOf(t *testing.T) {
+	id, err := eventIDOf(reflect.TypeOf(&EventDeploymentSuccess{}))
+	require.NoError(t, err)
+	assert.Equal(t, EventIDDeploymentSuccess, id)
+
+	id, err = eventIDOf(reflect.TypeOf(&EventStreamReconnected{}))
+	require.NoError(t, err)
+	assert.Equal(t, EventIDStreamReconnected, id)
+
+	id, err = eventIDOf(reflect.TypeOf(&EventInstanceChanged{}))
+	require.NoError(t, err)
+	assert.Equal(t, EventIDInstanceChanged, id)
+
+	id, err = eventIDOf(reflect.TypeOf(&EventPodCreated{}))
+	require.NoError(t, err)
+	assert.Equal(t, EventIDPodCreated, id)
+
+	_, err = eventIDOf(reflect.TypeOf(&Application{}))
+	assert.Error(t, err)
+}
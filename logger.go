@@ -0,0 +1,236 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// redacted replaces the sensitive values in the logs
+const redacted = "<redacted>"
+
+// Logger receives the diagnostics of the client as messages with alternating keys and values, e.g.
+// "method", "GET", "status", 200. A *slog.Logger satisfies it.
+type Logger interface {
+	// Debug logs the details of the requests and responses
+	Debug(msg string, args ...interface{})
+	// Info logs the noteworthy operations, e.g. retries with fresh credentials or waits on deployments
+	Info(msg string, args ...interface{})
+	// Warn logs the failures the client recovers from, e.g. members failing or event streams breaking
+	Warn(msg string, args ...interface{})
+	// Error logs the failures the client does not recover from
+	Error(msg string, args ...interface{})
+}
+
+// newLogger creates the logger of the configuration: the configured one or else one writing to LogOutput
+func newLogger(config Config) Logger {
+	if config.Logger != nil {
+		return config.Logger
+	}
+	if config.LogOutput == nil || config.LogOutput == ioutil.Discard {
+		return nopLogger{}
+	}
+	return &writerLogger{logger: log.New(config.LogOutput, "", 0)}
+}
+
+// nopLogger discards all the messages
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, args ...interface{}) {}
+
+func (nopLogger) Info(msg string, args ...interface{}) {}
+
+func (nopLogger) Warn(msg string, args ...interface{}) {}
+
+func (nopLogger) Error(msg string, args ...interface{}) {}
+
+// writerLogger writes the messages as lines of text, e.g. `DEBUG apiCall(): method=GET status=200`
+type writerLogger struct {
+	logger *log.Logger
+}
+
+func (l *writerLogger) Debug(msg string, args ...interface{}) {
+	l.log("DEBUG", msg, args)
+}
+
+func (l *writerLogger) Info(msg string, args ...interface{}) {
+	l.log("INFO", msg, args)
+}
+
+func (l *writerLogger) Warn(msg string, args ...interface{}) {
+	l.log("WARN", msg, args)
+}
+
+func (l *writerLogger) Error(msg string, args ...interface{}) {
+	l.log("ERROR", msg, args)
+}
+
+func (l *writerLogger) log(level, msg string, args []interface{}) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s %s", level, msg)
+	for i := 0; i < len(args); i += 2 {
+		if i+1 < len(args) {
+			fmt.Fprintf(&b, " %v=%v", args[i], args[i+1])
+		} else {
+			fmt.Fprintf(&b, " %v", args[i])
+		}
+	}
+	l.logger.Print(b.String())
+}
+
+// logPayload is a JSON payload to log, whose environment variables and secrets are redacted. The redaction
+// happens only when the payload is actually logged.
+type logPayload []byte
+
+func (p logPayload) String() string {
+	return string(oneLogLine(redactPayload(p)))
+}
+
+// MarshalJSON logs the payload as a string, e.g. with the slog.JSONHandler
+func (p logPayload) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.String())
+}
+
+// redactPayload redacts the values of the environment variables and the sources of the secrets of a JSON
+// payload, leaving any other payload untouched
+func redactPayload(payload []byte) []byte {
+	if len(payload) == 0 || !bytes.ContainsAny(payload, "{[") {
+		return payload
+	}
+	var value interface{}
+	if err := json.Unmarshal(payload, &value); err != nil {
+		return payload
+	}
+	if !redactValue(value) {
+		return payload
+	}
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return payload
+	}
+	return bytes.TrimSpace(b.Bytes())
+}
+
+// redactValue redacts the environment variables and secrets found in the decoded JSON value, reporting
+// whether anything has been redacted
+func redactValue(value interface{}) bool {
+	found := false
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			found = redactValue(item) || found
+		}
+	case map[string]interface{}:
+		for key, item := range v {
+			switch key {
+			case "env", "environment":
+				found = redactEnvironment(item) || found
+			case "secrets":
+				found = redactSecrets(item) || found
+			default:
+				found = redactValue(item) || found
+			}
+		}
+	}
+	return found
+}
+
+// redactEnvironment redacts the values of the environment variables, keeping the references to secrets
+func redactEnvironment(value interface{}) bool {
+	variables, ok := value.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	for name, variable := range variables {
+		if reference, ok := variable.(map[string]interface{}); ok && len(reference) == 1 && reference["secret"] != nil {
+			continue
+		}
+		variables[name] = redacted
+	}
+	return len(variables) > 0
+}
+
+// redactSecrets redacts the sources of the secrets
+func redactSecrets(value interface{}) bool {
+	secrets, ok := value.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	for name, secret := range secrets {
+		if source, ok := secret.(map[string]interface{}); ok {
+			for key := range source {
+				source[key] = redacted
+			}
+			continue
+		}
+		secrets[name] = redacted
+	}
+	return len(secrets) > 0
+}
+
+// logHeader is a header to log, whose credentials are redacted
+type logHeader http.Header
+
+func (h logHeader) String() string {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var values []string
+	for _, name := range names {
+		value := strings.Join(h[name], ",")
+		switch http.CanonicalHeaderKey(name) {
+		case "Authorization", "Proxy-Authorization", "Cookie":
+			value = redacted
+		}
+		values = append(values, fmt.Sprintf("%s:%s", name, value))
+	}
+	return "[" + strings.Join(values, " ") + "]"
+}
+
+// MarshalJSON logs the header as a string, e.g. with the slog.JSONHandler
+func (h logHeader) MarshalJSON() ([]byte, error) {
+	return json.Marshal(h.String())
+}
+
+// logURL returns the URL to log, whose password is redacted
+func logURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	if u.User == nil {
+		return u.String()
+	}
+	if _, found := u.User.Password(); !found {
+		return u.String()
+	}
+	copied := *u
+	copied.User = url.UserPassword(u.User.Username(), "xxxxx")
+	return copied.String()
+}
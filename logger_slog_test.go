@@ -0,0 +1,48 @@
+//go:build go1.21
+// +build go1.21
+
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlogLogger(t *testing.T) {
+	var output bytes.Buffer
+	config := NewDefaultConfig()
+	config.Logger = slog.New(slog.NewJSONHandler(&output, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config})
+	defer endpoint.Close()
+
+	_, err := endpoint.Client.CreateApplication(NewDockerApplication().Name(fakeAppName).AddEnv("KEY", "value"))
+	require.NoError(t, err)
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(output.Bytes(), &entry))
+	assert.Equal(t, "DEBUG", entry["level"])
+	assert.Equal(t, "POST", entry["method"])
+	assert.Equal(t, float64(200), entry["status"])
+	assert.Contains(t, entry["request"], `"env":{"KEY":"<redacted>"}`)
+}
@@ -0,0 +1,648 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by gen.go from the Marathon interface; DO NOT EDIT.
+
+package marathonmock
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	marathon "github.com/gambol99/go-marathon"
+)
+
+// ListApplications records the call and returns the values of its expectation
+func (c *Client) ListApplications(arg0 url.Values) ([]string, error) {
+	ret := c.Called(arg0)
+	var r0 []string
+	if v := ret.Get(0); v != nil {
+		r0 = v.([]string)
+	}
+	return r0, ret.Error(1)
+}
+
+// ApplicationVersions records the call and returns the values of its expectation
+func (c *Client) ApplicationVersions(name string) (*marathon.ApplicationVersions, error) {
+	ret := c.Called(name)
+	var r0 *marathon.ApplicationVersions
+	if v := ret.Get(0); v != nil {
+		r0 = v.(*marathon.ApplicationVersions)
+	}
+	return r0, ret.Error(1)
+}
+
+// HasApplicationVersion records the call and returns the values of its expectation
+func (c *Client) HasApplicationVersion(name string, version string) (bool, error) {
+	ret := c.Called(name, version)
+	return ret.Bool(0), ret.Error(1)
+}
+
+// SetApplicationVersion records the call and returns the values of its expectation
+func (c *Client) SetApplicationVersion(name string, version *marathon.ApplicationVersion) (*marathon.DeploymentID, error) {
+	ret := c.Called(name, version)
+	var r0 *marathon.DeploymentID
+	if v := ret.Get(0); v != nil {
+		r0 = v.(*marathon.DeploymentID)
+	}
+	return r0, ret.Error(1)
+}
+
+// ApplicationOK records the call and returns the values of its expectation
+func (c *Client) ApplicationOK(name string) (bool, error) {
+	ret := c.Called(name)
+	return ret.Bool(0), ret.Error(1)
+}
+
+// CreateApplication records the call and returns the values of its expectation
+func (c *Client) CreateApplication(application *marathon.Application) (*marathon.Application, error) {
+	ret := c.Called(application)
+	var r0 *marathon.Application
+	if v := ret.Get(0); v != nil {
+		r0 = v.(*marathon.Application)
+	}
+	return r0, ret.Error(1)
+}
+
+// DeleteApplication records the call and returns the values of its expectation
+func (c *Client) DeleteApplication(name string, force bool) (*marathon.DeploymentID, error) {
+	ret := c.Called(name, force)
+	var r0 *marathon.DeploymentID
+	if v := ret.Get(0); v != nil {
+		r0 = v.(*marathon.DeploymentID)
+	}
+	return r0, ret.Error(1)
+}
+
+// UpdateApplication records the call and returns the values of its expectation
+func (c *Client) UpdateApplication(application *marathon.Application, force bool) (*marathon.DeploymentID, error) {
+	ret := c.Called(application, force)
+	var r0 *marathon.DeploymentID
+	if v := ret.Get(0); v != nil {
+		r0 = v.(*marathon.DeploymentID)
+	}
+	return r0, ret.Error(1)
+}
+
+// ApplicationDeployments records the call and returns the values of its expectation
+func (c *Client) ApplicationDeployments(name string) ([]*marathon.DeploymentID, error) {
+	ret := c.Called(name)
+	var r0 []*marathon.DeploymentID
+	if v := ret.Get(0); v != nil {
+		r0 = v.([]*marathon.DeploymentID)
+	}
+	return r0, ret.Error(1)
+}
+
+// ScaleApplicationInstances records the call and returns the values of its expectation
+func (c *Client) ScaleApplicationInstances(name string, instances int, force bool) (*marathon.DeploymentID, error) {
+	ret := c.Called(name, instances, force)
+	var r0 *marathon.DeploymentID
+	if v := ret.Get(0); v != nil {
+		r0 = v.(*marathon.DeploymentID)
+	}
+	return r0, ret.Error(1)
+}
+
+// RestartApplication records the call and returns the values of its expectation
+func (c *Client) RestartApplication(name string, force bool) (*marathon.DeploymentID, error) {
+	ret := c.Called(name, force)
+	var r0 *marathon.DeploymentID
+	if v := ret.Get(0); v != nil {
+		r0 = v.(*marathon.DeploymentID)
+	}
+	return r0, ret.Error(1)
+}
+
+// Applications records the call and returns the values of its expectation
+func (c *Client) Applications(arg0 url.Values) (*marathon.Applications, error) {
+	ret := c.Called(arg0)
+	var r0 *marathon.Applications
+	if v := ret.Get(0); v != nil {
+		r0 = v.(*marathon.Applications)
+	}
+	return r0, ret.Error(1)
+}
+
+// EachApplication records the call and returns the values of its expectation
+func (c *Client) EachApplication(v url.Values, fn func(*marathon.Application) error) error {
+	ret := c.Called(v, fn)
+	return ret.Error(0)
+}
+
+// Application records the call and returns the values of its expectation
+func (c *Client) Application(name string) (*marathon.Application, error) {
+	ret := c.Called(name)
+	var r0 *marathon.Application
+	if v := ret.Get(0); v != nil {
+		r0 = v.(*marathon.Application)
+	}
+	return r0, ret.Error(1)
+}
+
+// ApplicationBy records the call and returns the values of its expectation
+func (c *Client) ApplicationBy(name string, opts *marathon.GetAppOpts) (*marathon.Application, error) {
+	ret := c.Called(name, opts)
+	var r0 *marathon.Application
+	if v := ret.Get(0); v != nil {
+		r0 = v.(*marathon.Application)
+	}
+	return r0, ret.Error(1)
+}
+
+// ApplicationByVersion records the call and returns the values of its expectation
+func (c *Client) ApplicationByVersion(name string, version string) (*marathon.Application, error) {
+	ret := c.Called(name, version)
+	var r0 *marathon.Application
+	if v := ret.Get(0); v != nil {
+		r0 = v.(*marathon.Application)
+	}
+	return r0, ret.Error(1)
+}
+
+// WaitOnApplication records the call and returns the values of its expectation
+func (c *Client) WaitOnApplication(name string, timeout time.Duration) error {
+	ret := c.Called(name, timeout)
+	return ret.Error(0)
+}
+
+// Apply records the call and returns the values of its expectation
+func (c *Client) Apply(ctx context.Context, desired []*marathon.Application, opts *marathon.ApplyOptions) (*marathon.ApplyPlan, error) {
+	ret := c.Called(ctx, desired, opts)
+	var r0 *marathon.ApplyPlan
+	if v := ret.Get(0); v != nil {
+		r0 = v.(*marathon.ApplyPlan)
+	}
+	return r0, ret.Error(1)
+}
+
+// SupportsPods records the call and returns the values of its expectation
+func (c *Client) SupportsPods() bool {
+	ret := c.Called()
+	return ret.Bool(0)
+}
+
+// GetPodStatus records the call and returns the values of its expectation
+func (c *Client) GetPodStatus(name string) (*marathon.PodStatus, error) {
+	ret := c.Called(name)
+	var r0 *marathon.PodStatus
+	if v := ret.Get(0); v != nil {
+		r0 = v.(*marathon.PodStatus)
+	}
+	return r0, ret.Error(1)
+}
+
+// GetAllPodStatus records the call and returns the values of its expectation
+func (c *Client) GetAllPodStatus() ([]*marathon.PodStatus, error) {
+	ret := c.Called()
+	var r0 []*marathon.PodStatus
+	if v := ret.Get(0); v != nil {
+		r0 = v.([]*marathon.PodStatus)
+	}
+	return r0, ret.Error(1)
+}
+
+// GetPod records the call and returns the values of its expectation
+func (c *Client) GetPod(name string) (*marathon.Pod, error) {
+	ret := c.Called(name)
+	var r0 *marathon.Pod
+	if v := ret.Get(0); v != nil {
+		r0 = v.(*marathon.Pod)
+	}
+	return r0, ret.Error(1)
+}
+
+// GetAllPods records the call and returns the values of its expectation
+func (c *Client) GetAllPods() ([]*marathon.Pod, error) {
+	ret := c.Called()
+	var r0 []*marathon.Pod
+	if v := ret.Get(0); v != nil {
+		r0 = v.([]*marathon.Pod)
+	}
+	return r0, ret.Error(1)
+}
+
+// CreatePod records the call and returns the values of its expectation
+func (c *Client) CreatePod(pod *marathon.Pod) (*marathon.Pod, error) {
+	ret := c.Called(pod)
+	var r0 *marathon.Pod
+	if v := ret.Get(0); v != nil {
+		r0 = v.(*marathon.Pod)
+	}
+	return r0, ret.Error(1)
+}
+
+// UpdatePod records the call and returns the values of its expectation
+func (c *Client) UpdatePod(pod *marathon.Pod, force bool) (*marathon.Pod, error) {
+	ret := c.Called(pod, force)
+	var r0 *marathon.Pod
+	if v := ret.Get(0); v != nil {
+		r0 = v.(*marathon.Pod)
+	}
+	return r0, ret.Error(1)
+}
+
+// DeletePod records the call and returns the values of its expectation
+func (c *Client) DeletePod(name string, force bool) (*marathon.DeploymentID, error) {
+	ret := c.Called(name, force)
+	var r0 *marathon.DeploymentID
+	if v := ret.Get(0); v != nil {
+		r0 = v.(*marathon.DeploymentID)
+	}
+	return r0, ret.Error(1)
+}
+
+// WaitOnPod records the call and returns the values of its expectation
+func (c *Client) WaitOnPod(name string, timeout time.Duration) error {
+	ret := c.Called(name, timeout)
+	return ret.Error(0)
+}
+
+// PodExistsAndRunning records the call and returns the values of its expectation
+func (c *Client) PodExistsAndRunning(name string) bool {
+	ret := c.Called(name)
+	return ret.Bool(0)
+}
+
+// GetVersions records the call and returns the values of its expectation
+func (c *Client) GetVersions(name string) ([]string, error) {
+	ret := c.Called(name)
+	var r0 []string
+	if v := ret.Get(0); v != nil {
+		r0 = v.([]string)
+	}
+	return r0, ret.Error(1)
+}
+
+// GetPodByVersion records the call and returns the values of its expectation
+func (c *Client) GetPodByVersion(name string, version string) (*marathon.Pod, error) {
+	ret := c.Called(name, version)
+	var r0 *marathon.Pod
+	if v := ret.Get(0); v != nil {
+		r0 = v.(*marathon.Pod)
+	}
+	return r0, ret.Error(1)
+}
+
+// DeletePodInstances records the call and returns the values of its expectation
+func (c *Client) DeletePodInstances(name string, instances []string) ([]*marathon.PodInstance, error) {
+	ret := c.Called(name, instances)
+	var r0 []*marathon.PodInstance
+	if v := ret.Get(0); v != nil {
+		r0 = v.([]*marathon.PodInstance)
+	}
+	return r0, ret.Error(1)
+}
+
+// DeletePodInstance records the call and returns the values of its expectation
+func (c *Client) DeletePodInstance(name string, instance string) (*marathon.PodInstance, error) {
+	ret := c.Called(name, instance)
+	var r0 *marathon.PodInstance
+	if v := ret.Get(0); v != nil {
+		r0 = v.(*marathon.PodInstance)
+	}
+	return r0, ret.Error(1)
+}
+
+// Tasks records the call and returns the values of its expectation
+func (c *Client) Tasks(application string) (*marathon.Tasks, error) {
+	ret := c.Called(application)
+	var r0 *marathon.Tasks
+	if v := ret.Get(0); v != nil {
+		r0 = v.(*marathon.Tasks)
+	}
+	return r0, ret.Error(1)
+}
+
+// AllTasks records the call and returns the values of its expectation
+func (c *Client) AllTasks(opts *marathon.AllTasksOpts) (*marathon.Tasks, error) {
+	ret := c.Called(opts)
+	var r0 *marathon.Tasks
+	if v := ret.Get(0); v != nil {
+		r0 = v.(*marathon.Tasks)
+	}
+	return r0, ret.Error(1)
+}
+
+// TaskEndpoints records the call and returns the values of its expectation
+func (c *Client) TaskEndpoints(name string, port int, healthCheck bool) ([]string, error) {
+	ret := c.Called(name, port, healthCheck)
+	var r0 []string
+	if v := ret.Get(0); v != nil {
+		r0 = v.([]string)
+	}
+	return r0, ret.Error(1)
+}
+
+// KillApplicationTasks records the call and returns the values of its expectation
+func (c *Client) KillApplicationTasks(applicationID string, opts *marathon.KillApplicationTasksOpts) (*marathon.Tasks, error) {
+	ret := c.Called(applicationID, opts)
+	var r0 *marathon.Tasks
+	if v := ret.Get(0); v != nil {
+		r0 = v.(*marathon.Tasks)
+	}
+	return r0, ret.Error(1)
+}
+
+// KillTask records the call and returns the values of its expectation
+func (c *Client) KillTask(taskID string, opts *marathon.KillTaskOpts) (*marathon.Task, error) {
+	ret := c.Called(taskID, opts)
+	var r0 *marathon.Task
+	if v := ret.Get(0); v != nil {
+		r0 = v.(*marathon.Task)
+	}
+	return r0, ret.Error(1)
+}
+
+// KillTasks records the call and returns the values of its expectation
+func (c *Client) KillTasks(taskIDs []string, opts *marathon.KillTaskOpts) error {
+	ret := c.Called(taskIDs, opts)
+	return ret.Error(0)
+}
+
+// Groups records the call and returns the values of its expectation
+func (c *Client) Groups() (*marathon.Groups, error) {
+	ret := c.Called()
+	var r0 *marathon.Groups
+	if v := ret.Get(0); v != nil {
+		r0 = v.(*marathon.Groups)
+	}
+	return r0, ret.Error(1)
+}
+
+// Group records the call and returns the values of its expectation
+func (c *Client) Group(name string) (*marathon.Group, error) {
+	ret := c.Called(name)
+	var r0 *marathon.Group
+	if v := ret.Get(0); v != nil {
+		r0 = v.(*marathon.Group)
+	}
+	return r0, ret.Error(1)
+}
+
+// GroupsBy records the call and returns the values of its expectation
+func (c *Client) GroupsBy(opts *marathon.GetGroupOpts) (*marathon.Groups, error) {
+	ret := c.Called(opts)
+	var r0 *marathon.Groups
+	if v := ret.Get(0); v != nil {
+		r0 = v.(*marathon.Groups)
+	}
+	return r0, ret.Error(1)
+}
+
+// GroupBy records the call and returns the values of its expectation
+func (c *Client) GroupBy(name string, opts *marathon.GetGroupOpts) (*marathon.Group, error) {
+	ret := c.Called(name, opts)
+	var r0 *marathon.Group
+	if v := ret.Get(0); v != nil {
+		r0 = v.(*marathon.Group)
+	}
+	return r0, ret.Error(1)
+}
+
+// CreateGroup records the call and returns the values of its expectation
+func (c *Client) CreateGroup(group *marathon.Group) error {
+	ret := c.Called(group)
+	return ret.Error(0)
+}
+
+// DeleteGroup records the call and returns the values of its expectation
+func (c *Client) DeleteGroup(name string, force bool) (*marathon.DeploymentID, error) {
+	ret := c.Called(name, force)
+	var r0 *marathon.DeploymentID
+	if v := ret.Get(0); v != nil {
+		r0 = v.(*marathon.DeploymentID)
+	}
+	return r0, ret.Error(1)
+}
+
+// UpdateGroup records the call and returns the values of its expectation
+func (c *Client) UpdateGroup(id string, group *marathon.Group, force bool) (*marathon.DeploymentID, error) {
+	ret := c.Called(id, group, force)
+	var r0 *marathon.DeploymentID
+	if v := ret.Get(0); v != nil {
+		r0 = v.(*marathon.DeploymentID)
+	}
+	return r0, ret.Error(1)
+}
+
+// HasGroup records the call and returns the values of its expectation
+func (c *Client) HasGroup(name string) (bool, error) {
+	ret := c.Called(name)
+	return ret.Bool(0), ret.Error(1)
+}
+
+// WaitOnGroup records the call and returns the values of its expectation
+func (c *Client) WaitOnGroup(name string, timeout time.Duration) error {
+	ret := c.Called(name, timeout)
+	return ret.Error(0)
+}
+
+// ApplyGroup records the call and returns the values of its expectation
+func (c *Client) ApplyGroup(ctx context.Context, group *marathon.Group, opts *marathon.ApplyOptions) (*marathon.ApplyPlan, error) {
+	ret := c.Called(ctx, group, opts)
+	var r0 *marathon.ApplyPlan
+	if v := ret.Get(0); v != nil {
+		r0 = v.(*marathon.ApplyPlan)
+	}
+	return r0, ret.Error(1)
+}
+
+// Deployments records the call and returns the values of its expectation
+func (c *Client) Deployments() ([]*marathon.Deployment, error) {
+	ret := c.Called()
+	var r0 []*marathon.Deployment
+	if v := ret.Get(0); v != nil {
+		r0 = v.([]*marathon.Deployment)
+	}
+	return r0, ret.Error(1)
+}
+
+// DeleteDeployment records the call and returns the values of its expectation
+func (c *Client) DeleteDeployment(id string, force bool) (*marathon.DeploymentID, error) {
+	ret := c.Called(id, force)
+	var r0 *marathon.DeploymentID
+	if v := ret.Get(0); v != nil {
+		r0 = v.(*marathon.DeploymentID)
+	}
+	return r0, ret.Error(1)
+}
+
+// HasDeployment records the call and returns the values of its expectation
+func (c *Client) HasDeployment(id string) (bool, error) {
+	ret := c.Called(id)
+	return ret.Bool(0), ret.Error(1)
+}
+
+// WaitOnDeployment records the call and returns the values of its expectation
+func (c *Client) WaitOnDeployment(id string, timeout time.Duration) error {
+	ret := c.Called(id, timeout)
+	return ret.Error(0)
+}
+
+// Subscriptions records the call and returns the values of its expectation
+func (c *Client) Subscriptions() (*marathon.Subscriptions, error) {
+	ret := c.Called()
+	var r0 *marathon.Subscriptions
+	if v := ret.Get(0); v != nil {
+		r0 = v.(*marathon.Subscriptions)
+	}
+	return r0, ret.Error(1)
+}
+
+// AddEventsListener records the call and returns the values of its expectation
+func (c *Client) AddEventsListener(filter int) (marathon.EventsChannel, error) {
+	ret := c.Called(filter)
+	var r0 marathon.EventsChannel
+	if v := ret.Get(0); v != nil {
+		r0 = v.(marathon.EventsChannel)
+	}
+	return r0, ret.Error(1)
+}
+
+// AddEventsListenerWithOptions records the call and returns the values of its expectation
+func (c *Client) AddEventsListenerWithOptions(filter int, options marathon.EventsListenerOptions) (marathon.EventsChannel, error) {
+	ret := c.Called(filter, options)
+	var r0 marathon.EventsChannel
+	if v := ret.Get(0); v != nil {
+		r0 = v.(marathon.EventsChannel)
+	}
+	return r0, ret.Error(1)
+}
+
+// EventsListenerStats records the call and returns the values of its expectation
+func (c *Client) EventsListenerStats(channel marathon.EventsChannel) (marathon.EventsListenerStats, bool) {
+	ret := c.Called(channel)
+	var r0 marathon.EventsListenerStats
+	if v := ret.Get(0); v != nil {
+		r0 = v.(marathon.EventsListenerStats)
+	}
+	return r0, ret.Bool(1)
+}
+
+// RemoveEventsListener records the call and returns the values of its expectation
+func (c *Client) RemoveEventsListener(channel marathon.EventsChannel) {
+	c.Called(channel)
+}
+
+// Subscribe records the call and returns the values of its expectation
+func (c *Client) Subscribe(arg0 string) error {
+	ret := c.Called(arg0)
+	return ret.Error(0)
+}
+
+// Unsubscribe records the call and returns the values of its expectation
+func (c *Client) Unsubscribe(arg0 string) error {
+	ret := c.Called(arg0)
+	return ret.Error(0)
+}
+
+// OnEvent records the call and returns the values of its expectation
+func (c *Client) OnEvent(filter int, handler func(*marathon.Event), filters ...marathon.EventFilter) (*marathon.EventListener, error) {
+	ret := c.Called(filter, handler, filters)
+	var r0 *marathon.EventListener
+	if v := ret.Get(0); v != nil {
+		r0 = v.(*marathon.EventListener)
+	}
+	return r0, ret.Error(1)
+}
+
+// Queue records the call and returns the values of its expectation
+func (c *Client) Queue() (*marathon.Queue, error) {
+	ret := c.Called()
+	var r0 *marathon.Queue
+	if v := ret.Get(0); v != nil {
+		r0 = v.(*marathon.Queue)
+	}
+	return r0, ret.Error(1)
+}
+
+// DeleteQueueDelay records the call and returns the values of its expectation
+func (c *Client) DeleteQueueDelay(appID string) error {
+	ret := c.Called(appID)
+	return ret.Error(0)
+}
+
+// GetMarathonURL records the call and returns the values of its expectation
+func (c *Client) GetMarathonURL() string {
+	ret := c.Called()
+	return ret.String(0)
+}
+
+// Ping records the call and returns the values of its expectation
+func (c *Client) Ping() (bool, error) {
+	ret := c.Called()
+	return ret.Bool(0), ret.Error(1)
+}
+
+// Info records the call and returns the values of its expectation
+func (c *Client) Info() (*marathon.Info, error) {
+	ret := c.Called()
+	var r0 *marathon.Info
+	if v := ret.Get(0); v != nil {
+		r0 = v.(*marathon.Info)
+	}
+	return r0, ret.Error(1)
+}
+
+// Leader records the call and returns the values of its expectation
+func (c *Client) Leader() (string, error) {
+	ret := c.Called()
+	return ret.String(0), ret.Error(1)
+}
+
+// AbdicateLeader records the call and returns the values of its expectation
+func (c *Client) AbdicateLeader() (string, error) {
+	ret := c.Called()
+	return ret.String(0), ret.Error(1)
+}
+
+// Members records the call and returns the values of its expectation
+func (c *Client) Members() []marathon.Member {
+	ret := c.Called()
+	var r0 []marathon.Member
+	if v := ret.Get(0); v != nil {
+		r0 = v.([]marathon.Member)
+	}
+	return r0
+}
+
+// Close records the call and returns the values of its expectation
+func (c *Client) Close() error {
+	ret := c.Called()
+	return ret.Error(0)
+}
+
+// WithContext records the call and returns the values of its expectation
+func (c *Client) WithContext(ctx context.Context) marathon.Marathon {
+	ret := c.Called(ctx)
+	var r0 marathon.Marathon
+	if v := ret.Get(0); v != nil {
+		r0 = v.(marathon.Marathon)
+	}
+	return r0
+}
+
+// WithConflictResolution records the call and returns the values of its expectation
+func (c *Client) WithConflictResolution(resolution *marathon.ConflictResolution) marathon.Marathon {
+	ret := c.Called(resolution)
+	var r0 marathon.Marathon
+	if v := ret.Get(0); v != nil {
+		r0 = v.(marathon.Marathon)
+	}
+	return r0
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathonmock
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os/exec"
+	"testing"
+	"time"
+
+	marathon "github.com/gambol99/go-marathon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientExpectations(t *testing.T) {
+	client := NewClient()
+	application := new(marathon.Application).Name("/prod/web")
+	client.On("Application", "/prod/web").Return(application, nil).Once()
+	client.On("Application", mock.Anything).Return(nil, errors.New("not found"))
+	client.On("ScaleApplicationInstances", "/prod/web", mock.MatchedBy(func(instances int) bool {
+		return instances > 0
+	}), false).Return(&marathon.DeploymentID{DeploymentID: "1"}, nil)
+	client.On("HasDeployment", "1").Return(true, nil)
+	client.On("WaitOnDeployment", "1", time.Minute).Return(nil)
+
+	var api marathon.Marathon = client
+	found, err := api.Application("/prod/web")
+	require.NoError(t, err)
+	assert.Equal(t, application, found)
+	found, err = api.Application("/prod/web")
+	assert.Error(t, err)
+	assert.Nil(t, found)
+
+	deployment, err := api.ScaleApplicationInstances("/prod/web", 2, false)
+	require.NoError(t, err)
+	assert.Equal(t, "1", deployment.DeploymentID)
+	assert.Panics(t, func() { api.ScaleApplicationInstances("/prod/web", 0, false) })
+	deploying, err := api.HasDeployment("1")
+	require.NoError(t, err)
+	assert.True(t, deploying)
+	assert.NoError(t, api.WaitOnDeployment("1", time.Minute))
+
+	client.AssertExpectations(t)
+	client.AssertNumberOfCalls(t, "Application", 2)
+	client.AssertCalled(t, "ScaleApplicationInstances", "/prod/web", 2, false)
+	client.AssertNotCalled(t, "DeleteApplication", mock.Anything, mock.Anything)
+}
+
+func TestClientCallbacks(t *testing.T) {
+	client := NewClient()
+	client.On("EachApplication", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		fn := args.Get(1).(func(*marathon.Application) error)
+		fn(new(marathon.Application).Name("/app"))
+	})
+	client.On("OnEvent", marathon.EventIDDeploymentSuccess, mock.Anything, []marathon.EventFilter(nil)).Return(nil, nil)
+	client.On("WithContext", mock.Anything).Return(client)
+	client.On("Members").Return(nil)
+	client.On("RemoveEventsListener", mock.Anything)
+
+	var ids []string
+	api := client.WithContext(context.Background())
+	assert.NoError(t, api.EachApplication(nil, func(application *marathon.Application) error {
+		ids = append(ids, application.ID)
+		return nil
+	}))
+	assert.Equal(t, []string{"/app"}, ids)
+	listener, err := api.OnEvent(marathon.EventIDDeploymentSuccess, func(*marathon.Event) {})
+	assert.NoError(t, err)
+	assert.Nil(t, listener)
+	assert.Nil(t, api.Members())
+	api.RemoveEventsListener(nil)
+
+	client.AssertExpectations(t)
+}
+
+func TestClientGenerated(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("the go tool is not available")
+	}
+	generated, err := exec.Command("go", "run", "gen.go", "-input", "../client.go", "-output", "-").Output()
+	require.NoError(t, err)
+	current, err := ioutil.ReadFile("client.go")
+	require.NoError(t, err)
+	assert.True(t, bytes.Equal(generated, current), "client.go is out of date with the Marathon interface, run go generate")
+}
@@ -0,0 +1,274 @@
+// +build ignore
+
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// gen generates the methods of the mock from the Marathon interface of client.go:
+//
+//	go run gen.go -input ../client.go -output client.go
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const header = `/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by gen.go from the Marathon interface; DO NOT EDIT.
+
+package marathonmock
+
+`
+
+// accessors are the methods of mock.Arguments returning the values of the basic types
+var accessors = map[string]string{
+	"bool":   "Bool",
+	"error":  "Error",
+	"int":    "Int",
+	"string": "String",
+}
+
+func main() {
+	input := flag.String("input", "../client.go", "the file declaring the Marathon interface")
+	output := flag.String("output", "client.go", "the file to generate, - for the standard output")
+	flag.Parse()
+
+	source, err := generate(*input)
+	if err != nil {
+		log.Fatalf("failed to generate the mock: %s", err)
+	}
+	if *output == "-" {
+		os.Stdout.Write(source)
+		return
+	}
+	if err := ioutil.WriteFile(*output, source, 0644); err != nil {
+		log.Fatalf("failed to write the mock: %s", err)
+	}
+}
+
+// generate returns the source of the methods of the mock
+func generate(input string) ([]byte, error) {
+	fileSet := token.NewFileSet()
+	file, err := parser.ParseFile(fileSet, input, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	iface := findInterface(file, "Marathon")
+	if iface == nil {
+		return nil, fmt.Errorf("the Marathon interface is not declared in %s", input)
+	}
+
+	// step: the imports of the file are needed for the qualified types of the methods
+	paths := make(map[string]string)
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		name := path[strings.LastIndex(path, "/")+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		paths[name] = path
+	}
+	used := make(map[string]string)
+
+	body := new(bytes.Buffer)
+	for _, field := range iface.Methods.List {
+		signature, ok := field.Type.(*ast.FuncType)
+		if !ok || len(field.Names) == 0 {
+			return nil, fmt.Errorf("the embedded interfaces of Marathon are not supported")
+		}
+		qualify(signature, paths, used)
+		writeMethod(body, fileSet, field.Names[0].Name, signature)
+	}
+
+	source := bytes.NewBufferString(header)
+	var names []string
+	for name := range used {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		standardI, standardJ := !strings.Contains(used[names[i]], "."), !strings.Contains(used[names[j]], ".")
+		if standardI != standardJ {
+			return standardI
+		}
+		return used[names[i]] < used[names[j]]
+	})
+	source.WriteString("import (\n")
+	for index, name := range names {
+		// step: the packages of the standard library are grouped apart
+		if index > 0 && !strings.Contains(used[names[index-1]], ".") && strings.Contains(used[name], ".") {
+			source.WriteString("\n")
+		}
+		if strings.HasSuffix(used[name], "/"+name) || used[name] == name {
+			fmt.Fprintf(source, "\t%q\n", used[name])
+		} else {
+			fmt.Fprintf(source, "\t%s %q\n", name, used[name])
+		}
+	}
+	source.WriteString(")\n")
+	source.Write(body.Bytes())
+
+	return format.Source(source.Bytes())
+}
+
+// findInterface returns the declaration of an interface
+func findInterface(file *ast.File, name string) *ast.InterfaceType {
+	for _, decl := range file.Decls {
+		declaration, ok := decl.(*ast.GenDecl)
+		if !ok || declaration.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range declaration.Specs {
+			if spec := spec.(*ast.TypeSpec); spec.Name.Name == name {
+				iface, _ := spec.Type.(*ast.InterfaceType)
+				return iface
+			}
+		}
+	}
+	return nil
+}
+
+// qualify qualifies the types of package marathon in the signature, recording the packages it uses
+//		paths:		the import paths of the file, by package name
+//		used:		the import paths used by the mock, by package name
+func qualify(signature *ast.FuncType, paths, used map[string]string) {
+	ast.Inspect(signature, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.SelectorExpr:
+			if pkg, ok := node.X.(*ast.Ident); ok {
+				if path, found := paths[pkg.Name]; found {
+					used[pkg.Name] = path
+				}
+			}
+			return false
+		case *ast.Field:
+			node.Type = qualifyType(node.Type, used)
+		}
+		return true
+	})
+}
+
+// qualifyType returns the type, as a qualified identifier when it is an exported type of package marathon
+func qualifyType(expr ast.Expr, used map[string]string) ast.Expr {
+	if ident, ok := expr.(*ast.Ident); ok && unicode.IsUpper(rune(ident.Name[0])) {
+		used["marathon"] = "github.com/gambol99/go-marathon"
+		return &ast.SelectorExpr{X: ast.NewIdent("marathon"), Sel: ast.NewIdent(ident.Name)}
+	}
+	switch node := expr.(type) {
+	case *ast.StarExpr:
+		node.X = qualifyType(node.X, used)
+	case *ast.ArrayType:
+		node.Elt = qualifyType(node.Elt, used)
+	case *ast.Ellipsis:
+		node.Elt = qualifyType(node.Elt, used)
+	case *ast.MapType:
+		node.Key = qualifyType(node.Key, used)
+		node.Value = qualifyType(node.Value, used)
+	case *ast.ChanType:
+		node.Value = qualifyType(node.Value, used)
+	}
+	return expr
+}
+
+// writeMethod writes the method of the mock recording the call and returning the values of its expectation
+func writeMethod(w *bytes.Buffer, fileSet *token.FileSet, name string, signature *ast.FuncType) {
+	var params, args []string
+	for _, field := range signature.Params.List {
+		typ := render(fileSet, field.Type)
+		if len(field.Names) == 0 {
+			arg := fmt.Sprintf("arg%d", len(args))
+			params = append(params, arg+" "+typ)
+			args = append(args, arg)
+			continue
+		}
+		for _, ident := range field.Names {
+			params = append(params, ident.Name+" "+typ)
+			args = append(args, ident.Name)
+		}
+	}
+
+	var results []string
+	if signature.Results != nil {
+		for _, field := range signature.Results.List {
+			count := len(field.Names)
+			if count == 0 {
+				count = 1
+			}
+			for i := 0; i < count; i++ {
+				results = append(results, render(fileSet, field.Type))
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "\n// %s records the call and returns the values of its expectation\n", name)
+	fmt.Fprintf(w, "func (c *Client) %s(%s)", name, strings.Join(params, ", "))
+	switch len(results) {
+	case 0:
+		fmt.Fprintf(w, " {\n\tc.Called(%s)\n}\n", strings.Join(args, ", "))
+		return
+	case 1:
+		fmt.Fprintf(w, " %s {\n", results[0])
+	default:
+		fmt.Fprintf(w, " (%s) {\n", strings.Join(results, ", "))
+	}
+
+	fmt.Fprintf(w, "\tret := c.Called(%s)\n", strings.Join(args, ", "))
+	var values []string
+	for index, typ := range results {
+		if accessor, found := accessors[typ]; found {
+			values = append(values, fmt.Sprintf("ret.%s(%d)", accessor, index))
+			continue
+		}
+		value := fmt.Sprintf("r%d", index)
+		fmt.Fprintf(w, "\tvar %s %s\n\tif v := ret.Get(%d); v != nil {\n\t\t%s = v.(%s)\n\t}\n", value, typ, index, value, typ)
+		values = append(values, value)
+	}
+	fmt.Fprintf(w, "\treturn %s\n}\n", strings.Join(values, ", "))
+}
+
+// render returns the source of a type
+func render(fileSet *token.FileSet, expr ast.Expr) string {
+	buffer := new(bytes.Buffer)
+	printer.Fprint(buffer, fileSet, expr)
+	return buffer.String()
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package marathonmock provides a mock of the Marathon interface, for the unit tests of the services using
+// go-marathon. The mock is built on the mock package of testify: the expectations of the calls are recorded with On,
+// the arguments are matched by value or with the matchers of testify, e.g. mock.Anything or mock.MatchedBy, and the
+// calls are asserted with AssertExpectations, AssertCalled or AssertNumberOfCalls.
+//
+//	client := marathonmock.NewClient()
+//	client.On("Application", "/prod/web").Return(application, nil).Once()
+//	client.On("ScaleApplicationInstances", "/prod/web", mock.Anything, false).Return(deployment, nil)
+//	...
+//	client.AssertExpectations(t)
+//
+// The methods are generated from the Marathon interface, the variadic arguments being recorded as a single slice.
+// A call without a matching expectation panics.
+package marathonmock
+
+//go:generate go run gen.go -input ../client.go -output client.go
+
+import (
+	marathon "github.com/gambol99/go-marathon"
+	"github.com/stretchr/testify/mock"
+)
+
+// Client is a mock of the Marathon interface
+type Client struct {
+	mock.Mock
+}
+
+var _ marathon.Marathon = &Client{}
+
+// NewClient creates a mock without any expectation
+func NewClient() *Client {
+	return &Client{}
+}
@@ -0,0 +1,718 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathontest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	marathon "github.com/gambol99/go-marathon"
+)
+
+// applicationServerFields are the fields of the applications populated by Marathon, ignored in the definitions
+// sent to the server
+var applicationServerFields = []string{"tasks", "tasksRunning", "tasksStaged", "tasksHealthy", "tasksUnhealthy",
+	"taskStats", "deployments", "readinessCheckResults", "lastTaskFailure", "version", "versionInfo"}
+
+// application is the state of an application
+type application struct {
+	definition *marathon.Application
+	// versions are the definitions of the application, the oldest first
+	versions        []*marathon.Application
+	tasks           []*marathon.Task
+	lastTaskFailure *marathon.LastTaskFailure
+	// deleted marks the applications being stopped by a deployment
+	deleted bool
+}
+
+// instances returns the number of instances of the application
+func (a *application) instances() int {
+	return instancesOf(a.definition)
+}
+
+// portsCount returns the number of ports assigned to the tasks of the application
+func (a *application) portsCount() int {
+	definition := a.definition
+	if container := definition.Container; container != nil && container.Docker != nil &&
+		container.Docker.PortMappings != nil &&
+		(container.Docker.Network == "BRIDGE" || container.Docker.Network == "USER") {
+		return len(*container.Docker.PortMappings)
+	}
+	if definition.PortDefinitions != nil {
+		return len(*definition.PortDefinitions)
+	}
+	return len(definition.Ports)
+}
+
+// hasHealthChecks checks if the application defines health checks
+func (a *application) hasHealthChecks() bool {
+	return a.definition.HealthChecks != nil && len(*a.definition.HealthChecks) > 0
+}
+
+// Application returns the application of the given ID as Marathon would, nil if there is none
+//		id:		the ID of the application
+func (s *Server) Application(id string) *marathon.Application {
+	s.Lock()
+	defer s.Unlock()
+
+	a := s.application(normalizeID(id))
+	if a == nil {
+		return nil
+	}
+	return s.applicationView(a, true)
+}
+
+// FailTask fails a task of an application, as if it had crashed, and launches a replacement, as Marathon does
+//		id:		the ID of the task
+//		message:	the message of the failure, reported as the last task failure of the application
+func (s *Server) FailTask(id, message string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	a, task := s.task(id)
+	if task == nil {
+		return fmt.Errorf("the task: %s does not exist", id)
+	}
+	s.endTask(a, task, "TASK_FAILED", message)
+	a.lastTaskFailure = &marathon.LastTaskFailure{
+		AppID:     task.AppID,
+		Host:      task.Host,
+		Message:   message,
+		State:     "TASK_FAILED",
+		TaskID:    task.ID,
+		Timestamp: s.timestamp(),
+		Version:   task.Version,
+	}
+	s.launchTask(a)
+
+	return nil
+}
+
+// application returns the application of the given ID, nil if there is none or if it is being deleted
+func (s *Server) application(id string) *application {
+	if a, found := s.applications[id]; found && !a.deleted {
+		return a
+	}
+	return nil
+}
+
+// task returns a task and its application, nil if there is none
+func (s *Server) task(id string) (*application, *marathon.Task) {
+	for _, a := range s.applications {
+		for _, task := range a.tasks {
+			if task.ID == id {
+				return a, task
+			}
+		}
+	}
+	return nil, nil
+}
+
+// sortedApplications returns the applications which are not being deleted, sorted by ID
+func (s *Server) sortedApplications() []*application {
+	var applications []*application
+	for _, a := range s.applications {
+		if !a.deleted {
+			applications = append(applications, a)
+		}
+	}
+	sort.Slice(applications, func(i, j int) bool {
+		return applications[i].definition.ID < applications[j].definition.ID
+	})
+	return applications
+}
+
+// applicationView returns the application as returned by Marathon, with its tasks and counts
+//		a:		the application
+//		embedTasks:	whether the tasks are embedded
+func (s *Server) applicationView(a *application, embedTasks bool) *marathon.Application {
+	view := new(marathon.Application)
+	copyJSON(a.definition, view)
+	view.TasksRunning = len(a.tasks)
+	if a.hasHealthChecks() {
+		view.TasksHealthy = len(a.tasks)
+	}
+	if embedTasks {
+		view.Tasks = []*marathon.Task{}
+		for _, task := range a.tasks {
+			copied := *task
+			view.Tasks = append(view.Tasks, &copied)
+		}
+	}
+	view.LastTaskFailure = a.lastTaskFailure
+	view.Deployments = []map[string]string{}
+	for _, d := range s.lockingDeployments(a.definition.ID) {
+		view.Deployments = append(view.Deployments, map[string]string{"id": d.id})
+	}
+	return view
+}
+
+// setApplication records a new version of the definition of an application
+//		definition:	the definition of the application
+//		version:	the version of the definition
+//		configChange:	whether the configuration has changed, restarting the tasks, rather than the scaling only
+func (s *Server) setApplication(definition *marathon.Application, version string, configChange bool) *application {
+	a, found := s.applications[definition.ID]
+	if !found {
+		a = new(application)
+		s.applications[definition.ID] = a
+		configChange = true
+	}
+	a.deleted = false
+
+	definition.Tasks = nil
+	definition.TasksRunning, definition.TasksStaged, definition.TasksHealthy, definition.TasksUnhealthy = 0, 0, 0, 0
+	definition.TaskStats = nil
+	definition.Deployments = nil
+	definition.ReadinessCheckResults = nil
+	definition.LastTaskFailure = nil
+	if definition.Instances == nil {
+		instances := 1
+		definition.Instances = &instances
+	}
+	definition.Version = version
+	versionInfo := &marathon.VersionInfo{LastScalingAt: version, LastConfigChangeAt: version}
+	if !configChange && a.definition != nil && a.definition.VersionInfo != nil {
+		versionInfo.LastConfigChangeAt = a.definition.VersionInfo.LastConfigChangeAt
+	}
+	definition.VersionInfo = versionInfo
+
+	a.definition = definition
+	stored := new(marathon.Application)
+	copyJSON(definition, stored)
+	a.versions = append(a.versions, stored)
+
+	return a
+}
+
+// convergeApplication replaces the tasks of an outdated configuration and scales the application, or stops it
+// when it is deleted
+func (s *Server) convergeApplication(a *application) {
+	if a.deleted {
+		for len(a.tasks) > 0 {
+			s.endTask(a, a.tasks[0], "TASK_KILLED", "")
+		}
+		delete(s.applications, a.definition.ID)
+		s.emit("app_terminated_event", map[string]interface{}{"appId": a.definition.ID})
+		return
+	}
+
+	for _, task := range append([]*marathon.Task{}, a.tasks...) {
+		if task.Version != a.definition.VersionInfo.LastConfigChangeAt {
+			s.endTask(a, task, "TASK_KILLED", "")
+		}
+	}
+	for len(a.tasks) > a.instances() {
+		s.endTask(a, a.tasks[len(a.tasks)-1], "TASK_KILLED", "")
+	}
+	for len(a.tasks) < a.instances() {
+		s.launchTask(a)
+	}
+}
+
+// launchTask launches a running task of the application
+func (s *Server) launchTask(a *application) {
+	host, agentID := s.placement()
+	task := &marathon.Task{
+		ID:        strings.Replace(strings.TrimPrefix(a.definition.ID, "/"), "/", "_", -1) + "." + newUUID(),
+		AppID:     a.definition.ID,
+		Host:      host,
+		SlaveID:   agentID,
+		Ports:     []int{},
+		StagedAt:  s.timestamp(),
+		StartedAt: s.timestamp(),
+		State:     "TASK_RUNNING",
+		Version:   a.definition.VersionInfo.LastConfigChangeAt,
+	}
+	for i := 0; i < a.portsCount(); i++ {
+		task.Ports = append(task.Ports, s.nextPort)
+		s.nextPort++
+	}
+	if a.hasHealthChecks() {
+		for range *a.definition.HealthChecks {
+			task.HealthCheckResults = append(task.HealthCheckResults, &marathon.HealthCheckResult{
+				Alive:        true,
+				FirstSuccess: s.timestamp(),
+				LastSuccess:  s.timestamp(),
+				TaskID:       task.ID,
+			})
+		}
+	}
+	a.tasks = append(a.tasks, task)
+	s.emitStatusUpdate(task, "")
+}
+
+// endTask removes a task of the application in a terminal state
+//		state:		the terminal state, e.g. "TASK_KILLED"
+//		message:	the message of the status update
+func (s *Server) endTask(a *application, task *marathon.Task, state, message string) {
+	for index := range a.tasks {
+		if a.tasks[index] == task {
+			a.tasks = append(a.tasks[:index], a.tasks[index+1:]...)
+			break
+		}
+	}
+	task.State = state
+	s.emitStatusUpdate(task, message)
+}
+
+// emitStatusUpdate publishes the state of a task
+func (s *Server) emitStatusUpdate(task *marathon.Task, message string) {
+	s.emit("status_update_event", map[string]interface{}{
+		"slaveId":     task.SlaveID,
+		"taskId":      task.ID,
+		"taskStatus":  task.State,
+		"message":     message,
+		"appId":       task.AppID,
+		"host":        task.Host,
+		"ports":       task.Ports,
+		"ipAddresses": []interface{}{},
+		"version":     task.Version,
+	})
+}
+
+// emitGroupChange publishes the change of the group of a run spec
+func (s *Server) emitGroupChange(id, version string) {
+	group := id[:strings.LastIndex(id, "/")]
+	if group == "" {
+		group = "/"
+	}
+	s.emit("group_change_success", map[string]interface{}{"groupId": group, "version": version})
+}
+
+// handleApplications handles the requests of the applications, their tasks and their versions
+func (s *Server) handleApplications(w http.ResponseWriter, r *http.Request, path string) {
+	s.Lock()
+	defer s.Unlock()
+
+	if path == "" {
+		switch r.Method {
+		case "GET":
+			s.listApplications(w, r)
+		case "POST":
+			s.createApplication(w, r)
+		default:
+			writeMessage(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+		return
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	last := len(segments) - 1
+	switch {
+	case last > 0 && segments[last-1] == "tasks" && r.Method == "DELETE":
+		s.killApplicationTask(w, r, normalizeID(strings.Join(segments[:last-1], "/")), segments[last])
+	case last > 0 && segments[last-1] == "versions" && r.Method == "GET":
+		s.applicationVersion(w, normalizeID(strings.Join(segments[:last-1], "/")), segments[last])
+	case last > 0 && segments[last] == "tasks":
+		s.handleApplicationTasks(w, r, normalizeID(strings.Join(segments[:last], "/")))
+	case last > 0 && segments[last] == "versions" && r.Method == "GET":
+		s.applicationVersions(w, normalizeID(strings.Join(segments[:last], "/")))
+	case last > 0 && segments[last] == "restart" && r.Method == "POST":
+		s.restartApplication(w, r, normalizeID(strings.Join(segments[:last], "/")))
+	case r.Method == "GET":
+		a := s.application(normalizeID(path))
+		if a == nil {
+			writeAppNotFound(w, normalizeID(path))
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"app": s.applicationView(a, true)})
+	case r.Method == "PUT":
+		s.updateApplication(w, r, normalizeID(path))
+	case r.Method == "DELETE":
+		s.deleteApplication(w, r, normalizeID(path))
+	default:
+		writeMessage(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// listApplications lists the applications, filtered by the id and label parameters
+func (s *Server) listApplications(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	embedTasks := false
+	for _, embed := range query["embed"] {
+		if embed == "apps.tasks" || embed == "tasks" {
+			embedTasks = true
+		}
+	}
+
+	applications := []*marathon.Application{}
+	for _, a := range s.sortedApplications() {
+		if id := query.Get("id"); id != "" && !strings.Contains(a.definition.ID, id) {
+			continue
+		}
+		if label := query.Get("label"); label != "" && !matchLabel(a.definition.Labels, label) {
+			continue
+		}
+		applications = append(applications, s.applicationView(a, embedTasks))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"apps": applications})
+}
+
+// matchLabel checks if the labels match a selector, either "key" or "key==value"
+func matchLabel(labels *map[string]string, selector string) bool {
+	if labels == nil {
+		return false
+	}
+	key, value := selector, ""
+	if index := strings.Index(selector, "=="); index >= 0 {
+		key, value = selector[:index], selector[index+2:]
+	}
+	actual, found := (*labels)[key]
+	return found && (value == "" || actual == value)
+}
+
+// createApplication creates an application, failing if it exists already
+func (s *Server) createApplication(w http.ResponseWriter, r *http.Request) {
+	definition := new(marathon.Application)
+	if !decodeBody(w, r, definition) {
+		return
+	}
+	definition.ID = normalizeID(definition.ID)
+	if err := definition.Validate(); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+	if s.application(definition.ID) != nil {
+		writeMessage(w, http.StatusConflict, fmt.Sprintf("An app with id [%s] already exists.", definition.ID))
+		return
+	}
+	d := s.newDeployment()
+	if s.lockedBy(w, r, d, definition.ID) {
+		return
+	}
+
+	version := s.version()
+	a := s.setApplication(definition, version, true)
+	d.addApplication(definition.ID, "StartApplication", nil)
+	s.emit("api_post_event", map[string]interface{}{
+		"clientIp":      r.RemoteAddr,
+		"uri":           "/v2/apps",
+		"appDefinition": a.definition,
+	})
+	s.emitGroupChange(definition.ID, version)
+	s.startDeployment(d, version)
+
+	w.Header().Set("Location", "/v2/apps"+definition.ID)
+	w.Header().Set("Marathon-Deployment-Id", d.id)
+	writeJSON(w, http.StatusCreated, s.applicationView(a, false))
+}
+
+// updateApplication updates an application, the fields missing from the request being kept, or creates it.
+// A request holding a version only reverts the application to it.
+func (s *Server) updateApplication(w http.ResponseWriter, r *http.Request, id string) {
+	var fields map[string]json.RawMessage
+	if !decodeBody(w, r, &fields) {
+		return
+	}
+
+	current := s.application(id)
+	definition := new(marathon.Application)
+	if version, found := fields["version"]; found && len(fields) == 1 {
+		var wanted string
+		json.Unmarshal(version, &wanted)
+		if current == nil || applicationVersion(current, wanted) == nil {
+			writeMessage(w, http.StatusNotFound, fmt.Sprintf("Version %s of app %s not found", wanted, id))
+			return
+		}
+		copyJSON(applicationVersion(current, wanted), definition)
+	} else {
+		merged := make(map[string]json.RawMessage)
+		if current != nil {
+			copyJSON(current.definition, &merged)
+		}
+		for _, field := range applicationServerFields {
+			delete(fields, field)
+		}
+		for key, value := range fields {
+			if string(value) != "null" {
+				merged[key] = value
+			}
+		}
+		copyJSON(merged, definition)
+	}
+	definition.ID = id
+	if err := definition.Validate(); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+	d := s.newDeployment()
+	if s.lockedBy(w, r, d, id) {
+		return
+	}
+
+	version := s.version()
+	var previous *marathon.Application
+	action := "StartApplication"
+	configChange := true
+	if current != nil {
+		previous = new(marathon.Application)
+		copyJSON(current.definition, previous)
+		configChange = configChanged(current.definition, definition)
+		action = "RestartApplication"
+		if !configChange {
+			action = "ScaleApplication"
+		}
+	}
+	a := s.setApplication(definition, version, configChange)
+	d.addApplication(id, action, previous)
+	s.emit("api_post_event", map[string]interface{}{
+		"clientIp":      r.RemoteAddr,
+		"uri":           "/v2/apps" + id,
+		"appDefinition": a.definition,
+	})
+	s.emitGroupChange(id, version)
+	writeJSON(w, http.StatusOK, s.startDeployment(d, version))
+}
+
+// deleteApplication stops and removes an application
+func (s *Server) deleteApplication(w http.ResponseWriter, r *http.Request, id string) {
+	a := s.application(id)
+	if a == nil {
+		writeAppNotFound(w, id)
+		return
+	}
+	d := s.newDeployment()
+	if s.lockedBy(w, r, d, id) {
+		return
+	}
+
+	version := s.version()
+	s.stopApplication(d, a)
+	s.emitGroupChange(id, version)
+	writeJSON(w, http.StatusOK, s.startDeployment(d, version))
+}
+
+// stopApplication marks an application as deleted, its tasks being killed by the deployment
+func (s *Server) stopApplication(d *deployment, a *application) {
+	previous := new(marathon.Application)
+	copyJSON(a.definition, previous)
+	a.deleted = true
+	d.addApplication(a.definition.ID, "StopApplication", previous)
+}
+
+// restoreApplication restores the definition of an application in a rollback
+//		previous:	the definition to restore, nil to delete the application
+func (s *Server) restoreApplication(d *deployment, id string, previous *marathon.Application, version string) {
+	a, found := s.applications[id]
+	if previous == nil {
+		if found && !a.deleted {
+			s.stopApplication(d, a)
+		}
+		return
+	}
+
+	var current *marathon.Application
+	action := "StartApplication"
+	if found && !a.deleted {
+		current = new(marathon.Application)
+		copyJSON(a.definition, current)
+		action = "RestartApplication"
+	}
+	definition := new(marathon.Application)
+	copyJSON(previous, definition)
+	s.setApplication(definition, version, true)
+	d.addApplication(id, action, current)
+}
+
+// restartApplication restarts the tasks of an application
+func (s *Server) restartApplication(w http.ResponseWriter, r *http.Request, id string) {
+	a := s.application(id)
+	if a == nil {
+		writeAppNotFound(w, id)
+		return
+	}
+	d := s.newDeployment()
+	if s.lockedBy(w, r, d, id) {
+		return
+	}
+
+	version := s.version()
+	previous := new(marathon.Application)
+	copyJSON(a.definition, previous)
+	definition := new(marathon.Application)
+	copyJSON(a.definition, definition)
+	s.setApplication(definition, version, true)
+	d.addApplication(id, "RestartApplication", previous)
+	writeJSON(w, http.StatusOK, s.startDeployment(d, version))
+}
+
+// applicationVersions lists the versions of an application, the latest first
+func (s *Server) applicationVersions(w http.ResponseWriter, id string) {
+	a := s.application(id)
+	if a == nil {
+		writeAppNotFound(w, id)
+		return
+	}
+	versions := []string{}
+	for index := len(a.versions) - 1; index >= 0; index-- {
+		versions = append(versions, a.versions[index].Version)
+	}
+	writeJSON(w, http.StatusOK, map[string][]string{"versions": versions})
+}
+
+// applicationVersion returns a version of the definition of an application
+func (s *Server) applicationVersion(w http.ResponseWriter, id, version string) {
+	a := s.application(id)
+	if a == nil {
+		writeAppNotFound(w, id)
+		return
+	}
+	definition := applicationVersion(a, version)
+	if definition == nil {
+		writeMessage(w, http.StatusNotFound, fmt.Sprintf("Version %s of app %s not found", version, id))
+		return
+	}
+	writeJSON(w, http.StatusOK, definition)
+}
+
+// applicationVersion returns a version of the definition of an application, nil if there is none
+func applicationVersion(a *application, version string) *marathon.Application {
+	for _, definition := range a.versions {
+		if definition.Version == version {
+			return definition
+		}
+	}
+	return nil
+}
+
+// handleApplicationTasks lists the tasks of an application or kills them
+func (s *Server) handleApplicationTasks(w http.ResponseWriter, r *http.Request, id string) {
+	a := s.application(id)
+	if a == nil {
+		writeAppNotFound(w, id)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		writeJSON(w, http.StatusOK, map[string]interface{}{"tasks": s.applicationView(a, true).Tasks})
+	case "DELETE":
+		host := r.URL.Query().Get("host")
+		var killed []*marathon.Task
+		for _, task := range a.tasks {
+			if host == "" || task.Host == host {
+				killed = append(killed, task)
+			}
+		}
+		if version, scaled := s.killTasks(a, killed, r.URL.Query().Get("scale") == "true"); scaled {
+			writeJSON(w, http.StatusOK, marathon.DeploymentID{DeploymentID: newUUID(), Version: version})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"tasks": killed})
+	default:
+		writeMessage(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// killApplicationTask kills a task of an application
+func (s *Server) killApplicationTask(w http.ResponseWriter, r *http.Request, id, taskID string) {
+	a := s.application(id)
+	if a == nil {
+		writeAppNotFound(w, id)
+		return
+	}
+	for _, task := range a.tasks {
+		if task.ID == taskID {
+			s.killTasks(a, []*marathon.Task{task}, r.URL.Query().Get("scale") == "true")
+			writeJSON(w, http.StatusOK, map[string]interface{}{"task": task})
+			return
+		}
+	}
+	writeMessage(w, http.StatusNotFound, fmt.Sprintf("Task '%s' does not exist", taskID))
+}
+
+// killTasks kills tasks of an application, which are replaced unless the application is scaled down, returning
+// the version of the scaled application
+func (s *Server) killTasks(a *application, tasks []*marathon.Task, scale bool) (string, bool) {
+	for _, task := range tasks {
+		s.endTask(a, task, "TASK_KILLED", "")
+	}
+	if !scale {
+		for range tasks {
+			s.launchTask(a)
+		}
+		return "", false
+	}
+
+	definition := new(marathon.Application)
+	copyJSON(a.definition, definition)
+	instances := a.instances() - len(tasks)
+	if instances < 0 {
+		instances = 0
+	}
+	definition.Instances = &instances
+	version := s.version()
+	s.setApplication(definition, version, false)
+
+	return version, true
+}
+
+// handleTasks lists the tasks of all the applications and kills them
+func (s *Server) handleTasks(w http.ResponseWriter, r *http.Request, path string) {
+	s.Lock()
+	defer s.Unlock()
+
+	switch {
+	case path == "" && r.Method == "GET":
+		tasks := []*marathon.Task{}
+		if status := r.URL.Query().Get("status"); status == "" || status == "running" {
+			for _, a := range s.sortedApplications() {
+				tasks = append(tasks, s.applicationView(a, true).Tasks...)
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"tasks": tasks})
+	case path == "/delete" && r.Method == "POST":
+		var request struct {
+			IDs []string `json:"ids"`
+		}
+		if !decodeBody(w, r, &request) {
+			return
+		}
+		for _, id := range request.IDs {
+			if a, task := s.task(id); task != nil && !a.deleted {
+				s.killTasks(a, []*marathon.Task{task}, r.URL.Query().Get("scale") == "true")
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{})
+	default:
+		writeMessage(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// configChanged checks if the configuration of an application differs, besides its scaling
+func configChanged(a, b *marathon.Application) bool {
+	configuration := func(application *marathon.Application) []byte {
+		copied := new(marathon.Application)
+		copyJSON(application, copied)
+		copied.Instances = nil
+		copied.Version = ""
+		copied.VersionInfo = nil
+		content, _ := json.Marshal(copied)
+		return content
+	}
+	return !bytes.Equal(configuration(a), configuration(b))
+}
+
+// writeAppNotFound writes the 404 Not Found error of a missing application
+func writeAppNotFound(w http.ResponseWriter, id string) {
+	writeMessage(w, http.StatusNotFound, fmt.Sprintf("App '%s' does not exist", id))
+}
@@ -0,0 +1,309 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathontest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	marathon "github.com/gambol99/go-marathon"
+)
+
+// deployment is a deployment of applications and pods, which completes once its duration has elapsed
+type deployment struct {
+	id      string
+	version string
+	started time.Time
+	// actions are the actions of the deployment, each in a step of its own
+	actions []deploymentAction
+	// the definitions before the deployment, to roll it back; nil for the run specs it creates
+	applications map[string]*marathon.Application
+	pods         map[string]*marathon.Pod
+}
+
+// deploymentAction is an action of a deployment, encoded as in the deployments and their events
+type deploymentAction struct {
+	Action string `json:"action"`
+	App    string `json:"app,omitempty"`
+	Pod    string `json:"pod,omitempty"`
+}
+
+// newDeployment creates a deployment, to be started once the changes have been made
+func (s *Server) newDeployment() *deployment {
+	return &deployment{
+		id:           newUUID(),
+		started:      s.now,
+		applications: make(map[string]*marathon.Application),
+		pods:         make(map[string]*marathon.Pod),
+	}
+}
+
+// addApplication adds the action of an application to the deployment. The action of an application added again
+// is replaced, while its first definition is kept.
+//		id:		the ID of the application
+//		action:		the action, e.g. "StartApplication"
+//		previous:	the definition before the deployment, nil when it creates the application
+func (d *deployment) addApplication(id, action string, previous *marathon.Application) {
+	if _, found := d.applications[id]; !found {
+		d.applications[id] = previous
+	}
+	d.setAction(deploymentAction{Action: action, App: id})
+}
+
+// addPod adds the action of a pod to the deployment. The action of a pod added again is replaced, while its first
+// definition is kept.
+//		id:		the ID of the pod
+//		action:		the action, e.g. "StartPod"
+//		previous:	the definition before the deployment, nil when it creates the pod
+func (d *deployment) addPod(id, action string, previous *marathon.Pod) {
+	if _, found := d.pods[id]; !found {
+		d.pods[id] = previous
+	}
+	d.setAction(deploymentAction{Action: action, Pod: id})
+}
+
+// setAction adds the action, or replaces the one of the same run spec
+func (d *deployment) setAction(action deploymentAction) {
+	for index := range d.actions {
+		if d.actions[index].App == action.App && d.actions[index].Pod == action.Pod {
+			d.actions[index] = action
+			return
+		}
+	}
+	d.actions = append(d.actions, action)
+}
+
+// affects checks if the deployment affects the application or the pod
+func (d *deployment) affects(id string) bool {
+	for _, action := range d.actions {
+		if action.App == id || action.Pod == id {
+			return true
+		}
+	}
+	return false
+}
+
+// affectedApps returns the IDs of the applications of the deployment
+func (d *deployment) affectedApps() []string {
+	ids := []string{}
+	for _, action := range d.actions {
+		if action.App != "" {
+			ids = append(ids, action.App)
+		}
+	}
+	return ids
+}
+
+// affectedPods returns the IDs of the pods of the deployment
+func (d *deployment) affectedPods() []string {
+	ids := []string{}
+	for _, action := range d.actions {
+		if action.Pod != "" {
+			ids = append(ids, action.Pod)
+		}
+	}
+	return ids
+}
+
+// steps returns the steps of the deployment, encoded as by Marathon
+func (d *deployment) steps() []map[string]interface{} {
+	steps := []map[string]interface{}{}
+	for _, action := range d.actions {
+		steps = append(steps, map[string]interface{}{"actions": []deploymentAction{action}})
+	}
+	return steps
+}
+
+// plan returns the plan of the deployment, as published in its events
+func (d *deployment) plan() map[string]interface{} {
+	return map[string]interface{}{"id": d.id, "version": d.version, "steps": d.steps()}
+}
+
+// view returns the deployment as listed by Marathon
+func (d *deployment) view() map[string]interface{} {
+	return map[string]interface{}{
+		"id":             d.id,
+		"version":        d.version,
+		"affectedApps":   d.affectedApps(),
+		"affectedPods":   d.affectedPods(),
+		"steps":          d.steps(),
+		"currentActions": d.actions,
+		"currentStep":    1,
+		"totalSteps":     len(d.actions),
+	}
+}
+
+// lockingDeployments returns the running deployments affecting any of the applications or pods
+func (s *Server) lockingDeployments(ids ...string) []*deployment {
+	var locking []*deployment
+	for _, d := range s.deployments {
+		for _, id := range ids {
+			if d.affects(id) {
+				locking = append(locking, d)
+				break
+			}
+		}
+	}
+	return locking
+}
+
+// lockedBy checks the deployments locking the run specs, writing the 409 Conflict error of Marathon unless the
+// request overrides them, in which case they are canceled and taken over by the given deployment
+//		d:		the deployment about to be started
+//		ids:		the IDs of the applications and the pods about to be changed
+func (s *Server) lockedBy(w http.ResponseWriter, r *http.Request, d *deployment, ids ...string) bool {
+	locking := s.lockingDeployments(ids...)
+	if len(locking) == 0 {
+		return false
+	}
+	if !isForced(r) {
+		var references []map[string]string
+		for _, deployment := range locking {
+			references = append(references, map[string]string{"id": deployment.id})
+		}
+		writeJSON(w, http.StatusConflict, map[string]interface{}{
+			"message": fmt.Sprintf("App is locked by one or more deployments. Override with the option '?force=true'. "+
+				"View details at '/v2/deployments/%s'.", locking[0].id),
+			"deployments": references,
+		})
+		return true
+	}
+
+	// step: the run specs of the canceled deployments are still converged by the new one
+	for _, locked := range locking {
+		s.removeDeployment(locked)
+		s.emit("deployment_failed", map[string]interface{}{"id": locked.id})
+		for _, action := range locked.actions {
+			if action.App != "" {
+				d.addApplication(action.App, action.Action, locked.applications[action.App])
+			} else {
+				d.addPod(action.Pod, action.Action, locked.pods[action.Pod])
+			}
+		}
+	}
+	return false
+}
+
+// startDeployment starts a deployment, which completes at once when the deployments take no time
+func (s *Server) startDeployment(d *deployment, version string) *marathon.DeploymentID {
+	d.version = version
+	s.deployments = append(s.deployments, d)
+	if len(d.actions) > 0 {
+		s.emit("deployment_info", map[string]interface{}{
+			"plan":        d.plan(),
+			"currentStep": map[string]interface{}{"actions": []deploymentAction{d.actions[0]}},
+		})
+	}
+	s.progress()
+
+	return &marathon.DeploymentID{DeploymentID: d.id, Version: version}
+}
+
+// progress completes the deployments which are due
+func (s *Server) progress() {
+	for _, d := range append([]*deployment{}, s.deployments...) {
+		if !s.now.Before(d.started.Add(s.options.DeploymentDuration)) {
+			s.completeDeployment(d)
+		}
+	}
+}
+
+// completeDeployment converges the tasks and the instances of the run specs of the deployment
+func (s *Server) completeDeployment(d *deployment) {
+	s.removeDeployment(d)
+	for _, action := range d.actions {
+		if action.App != "" {
+			if a, found := s.applications[action.App]; found {
+				s.convergeApplication(a)
+			}
+		} else if p, found := s.pods[action.Pod]; found {
+			s.convergePod(p)
+		}
+	}
+
+	for _, action := range d.actions {
+		s.emit("deployment_step_success", map[string]interface{}{
+			"plan":        d.plan(),
+			"currentStep": map[string]interface{}{"actions": []deploymentAction{action}},
+		})
+	}
+	s.emit("deployment_success", map[string]interface{}{"id": d.id, "plan": d.plan()})
+}
+
+// removeDeployment removes a deployment from the running ones
+func (s *Server) removeDeployment(d *deployment) {
+	for index, deployment := range s.deployments {
+		if deployment == d {
+			s.deployments = append(s.deployments[:index], s.deployments[index+1:]...)
+			return
+		}
+	}
+}
+
+// handleDeployments lists the running deployments and cancels them
+func (s *Server) handleDeployments(w http.ResponseWriter, r *http.Request, path string) {
+	s.Lock()
+	defer s.Unlock()
+
+	id := strings.TrimPrefix(path, "/")
+	switch {
+	case id == "" && r.Method == "GET":
+		deployments := []map[string]interface{}{}
+		for _, d := range s.deployments {
+			deployments = append(deployments, d.view())
+		}
+		writeJSON(w, http.StatusOK, deployments)
+	case id != "" && r.Method == "DELETE":
+		s.cancelDeployment(w, r, id)
+	default:
+		writeMessage(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// cancelDeployment cancels a running deployment, rolling its changes back in a new deployment unless forced
+func (s *Server) cancelDeployment(w http.ResponseWriter, r *http.Request, id string) {
+	var canceled *deployment
+	for _, d := range s.deployments {
+		if d.id == id {
+			canceled = d
+		}
+	}
+	if canceled == nil {
+		writeMessage(w, http.StatusNotFound, fmt.Sprintf("DeploymentPlan %s does not exist", id))
+		return
+	}
+	s.removeDeployment(canceled)
+	s.emit("deployment_failed", map[string]interface{}{"id": canceled.id})
+	if isForced(r) {
+		writeJSON(w, http.StatusAccepted, map[string]interface{}{})
+		return
+	}
+
+	// step: restore the definitions the deployment has changed
+	rollback := s.newDeployment()
+	version := s.version()
+	for _, action := range canceled.actions {
+		if action.App != "" {
+			s.restoreApplication(rollback, action.App, canceled.applications[action.App], version)
+		} else {
+			s.restorePod(rollback, action.Pod, canceled.pods[action.Pod], version)
+		}
+	}
+	writeJSON(w, http.StatusOK, s.startDeployment(rollback, version))
+}
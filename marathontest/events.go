@@ -0,0 +1,224 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathontest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// eventStreamBuffer is the number of events buffered for each event stream, the events being dropped for
+	// the streams which fall further behind
+	eventStreamBuffer = 1024
+	// callbackTimeout bounds the delivery of an event to a callback subscription
+	callbackTimeout = 5 * time.Second
+)
+
+// event is an event, encoded as published
+type event struct {
+	eventType string
+	content   []byte
+}
+
+// eventStream is a client attached to the event stream
+type eventStream struct {
+	// types are the event types the client is interested in, all of them when empty
+	types  map[string]bool
+	events chan *event
+}
+
+// emit publishes an event to the event streams and the callback subscriptions
+//		eventType:	the type of the event, e.g. "deployment_success"
+//		fields:		the fields of the event, besides its type and timestamp
+func (s *Server) emit(eventType string, fields map[string]interface{}) {
+	fields["eventType"] = eventType
+	if _, found := fields["timestamp"]; !found {
+		fields["timestamp"] = s.timestamp()
+	}
+	content, err := json.Marshal(fields)
+	if err != nil {
+		panic(fmt.Sprintf("failed to encode the event: %s", err))
+	}
+	e := &event{eventType: eventType, content: content}
+
+	for stream := range s.streams {
+		if len(stream.types) > 0 && !stream.types[eventType] {
+			continue
+		}
+		select {
+		case stream.events <- e:
+		default:
+		}
+	}
+	if len(s.subscriptions) > 0 {
+		s.callbacks.push(append([]string{}, s.subscriptions...), e)
+	}
+}
+
+// handleEvents streams the events to the client as server-sent events, filtered by the event_type parameters
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeMessage(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+	stream := &eventStream{types: make(map[string]bool), events: make(chan *event, eventStreamBuffer)}
+	for _, eventType := range r.URL.Query()["event_type"] {
+		stream.types[eventType] = true
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	s.Lock()
+	s.streams[stream] = true
+	s.emit("event_stream_attached", map[string]interface{}{"remoteAddress": r.RemoteAddr})
+	s.Unlock()
+
+	defer func() {
+		s.Lock()
+		delete(s.streams, stream)
+		s.emit("event_stream_detached", map[string]interface{}{"remoteAddress": r.RemoteAddr})
+		s.Unlock()
+	}()
+
+	for {
+		select {
+		case e := <-stream.events:
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.eventType, e.content); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// handleSubscriptions lists, adds and removes the callback subscriptions
+func (s *Server) handleSubscriptions(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	callback := r.URL.Query().Get("callbackUrl")
+	switch r.Method {
+	case "GET":
+		writeJSON(w, http.StatusOK, map[string][]string{"callbackUrls": append([]string{}, s.subscriptions...)})
+		return
+	case "POST", "DELETE":
+	default:
+		writeMessage(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if callback == "" {
+		writeMessage(w, http.StatusBadRequest, "callbackUrl is required")
+		return
+	}
+
+	index := -1
+	for i, subscription := range s.subscriptions {
+		if subscription == callback {
+			index = i
+		}
+	}
+	fields := map[string]interface{}{"callbackUrl": callback, "clientIp": r.RemoteAddr}
+	if r.Method == "POST" {
+		if index < 0 {
+			s.subscriptions = append(s.subscriptions, callback)
+		}
+		s.emit("subscribe_event", fields)
+	} else {
+		if index < 0 {
+			writeMessage(w, http.StatusNotFound, fmt.Sprintf("Callback URL %s does not exist", callback))
+			return
+		}
+		s.emit("unsubscribe_event", fields)
+		s.subscriptions = append(s.subscriptions[:index], s.subscriptions[index+1:]...)
+	}
+	writeJSON(w, http.StatusOK, fields)
+}
+
+// callbackQueue delivers the events to the callback subscriptions in order, without ever blocking the server
+type callbackQueue struct {
+	sync.Mutex
+	pending []callbackDelivery
+	signal  chan struct{}
+	client  *http.Client
+}
+
+// callbackDelivery is an event to deliver to the callback URLs
+type callbackDelivery struct {
+	urls  []string
+	event *event
+}
+
+// newCallbackQueue creates a queue delivering the events until done is closed
+func newCallbackQueue(done chan struct{}) *callbackQueue {
+	queue := &callbackQueue{
+		signal: make(chan struct{}, 1),
+		client: &http.Client{Timeout: callbackTimeout},
+	}
+	go queue.run(done)
+
+	return queue
+}
+
+// push queues an event for delivery
+func (q *callbackQueue) push(urls []string, e *event) {
+	q.Lock()
+	q.pending = append(q.pending, callbackDelivery{urls: urls, event: e})
+	q.Unlock()
+
+	select {
+	case q.signal <- struct{}{}:
+	default:
+	}
+}
+
+// run delivers the queued events, the failed deliveries being dropped as Marathon does
+func (q *callbackQueue) run(done chan struct{}) {
+	for {
+		select {
+		case <-q.signal:
+		case <-done:
+			return
+		}
+
+		q.Lock()
+		pending := q.pending
+		q.pending = nil
+		q.Unlock()
+
+		for _, delivery := range pending {
+			for _, url := range delivery.urls {
+				response, err := q.client.Post(url, "application/json", bytes.NewReader(delivery.event.content))
+				if err == nil {
+					response.Body.Close()
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,292 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathontest
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	marathon "github.com/gambol99/go-marathon"
+)
+
+// groupView is a group as returned by Marathon, with its applications, pods and subgroups
+type groupView struct {
+	ID           string                  `json:"id"`
+	Apps         []*marathon.Application `json:"apps"`
+	Pods         []*marathon.Pod         `json:"pods"`
+	Groups       []*groupView            `json:"groups"`
+	Dependencies []string                `json:"dependencies"`
+}
+
+// groupExists checks if a group has been created or holds applications or pods
+func (s *Server) groupExists(id string) bool {
+	if id == "/" || s.groups[id] {
+		return true
+	}
+	for _, member := range s.groupMembers(id) {
+		if strings.HasPrefix(member, id+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// groupMembers returns the IDs of the applications, the pods and the groups within a group
+func (s *Server) groupMembers(id string) []string {
+	prefix := strings.TrimSuffix(id, "/") + "/"
+	var members []string
+	for _, a := range s.sortedApplications() {
+		if strings.HasPrefix(a.definition.ID, prefix) {
+			members = append(members, a.definition.ID)
+		}
+	}
+	for _, p := range s.sortedPods() {
+		if strings.HasPrefix(p.definition.ID, prefix) {
+			members = append(members, p.definition.ID)
+		}
+	}
+	for group := range s.groups {
+		if strings.HasPrefix(group, prefix) {
+			members = append(members, group)
+		}
+	}
+	return members
+}
+
+// groupView returns the group as returned by Marathon
+func (s *Server) groupView(id string) *groupView {
+	view := &groupView{
+		ID:           id,
+		Apps:         []*marathon.Application{},
+		Pods:         []*marathon.Pod{},
+		Groups:       []*groupView{},
+		Dependencies: []string{},
+	}
+	prefix := strings.TrimSuffix(id, "/") + "/"
+	subgroups := make(map[string]bool)
+	addMember := func(member string) bool {
+		relative := strings.TrimPrefix(member, prefix)
+		if index := strings.Index(relative, "/"); index >= 0 {
+			subgroups[prefix+relative[:index]] = true
+			return false
+		}
+		return true
+	}
+
+	for _, a := range s.sortedApplications() {
+		if strings.HasPrefix(a.definition.ID, prefix) && addMember(a.definition.ID) {
+			view.Apps = append(view.Apps, s.applicationView(a, false))
+		}
+	}
+	for _, p := range s.sortedPods() {
+		if strings.HasPrefix(p.definition.ID, prefix) && addMember(p.definition.ID) {
+			view.Pods = append(view.Pods, s.podView(p))
+		}
+	}
+	for group := range s.groups {
+		if strings.HasPrefix(group, prefix) && addMember(group) {
+			subgroups[group] = true
+		}
+	}
+
+	var sorted []string
+	for group := range subgroups {
+		sorted = append(sorted, group)
+	}
+	sort.Strings(sorted)
+	for _, group := range sorted {
+		view.Groups = append(view.Groups, s.groupView(group))
+	}
+	return view
+}
+
+// handleGroups handles the requests of the groups
+func (s *Server) handleGroups(w http.ResponseWriter, r *http.Request, path string) {
+	s.Lock()
+	defer s.Unlock()
+
+	id := normalizeID(path)
+	switch {
+	case r.Method == "GET":
+		if !s.groupExists(id) {
+			writeMessage(w, http.StatusNotFound, fmt.Sprintf("Group '%s' does not exist", id))
+			return
+		}
+		writeJSON(w, http.StatusOK, s.groupView(id))
+	case r.Method == "POST" && id == "/":
+		s.createGroup(w, r)
+	case r.Method == "PUT":
+		s.updateGroup(w, r, id)
+	case r.Method == "DELETE" && id != "/":
+		s.deleteGroup(w, r, id)
+	default:
+		writeMessage(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// createGroup creates a group with its applications and subgroups
+func (s *Server) createGroup(w http.ResponseWriter, r *http.Request) {
+	group := new(marathon.Group)
+	if !decodeBody(w, r, group) {
+		return
+	}
+	group.ID = normalizeID(group.ID)
+	if err := group.Validate(); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+	if s.groupExists(group.ID) {
+		writeMessage(w, http.StatusConflict, fmt.Sprintf("Group %s is already created. Use PUT to change this group.", group.ID))
+		return
+	}
+	s.deployGroup(w, r, group, http.StatusCreated)
+}
+
+// updateGroup replaces the applications and the subgroups of a group, or creates it
+func (s *Server) updateGroup(w http.ResponseWriter, r *http.Request, id string) {
+	group := new(marathon.Group)
+	if !decodeBody(w, r, group) {
+		return
+	}
+	group.ID = id
+	if err := group.Validate(); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+	s.deployGroup(w, r, group, http.StatusOK)
+}
+
+// deployGroup deploys the applications of a group, removing the applications of the group it doesn't hold
+//		code:		the status code of the response
+func (s *Server) deployGroup(w http.ResponseWriter, r *http.Request, group *marathon.Group, code int) {
+	groups := make(map[string]bool)
+	applications := make(map[string]*marathon.Application)
+	collectGroup(group, "/", groups, applications)
+	replace := group.Apps != nil || group.Groups != nil
+
+	// step: the applications of the group which are not desired anymore are stopped
+	var removed []*application
+	ids := []string{}
+	for id := range applications {
+		ids = append(ids, id)
+	}
+	if replace {
+		for _, a := range s.sortedApplications() {
+			if _, desired := applications[a.definition.ID]; !desired && strings.HasPrefix(a.definition.ID, group.ID+"/") {
+				removed = append(removed, a)
+				ids = append(ids, a.definition.ID)
+			}
+		}
+	}
+	sort.Strings(ids)
+
+	d := s.newDeployment()
+	if s.lockedBy(w, r, d, ids...) {
+		return
+	}
+	version := s.version()
+	if replace {
+		for id := range s.groups {
+			if strings.HasPrefix(id, group.ID+"/") && !groups[id] {
+				delete(s.groups, id)
+			}
+		}
+	}
+	for id := range groups {
+		s.groups[id] = true
+	}
+	for _, a := range removed {
+		s.stopApplication(d, a)
+	}
+	for _, id := range ids {
+		definition, desired := applications[id]
+		if !desired {
+			continue
+		}
+		var previous *marathon.Application
+		action := "StartApplication"
+		configChange := true
+		if current := s.application(id); current != nil {
+			previous = new(marathon.Application)
+			copyJSON(current.definition, previous)
+			configChange = configChanged(current.definition, definition)
+			action = "RestartApplication"
+			if !configChange {
+				action = "ScaleApplication"
+			}
+			if !configChange && *previous.Instances == instancesOf(definition) {
+				continue
+			}
+		}
+		s.setApplication(definition, version, configChange)
+		d.addApplication(id, action, previous)
+	}
+	s.emit("group_change_success", map[string]interface{}{"groupId": group.ID, "version": version})
+	writeJSON(w, code, s.startDeployment(d, version))
+}
+
+// deleteGroup stops the applications and the pods of a group and removes it
+func (s *Server) deleteGroup(w http.ResponseWriter, r *http.Request, id string) {
+	if !s.groupExists(id) {
+		writeMessage(w, http.StatusNotFound, fmt.Sprintf("Group '%s' does not exist", id))
+		return
+	}
+	members := s.groupMembers(id)
+	d := s.newDeployment()
+	if s.lockedBy(w, r, d, members...) {
+		return
+	}
+
+	version := s.version()
+	for _, member := range members {
+		if a := s.application(member); a != nil {
+			s.stopApplication(d, a)
+		} else if p := s.pod(member); p != nil {
+			s.stopPod(d, p)
+		}
+		delete(s.groups, member)
+	}
+	delete(s.groups, id)
+	s.emit("group_change_success", map[string]interface{}{"groupId": id, "version": version})
+	writeJSON(w, http.StatusOK, s.startDeployment(d, version))
+}
+
+// collectGroup collects the IDs of a group and its subgroups, and their applications with absolute IDs
+//		parent:		the ID of the parent group, which the relative IDs are resolved against
+func collectGroup(group *marathon.Group, parent string, groups map[string]bool, applications map[string]*marathon.Application) {
+	id := resolveID(group.ID, parent)
+	groups[id] = true
+	for _, a := range group.Apps {
+		definition := new(marathon.Application)
+		copyJSON(a, definition)
+		definition.ID = resolveID(a.ID, id)
+		applications[definition.ID] = definition
+	}
+	for _, subgroup := range group.Groups {
+		collectGroup(subgroup, id, groups, applications)
+	}
+}
+
+// instancesOf returns the number of instances of a definition, one when unset
+func instancesOf(definition *marathon.Application) int {
+	if definition.Instances == nil {
+		return 1
+	}
+	return *definition.Instances
+}
@@ -0,0 +1,426 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathontest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	marathon "github.com/gambol99/go-marathon"
+)
+
+// pod is the state of a pod
+type pod struct {
+	definition *marathon.Pod
+	// versions are the definitions of the pod, the oldest first
+	versions  []*marathon.Pod
+	instances []*marathon.PodInstanceStatus
+	// configVersion is the version of the last change of the configuration, besides the scaling
+	configVersion string
+	// deleted marks the pods being stopped by a deployment
+	deleted bool
+}
+
+// instancesCount returns the number of instances of the pod
+func (p *pod) instancesCount() int {
+	if p.definition.Scaling == nil {
+		return 1
+	}
+	return p.definition.Scaling.Instances
+}
+
+// pod returns the pod of the given ID, nil if there is none or if it is being deleted
+func (s *Server) pod(id string) *pod {
+	if p, found := s.pods[id]; found && !p.deleted {
+		return p
+	}
+	return nil
+}
+
+// sortedPods returns the pods which are not being deleted, sorted by ID
+func (s *Server) sortedPods() []*pod {
+	var pods []*pod
+	for _, p := range s.pods {
+		if !p.deleted {
+			pods = append(pods, p)
+		}
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[i].definition.ID < pods[j].definition.ID
+	})
+	return pods
+}
+
+// podView returns a copy of the definition of the pod
+func (s *Server) podView(p *pod) *marathon.Pod {
+	view := new(marathon.Pod)
+	copyJSON(p.definition, view)
+	return view
+}
+
+// podStatus returns the status of the pod, stable once its deployments are done and its instances are running
+func (s *Server) podStatus(p *pod) *marathon.PodStatus {
+	status := &marathon.PodStatus{
+		ID:          p.definition.ID,
+		Spec:        s.podView(p),
+		Status:      marathon.PodStateStable,
+		StatusSince: p.definition.Version,
+		Instances:   []*marathon.PodInstanceStatus{},
+		LastUpdated: s.timestamp(),
+		LastChanged: p.definition.Version,
+	}
+	if len(p.instances) != p.instancesCount() || len(s.lockingDeployments(p.definition.ID)) > 0 {
+		status.Status = marathon.PodStateDegraded
+	}
+	for _, instance := range p.instances {
+		copied := *instance
+		status.Instances = append(status.Instances, &copied)
+	}
+	return status
+}
+
+// setPod records a new version of the definition of a pod
+//		definition:	the definition of the pod
+//		version:	the version of the definition
+func (s *Server) setPod(definition *marathon.Pod, version string) *pod {
+	p, found := s.pods[definition.ID]
+	if !found {
+		p = new(pod)
+		s.pods[definition.ID] = p
+	}
+	if !found || p.deleted || podConfigChanged(p.definition, definition) {
+		p.configVersion = version
+	}
+	p.deleted = false
+
+	definition.Version = version
+	p.definition = definition
+	stored := new(marathon.Pod)
+	copyJSON(definition, stored)
+	p.versions = append(p.versions, stored)
+
+	return p
+}
+
+// convergePod replaces the instances of an outdated configuration and scales the pod, or stops it when it is
+// deleted
+func (s *Server) convergePod(p *pod) {
+	if p.deleted {
+		for len(p.instances) > 0 {
+			s.endInstance(p, p.instances[0])
+		}
+		delete(s.pods, p.definition.ID)
+		return
+	}
+
+	for _, instance := range append([]*marathon.PodInstanceStatus{}, p.instances...) {
+		if instance.SpecReference != podSpecReference(p.definition.ID, p.configVersion) {
+			s.endInstance(p, instance)
+		}
+	}
+	for len(p.instances) > p.instancesCount() {
+		s.endInstance(p, p.instances[len(p.instances)-1])
+	}
+	for len(p.instances) < p.instancesCount() {
+		s.launchInstance(p)
+	}
+}
+
+// launchInstance launches a stable instance of the pod
+func (s *Server) launchInstance(p *pod) {
+	host, agentID := s.placement()
+	instance := &marathon.PodInstanceStatus{
+		ID:            strings.Replace(strings.TrimPrefix(p.definition.ID, "/"), "/", "_", -1) + ".instance-" + newUUID(),
+		AgentHostname: host,
+		Status:        marathon.PodInstanceStateStable,
+		StatusSince:   s.timestamp(),
+		LastUpdated:   s.timestamp(),
+		LastChanged:   s.timestamp(),
+		SpecReference: podSpecReference(p.definition.ID, p.configVersion),
+	}
+	p.instances = append(p.instances, instance)
+	s.emitInstanceChanged(p, instance, agentID, "Running")
+}
+
+// endInstance kills an instance of the pod
+func (s *Server) endInstance(p *pod, instance *marathon.PodInstanceStatus) {
+	for index := range p.instances {
+		if p.instances[index] == instance {
+			p.instances = append(p.instances[:index], p.instances[index+1:]...)
+			break
+		}
+	}
+	instance.Status = marathon.PodInstanceStateTerminal
+	s.emitInstanceChanged(p, instance, "", "Killed")
+}
+
+// emitInstanceChanged publishes the condition of an instance
+func (s *Server) emitInstanceChanged(p *pod, instance *marathon.PodInstanceStatus, agentID, condition string) {
+	s.emit("instance_changed_event", map[string]interface{}{
+		"instanceId":     instance.ID,
+		"condition":      condition,
+		"runSpecId":      p.definition.ID,
+		"agentId":        agentID,
+		"host":           instance.AgentHostname,
+		"runSpecVersion": p.configVersion,
+	})
+}
+
+// handlePods handles the requests of the pods, their status, versions and instances
+func (s *Server) handlePods(w http.ResponseWriter, r *http.Request, path string) {
+	s.Lock()
+	defer s.Unlock()
+
+	if path == "" {
+		switch r.Method {
+		case "HEAD":
+			w.WriteHeader(http.StatusOK)
+		case "GET":
+			pods := []*marathon.Pod{}
+			for _, p := range s.sortedPods() {
+				pods = append(pods, s.podView(p))
+			}
+			writeJSON(w, http.StatusOK, pods)
+		case "POST":
+			s.createPod(w, r)
+		default:
+			writeMessage(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+		return
+	}
+	if path == "/::status" && r.Method == "GET" {
+		statuses := []*marathon.PodStatus{}
+		for _, p := range s.sortedPods() {
+			statuses = append(statuses, s.podStatus(p))
+		}
+		writeJSON(w, http.StatusOK, statuses)
+		return
+	}
+
+	id, resource := path, ""
+	if index := strings.Index(path, "::"); index >= 0 {
+		id, resource = path[:index], path[index+2:]
+	}
+	id = normalizeID(id)
+	if r.Method == "PUT" && resource == "" {
+		s.updatePod(w, r, id)
+		return
+	}
+	p := s.pod(id)
+	if p == nil {
+		writeMessage(w, http.StatusNotFound, fmt.Sprintf("Pod '%s' does not exist", id))
+		return
+	}
+
+	switch {
+	case resource == "" && r.Method == "GET":
+		writeJSON(w, http.StatusOK, s.podView(p))
+	case resource == "" && r.Method == "DELETE":
+		s.deletePod(w, r, p)
+	case resource == "status" && r.Method == "GET":
+		writeJSON(w, http.StatusOK, s.podStatus(p))
+	case resource == "versions" && r.Method == "GET":
+		versions := []string{}
+		for index := len(p.versions) - 1; index >= 0; index-- {
+			versions = append(versions, p.versions[index].Version)
+		}
+		writeJSON(w, http.StatusOK, versions)
+	case strings.HasPrefix(resource, "versions/") && r.Method == "GET":
+		version := strings.TrimPrefix(resource, "versions/")
+		for _, definition := range p.versions {
+			if definition.Version == version {
+				writeJSON(w, http.StatusOK, definition)
+				return
+			}
+		}
+		writeMessage(w, http.StatusNotFound, fmt.Sprintf("Version %s of pod %s not found", version, id))
+	case resource == "instances" && r.Method == "DELETE":
+		var ids []string
+		if !decodeBody(w, r, &ids) {
+			return
+		}
+		writeJSON(w, http.StatusOK, s.killInstances(p, ids))
+	case strings.HasPrefix(resource, "instances/") && r.Method == "DELETE":
+		killed := s.killInstances(p, []string{strings.TrimPrefix(resource, "instances/")})
+		if len(killed) == 0 {
+			writeMessage(w, http.StatusNotFound, fmt.Sprintf("Instance '%s' does not exist", strings.TrimPrefix(resource, "instances/")))
+			return
+		}
+		writeJSON(w, http.StatusOK, killed[0])
+	default:
+		writeMessage(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// createPod creates a pod, failing if it exists already
+func (s *Server) createPod(w http.ResponseWriter, r *http.Request) {
+	definition := new(marathon.Pod)
+	if !decodeBody(w, r, definition) {
+		return
+	}
+	definition.ID = normalizeID(definition.ID)
+	if err := definition.Validate(); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+	if s.pod(definition.ID) != nil {
+		writeMessage(w, http.StatusConflict, fmt.Sprintf("A pod with id [%s] already exists.", definition.ID))
+		return
+	}
+	d := s.newDeployment()
+	if s.lockedBy(w, r, d, definition.ID) {
+		return
+	}
+
+	version := s.version()
+	p := s.setPod(definition, version)
+	d.addPod(definition.ID, "StartPod", nil)
+	s.emit("pod_created_event", map[string]interface{}{"clientIp": r.RemoteAddr, "uri": "/v2/pods" + definition.ID})
+	s.startDeployment(d, version)
+
+	w.Header().Set("Location", "/v2/pods"+definition.ID)
+	w.Header().Set("Marathon-Deployment-Id", d.id)
+	writeJSON(w, http.StatusCreated, s.podView(p))
+}
+
+// updatePod replaces the definition of a pod, or creates it
+func (s *Server) updatePod(w http.ResponseWriter, r *http.Request, id string) {
+	definition := new(marathon.Pod)
+	if !decodeBody(w, r, definition) {
+		return
+	}
+	definition.ID = id
+	if err := definition.Validate(); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+	d := s.newDeployment()
+	if s.lockedBy(w, r, d, id) {
+		return
+	}
+
+	version := s.version()
+	current := s.pod(id)
+	var previous *marathon.Pod
+	action, eventType, code := "StartPod", "pod_created_event", http.StatusCreated
+	if current != nil {
+		previous = new(marathon.Pod)
+		copyJSON(current.definition, previous)
+		action, eventType, code = "ScalePod", "pod_updated_event", http.StatusOK
+		if podConfigChanged(current.definition, definition) {
+			action = "RestartPod"
+		}
+	}
+	p := s.setPod(definition, version)
+	d.addPod(id, action, previous)
+	s.emit(eventType, map[string]interface{}{"clientIp": r.RemoteAddr, "uri": "/v2/pods" + id})
+	s.startDeployment(d, version)
+
+	w.Header().Set("Marathon-Deployment-Id", d.id)
+	writeJSON(w, code, s.podView(p))
+}
+
+// deletePod stops and removes a pod
+func (s *Server) deletePod(w http.ResponseWriter, r *http.Request, p *pod) {
+	d := s.newDeployment()
+	if s.lockedBy(w, r, d, p.definition.ID) {
+		return
+	}
+
+	version := s.version()
+	s.stopPod(d, p)
+	s.emit("pod_deleted_event", map[string]interface{}{"clientIp": r.RemoteAddr, "uri": "/v2/pods" + p.definition.ID})
+	deploymentID := s.startDeployment(d, version)
+
+	w.Header().Set("Marathon-Deployment-Id", d.id)
+	writeJSON(w, http.StatusAccepted, deploymentID)
+}
+
+// stopPod marks a pod as deleted, its instances being killed by the deployment
+func (s *Server) stopPod(d *deployment, p *pod) {
+	previous := new(marathon.Pod)
+	copyJSON(p.definition, previous)
+	p.deleted = true
+	d.addPod(p.definition.ID, "StopPod", previous)
+}
+
+// restorePod restores the definition of a pod in a rollback
+//		previous:	the definition to restore, nil to delete the pod
+func (s *Server) restorePod(d *deployment, id string, previous *marathon.Pod, version string) {
+	p, found := s.pods[id]
+	if previous == nil {
+		if found && !p.deleted {
+			s.stopPod(d, p)
+		}
+		return
+	}
+
+	var current *marathon.Pod
+	action := "StartPod"
+	if found && !p.deleted {
+		current = new(marathon.Pod)
+		copyJSON(p.definition, current)
+		action = "RestartPod"
+	}
+	definition := new(marathon.Pod)
+	copyJSON(previous, definition)
+	s.setPod(definition, version)
+	d.addPod(id, action, current)
+}
+
+// killInstances kills instances of a pod, which are replaced, returning them as Marathon does
+func (s *Server) killInstances(p *pod, ids []string) []map[string]interface{} {
+	killed := []map[string]interface{}{}
+	for _, id := range ids {
+		for _, instance := range p.instances {
+			if instance.ID != id {
+				continue
+			}
+			s.endInstance(p, instance)
+			s.launchInstance(p)
+			killed = append(killed, map[string]interface{}{
+				"instanceId": map[string]string{"idString": instance.ID},
+				"agentInfo":  map[string]interface{}{"host": instance.AgentHostname, "attributes": []string{}},
+				"state":      map[string]interface{}{"condition": map[string]string{"str": "Killed"}},
+			})
+			break
+		}
+	}
+	return killed
+}
+
+// podConfigChanged checks if the configuration of a pod differs, besides its scaling
+func podConfigChanged(a, b *marathon.Pod) bool {
+	configuration := func(definition *marathon.Pod) []byte {
+		copied := new(marathon.Pod)
+		copyJSON(definition, copied)
+		copied.Scaling = nil
+		copied.Version = ""
+		content, _ := json.Marshal(copied)
+		return content
+	}
+	return !bytes.Equal(configuration(a), configuration(b))
+}
+
+// podSpecReference returns the reference of a version of a pod, as in the status of its instances
+func podSpecReference(id, version string) string {
+	return fmt.Sprintf("/v2/pods%s::versions/%s", id, version)
+}
@@ -0,0 +1,317 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package marathontest provides an in-memory Marathon server for the integration tests of the services using
+// go-marathon. Unlike a recording of responses, the server keeps the state of the applications, groups, pods,
+// tasks and deployments it is sent, assigns them versions, locks the run specs being deployed with 409 Conflict
+// errors and publishes the events of the changes on the SSE event stream and to the callback subscriptions.
+//
+// The deployments progress over a simulated time: they complete once the clock of the server has been advanced
+// by the DeploymentDuration of the options, at once when it is zero.
+//
+//	server := marathontest.NewServer(&marathontest.Options{DeploymentDuration: time.Minute})
+//	defer server.Close()
+//
+//	config := marathon.NewDefaultConfig()
+//	config.URL = server.URL()
+//	client, err := marathon.NewClient(config)
+//	...
+//	server.Advance(time.Minute)
+package marathontest
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	marathon "github.com/gambol99/go-marathon"
+)
+
+// timestampLayout is the layout of the timestamps and the versions of Marathon
+const timestampLayout = "2006-01-02T15:04:05.000Z"
+
+// Options are the options of the server
+type Options struct {
+	// DeploymentDuration is the simulated time the deployments take, zero completing them at once
+	DeploymentDuration time.Duration
+	// Hosts are the hostnames of the agents the tasks are spread over, defaults to a single "agent-1"
+	Hosts []string
+	// Start is the initial simulated time, defaults to the current time
+	Start time.Time
+}
+
+// Server is an in-memory Marathon server
+type Server struct {
+	sync.Mutex
+	options Options
+	server  *httptest.Server
+	// the simulated time and the last version assigned
+	now         time.Time
+	lastVersion time.Time
+	// the state of the run specs
+	applications map[string]*application
+	pods         map[string]*pod
+	groups       map[string]bool
+	deployments  []*deployment
+	// the counters of the task ports and the placements
+	nextPort      int
+	nextPlacement int
+	// the event streams and the callback subscriptions
+	streams       map[*eventStream]bool
+	subscriptions []string
+	callbacks     *callbackQueue
+	done          chan struct{}
+}
+
+// NewServer creates and starts an in-memory Marathon server, to be closed once done with
+//		options:	the options of the server, nil for the defaults
+func NewServer(options *Options) *Server {
+	if options == nil {
+		options = &Options{}
+	}
+	s := &Server{
+		options:      *options,
+		now:          options.Start,
+		applications: make(map[string]*application),
+		pods:         make(map[string]*pod),
+		groups:       make(map[string]bool),
+		nextPort:     31000,
+		streams:      make(map[*eventStream]bool),
+		done:         make(chan struct{}),
+	}
+	if len(s.options.Hosts) == 0 {
+		s.options.Hosts = []string{"agent-1"}
+	}
+	if s.now.IsZero() {
+		s.now = time.Now()
+	}
+	s.now = s.now.UTC().Truncate(time.Millisecond)
+	s.callbacks = newCallbackQueue(s.done)
+	s.server = httptest.NewServer(s)
+
+	return s
+}
+
+// URL returns the URL of the server, to be set in the configuration of the client
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// Close terminates the event streams and shuts the server down
+func (s *Server) Close() {
+	s.Lock()
+	select {
+	case <-s.done:
+		s.Unlock()
+		return
+	default:
+		close(s.done)
+	}
+	s.Unlock()
+
+	s.server.CloseClientConnections()
+	s.server.Close()
+}
+
+// Now returns the simulated time of the server
+func (s *Server) Now() time.Time {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.now
+}
+
+// Advance moves the simulated time forward, completing the deployments which are due
+//		duration:	the time to move forward by
+func (s *Server) Advance(duration time.Duration) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.now = s.now.Add(duration)
+	s.progress()
+}
+
+// ServeHTTP dispatches the requests to the handlers of the resources
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	switch {
+	case path == "/ping":
+		w.Write([]byte("pong"))
+	case path == "/v2/events":
+		s.handleEvents(w, r)
+	case path == "/v2/eventSubscriptions":
+		s.handleSubscriptions(w, r)
+	case path == "/v2/info":
+		s.handleInfo(w, r)
+	case path == "/v2/leader":
+		s.handleLeader(w, r)
+	case path == "/v2/apps" || strings.HasPrefix(path, "/v2/apps/"):
+		s.handleApplications(w, r, strings.TrimPrefix(path, "/v2/apps"))
+	case path == "/v2/groups" || strings.HasPrefix(path, "/v2/groups/"):
+		s.handleGroups(w, r, strings.TrimPrefix(path, "/v2/groups"))
+	case path == "/v2/pods" || strings.HasPrefix(path, "/v2/pods/"):
+		s.handlePods(w, r, strings.TrimPrefix(path, "/v2/pods"))
+	case path == "/v2/tasks" || path == "/v2/tasks/delete":
+		s.handleTasks(w, r, strings.TrimPrefix(path, "/v2/tasks"))
+	case path == "/v2/deployments" || strings.HasPrefix(path, "/v2/deployments/"):
+		s.handleDeployments(w, r, strings.TrimPrefix(path, "/v2/deployments"))
+	case path == "/v2/queue":
+		writeJSON(w, http.StatusOK, map[string]interface{}{"queue": []interface{}{}})
+	case strings.HasPrefix(path, "/v2/queue/") && strings.HasSuffix(path, "/delay"):
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeMessage(w, http.StatusNotFound, fmt.Sprintf("The requested resource %s could not be found", path))
+	}
+}
+
+// handleInfo returns the information of the server
+func (s *Server) handleInfo(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	info := new(marathon.Info)
+	info.Name = "marathon"
+	info.Version = "1.5.0"
+	info.FrameworkID = "marathontest"
+	info.Leader = r.Host
+	info.EventSubscriber.Type = "http_callback"
+	info.EventSubscriber.HTTPEndpoints = append([]string{}, s.subscriptions...)
+	info.MarathonConfig.Hostname = r.Host
+	writeJSON(w, http.StatusOK, info)
+}
+
+// handleLeader returns the leader, the server itself
+func (s *Server) handleLeader(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		writeJSON(w, http.StatusOK, map[string]string{"leader": r.Host})
+	case "DELETE":
+		writeMessage(w, http.StatusOK, "Leadership abdicated")
+	default:
+		writeMessage(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// version returns a new version, strictly after the previous ones even when the simulated time has not moved
+func (s *Server) version() string {
+	version := s.now
+	if !version.After(s.lastVersion) {
+		version = s.lastVersion.Add(time.Millisecond)
+	}
+	s.lastVersion = version
+
+	return version.Format(timestampLayout)
+}
+
+// timestamp returns the simulated time in the format of Marathon
+func (s *Server) timestamp() string {
+	return s.now.Format(timestampLayout)
+}
+
+// placement returns the host and the agent ID of a new task or instance, spreading them over the hosts
+func (s *Server) placement() (string, string) {
+	index := s.nextPlacement % len(s.options.Hosts)
+	s.nextPlacement++
+
+	return s.options.Hosts[index], fmt.Sprintf("agent-%d-S0", index+1)
+}
+
+// normalizeID returns the absolute form of an ID, without a trailing slash
+func normalizeID(id string) string {
+	return "/" + strings.Trim(id, "/")
+}
+
+// resolveID resolves an ID relative to a group
+func resolveID(id, group string) string {
+	if strings.HasPrefix(id, "/") {
+		return normalizeID(id)
+	}
+	if group == "/" {
+		return normalizeID(id)
+	}
+
+	return normalizeID(group + "/" + id)
+}
+
+// isForced checks if the request overrides the deployments locking the run specs
+func isForced(r *http.Request) bool {
+	return r.URL.Query().Get("force") == "true"
+}
+
+// newUUID returns a random version 4 UUID
+func newUUID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// writeJSON writes the JSON encoding of the value as the response
+func writeJSON(w http.ResponseWriter, code int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(value)
+}
+
+// writeMessage writes an error, or a plain message, as Marathon does
+func writeMessage(w http.ResponseWriter, code int, message string) {
+	writeJSON(w, code, map[string]string{"message": message})
+}
+
+// decodeBody decodes the JSON body of the request, writing a 400 Bad Request error on failure
+func decodeBody(w http.ResponseWriter, r *http.Request, value interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(value); err != nil {
+		writeMessage(w, http.StatusBadRequest, fmt.Sprintf("Invalid JSON: %s", err))
+		return false
+	}
+
+	return true
+}
+
+// writeValidationError writes the violations of an invalid definition as a 422 Unprocessable Entity error
+func writeValidationError(w http.ResponseWriter, err error) {
+	validationErr, ok := err.(*marathon.ValidationError)
+	if !ok {
+		writeMessage(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	details := make([]map[string]interface{}, 0, len(validationErr.Violations))
+	for _, violation := range validationErr.Violations {
+		details = append(details, map[string]interface{}{"path": violation.Path, "errors": violation.Errors})
+	}
+	writeJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+		"message": validationErr.Message,
+		"details": details,
+	})
+}
+
+// copyJSON deep copies a definition through its JSON encoding
+func copyJSON(from, to interface{}) {
+	content, err := json.Marshal(from)
+	if err != nil {
+		panic(fmt.Sprintf("failed to encode a definition: %s", err))
+	}
+	if err := json.Unmarshal(content, to); err != nil {
+		panic(fmt.Sprintf("failed to decode a definition: %s", err))
+	}
+}
@@ -0,0 +1,310 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathontest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	marathon "github.com/gambol99/go-marathon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient creates a server and a client of it
+func newTestClient(t *testing.T, options *Options, transport marathon.EventsTransport) (*Server, marathon.Marathon) {
+	server := NewServer(options)
+	config := marathon.NewDefaultConfig()
+	config.URL = server.URL()
+	config.EventsTransport = transport
+	client, err := marathon.NewClient(config)
+	require.NoError(t, err)
+
+	return server, client
+}
+
+// newTestApplication returns the definition of an application
+func newTestApplication(id string, instances int) *marathon.Application {
+	return new(marathon.Application).Name(id).Command("sleep 60").Count(instances).CPU(0.1).Memory(32)
+}
+
+// waitEvent waits for an event of the given type
+func waitEvent(t *testing.T, events marathon.EventsChannel, name string) *marathon.Event {
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event := <-events:
+			if event.Name == name {
+				return event
+			}
+		case <-timeout:
+			require.FailNow(t, "timed out waiting for the event", name)
+		}
+	}
+}
+
+func TestApplicationLifecycle(t *testing.T) {
+	server, client := newTestClient(t, &Options{DeploymentDuration: time.Minute, Hosts: []string{"a", "b"}}, marathon.EventsTransportCallback)
+	defer server.Close()
+
+	created, err := client.CreateApplication(newTestApplication("/prod/web", 2))
+	require.NoError(t, err)
+	assert.Equal(t, "/prod/web", created.ID)
+	assert.Len(t, created.Deployments, 1)
+
+	// step: the application is locked until the deployment completes
+	_, err = client.ScaleApplicationInstances("/prod/web", 3, false)
+	require.Error(t, err)
+	apiErr, ok := err.(*marathon.APIError)
+	require.True(t, ok)
+	assert.Equal(t, marathon.ErrCodeAppLocked, apiErr.ErrCode)
+	assert.Contains(t, err.Error(), created.Deployments[0]["id"])
+
+	deployments, err := client.Deployments()
+	require.NoError(t, err)
+	require.Len(t, deployments, 1)
+	assert.Equal(t, []string{"/prod/web"}, deployments[0].AffectedApps)
+	assert.Equal(t, "StartApplication", deployments[0].Steps[0][0].Action)
+
+	server.Advance(time.Minute)
+	application, err := client.Application("/prod/web")
+	require.NoError(t, err)
+	assert.True(t, application.AllTaskRunning())
+	assert.Len(t, application.Deployments, 0)
+	require.Len(t, application.Tasks, 2)
+	assert.Equal(t, "a", application.Tasks[0].Host)
+	assert.Equal(t, "b", application.Tasks[1].Host)
+	assert.NoError(t, client.WaitOnApplication("/prod/web", time.Second))
+
+	// step: a scaling keeps the tasks, while a change of the configuration replaces them
+	deploymentID, err := client.ScaleApplicationInstances("/prod/web", 3, false)
+	require.NoError(t, err)
+	server.Advance(time.Minute)
+	found, err := client.HasDeployment(deploymentID.DeploymentID)
+	require.NoError(t, err)
+	assert.False(t, found)
+	tasks, err := client.Tasks("/prod/web")
+	require.NoError(t, err)
+	require.Len(t, tasks.Tasks, 3)
+	assert.Equal(t, application.Tasks[0].ID, tasks.Tasks[0].ID)
+
+	_, err = client.UpdateApplication(newTestApplication("/prod/web", 3).Command("sleep 120"), false)
+	require.NoError(t, err)
+	server.Advance(time.Minute)
+	tasks, err = client.Tasks("/prod/web")
+	require.NoError(t, err)
+	require.Len(t, tasks.Tasks, 3)
+	assert.NotEqual(t, application.Tasks[0].ID, tasks.Tasks[0].ID)
+
+	versions, err := client.ApplicationVersions("/prod/web")
+	require.NoError(t, err)
+	assert.Len(t, versions.Versions, 3)
+	previous, err := client.ApplicationByVersion("/prod/web", versions.Versions[2])
+	require.NoError(t, err)
+	assert.Equal(t, "sleep 60", *previous.Cmd)
+
+	// step: a forced update overrides the locking deployment
+	_, err = client.UpdateApplication(newTestApplication("/prod/web", 1), false)
+	require.NoError(t, err)
+	_, err = client.UpdateApplication(newTestApplication("/prod/web", 2), true)
+	require.NoError(t, err)
+	deployments, err = client.Deployments()
+	require.NoError(t, err)
+	assert.Len(t, deployments, 1)
+
+	server.Advance(time.Minute)
+	_, err = client.DeleteApplication("/prod/web", false)
+	require.NoError(t, err)
+	_, err = client.Application("/prod/web")
+	assert.Error(t, err)
+	server.Advance(time.Minute)
+	applications, err := client.Applications(nil)
+	require.NoError(t, err)
+	assert.Len(t, applications.Apps, 0)
+}
+
+func TestApplicationValidation(t *testing.T) {
+	server, client := newTestClient(t, nil, marathon.EventsTransportCallback)
+	defer server.Close()
+
+	_, err := client.CreateApplication(new(marathon.Application).Name("/invalid"))
+	require.Error(t, err)
+	apiErr, ok := err.(*marathon.APIError)
+	require.True(t, ok)
+	assert.Equal(t, marathon.ErrCodeInvalidBean, apiErr.ErrCode)
+
+	_, err = client.CreateApplication(newTestApplication("/app", 1))
+	require.NoError(t, err)
+	_, err = client.CreateApplication(newTestApplication("/app", 1))
+	require.Error(t, err)
+	assert.Equal(t, marathon.ErrCodeDuplicateID, err.(*marathon.APIError).ErrCode)
+}
+
+func TestDeploymentRollback(t *testing.T) {
+	server, client := newTestClient(t, &Options{DeploymentDuration: time.Minute}, marathon.EventsTransportCallback)
+	defer server.Close()
+
+	_, err := client.CreateApplication(newTestApplication("/app", 1))
+	require.NoError(t, err)
+	server.Advance(time.Minute)
+
+	deploymentID, err := client.UpdateApplication(newTestApplication("/app", 1).Command("sleep 120"), false)
+	require.NoError(t, err)
+	rollback, err := client.DeleteDeployment(deploymentID.DeploymentID, false)
+	require.NoError(t, err)
+	assert.NotEqual(t, deploymentID.DeploymentID, rollback.DeploymentID)
+
+	application, err := client.Application("/app")
+	require.NoError(t, err)
+	assert.Equal(t, "sleep 60", *application.Cmd)
+	server.Advance(time.Minute)
+	assert.Len(t, server.Application("/app").Tasks, 1)
+
+	// step: the failed tasks are replaced
+	taskID := server.Application("/app").Tasks[0].ID
+	require.NoError(t, server.FailTask(taskID, "exited"))
+	application = server.Application("/app")
+	require.Len(t, application.Tasks, 1)
+	assert.NotEqual(t, taskID, application.Tasks[0].ID)
+	assert.Equal(t, "exited", application.LastTaskFailure.Message)
+	assert.Error(t, server.FailTask("missing", "exited"))
+}
+
+func TestGroups(t *testing.T) {
+	server, client := newTestClient(t, nil, marathon.EventsTransportCallback)
+	defer server.Close()
+
+	group := marathon.NewApplicationGroup("/prod")
+	group.App(newTestApplication("/prod/web", 1))
+	group.Groups = []*marathon.Group{{ID: "db", Apps: []*marathon.Application{newTestApplication("/prod/db/primary", 1)}}}
+	require.NoError(t, client.CreateGroup(group))
+
+	groups, err := client.Groups()
+	require.NoError(t, err)
+	require.Len(t, groups.Groups, 1)
+	assert.Equal(t, "/prod", groups.Groups[0].ID)
+	assert.Equal(t, "/prod/web", groups.Groups[0].Apps[0].ID)
+	assert.Equal(t, "/prod/db/primary", groups.Groups[0].Groups[0].Apps[0].ID)
+	assert.Error(t, client.CreateGroup(group))
+
+	// step: the applications missing from an updated group are removed
+	_, err = client.UpdateGroup("/prod", &marathon.Group{ID: "/prod", Apps: []*marathon.Application{newTestApplication("/prod/web", 2)}}, false)
+	require.NoError(t, err)
+	applications, err := client.Applications(nil)
+	require.NoError(t, err)
+	require.Len(t, applications.Apps, 1)
+	assert.Equal(t, 2, applications.Apps[0].TasksRunning)
+
+	_, err = client.DeleteGroup("/prod", false)
+	require.NoError(t, err)
+	_, err = client.Group("/prod")
+	assert.Error(t, err)
+}
+
+func TestPods(t *testing.T) {
+	server, client := newTestClient(t, &Options{DeploymentDuration: time.Minute}, marathon.EventsTransportCallback)
+	defer server.Close()
+
+	pod := marathon.NewPod().Name("/pod").Count(2).
+		AddContainer(marathon.NewPodContainer().SetName("web").CPUs(0.1).Memory(32))
+	created, err := client.CreatePod(pod)
+	require.NoError(t, err)
+	assert.NotEmpty(t, created.Version)
+	assert.False(t, client.PodExistsAndRunning("/pod"))
+	_, err = client.UpdatePod(pod.Count(3), false)
+	assert.Error(t, err)
+
+	server.Advance(time.Minute)
+	status, err := client.GetPodStatus("/pod")
+	require.NoError(t, err)
+	assert.Equal(t, marathon.PodStateStable, status.Status)
+	require.Len(t, status.Instances, 2)
+
+	killed, err := client.DeletePodInstance("/pod", status.Instances[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, status.Instances[0].ID, killed.InstanceID.ID)
+
+	_, err = client.DeletePod("/pod", false)
+	require.NoError(t, err)
+	server.Advance(time.Minute)
+	pods, err := client.GetAllPods()
+	require.NoError(t, err)
+	assert.Len(t, pods, 0)
+}
+
+func TestEventStream(t *testing.T) {
+	server, client := newTestClient(t, nil, marathon.EventsTransportSSE)
+	defer server.Close()
+	defer client.Close()
+
+	events, err := client.AddEventsListener(marathon.EventIDStreamAttached | marathon.EventIDDeploymentSuccess |
+		marathon.EventIDStatusUpdate | marathon.EventIDAPIRequest)
+	require.NoError(t, err)
+	waitEvent(t, events, "event_stream_attached")
+
+	_, err = client.CreateApplication(newTestApplication("/app", 1))
+	require.NoError(t, err)
+	event := waitEvent(t, events, "api_post_event")
+	assert.Equal(t, "/app", event.Event.(*marathon.EventAPIRequest).AppDefinition.ID)
+	event = waitEvent(t, events, "status_update_event")
+	assert.Equal(t, "TASK_RUNNING", event.Event.(*marathon.EventStatusUpdate).TaskStatus)
+	event = waitEvent(t, events, "deployment_success")
+	assert.Equal(t, "/app", event.Event.(*marathon.EventDeploymentSuccess).Plan.Steps[0].Actions[0].App)
+}
+
+func TestCallbackSubscriptions(t *testing.T) {
+	server, client := newTestClient(t, nil, marathon.EventsTransportCallback)
+	defer server.Close()
+
+	received := make(chan string, 100)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, _ := ioutil.ReadAll(r.Body)
+		var event marathon.EventType
+		json.Unmarshal(content, &event)
+		received <- event.EventType
+	}))
+	defer receiver.Close()
+
+	require.NoError(t, client.Subscribe(receiver.URL))
+	subscriptions, err := client.Subscriptions()
+	require.NoError(t, err)
+	assert.Equal(t, []string{receiver.URL}, subscriptions.CallbackURLs)
+
+	_, err = client.CreateApplication(newTestApplication("/app", 1))
+	require.NoError(t, err)
+	var types []string
+	timeout := time.After(5 * time.Second)
+	for len(types) == 0 || types[len(types)-1] != "deployment_success" {
+		select {
+		case eventType := <-received:
+			types = append(types, eventType)
+		case <-timeout:
+			require.FailNow(t, "timed out waiting for the events", "%v", types)
+		}
+	}
+	assert.Equal(t, []string{"subscribe_event", "api_post_event", "group_change_success", "deployment_info",
+		"status_update_event", "deployment_step_success", "deployment_success"}, types)
+
+	require.NoError(t, client.Unsubscribe(receiver.URL))
+	subscriptions, err = client.Subscriptions()
+	require.NoError(t, err)
+	assert.Empty(t, subscriptions.CallbackURLs)
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Metrics receives the measurements of the client, e.g. to record them as OpenTelemetry spans or Prometheus
+// metrics. The methods are called synchronously and concurrently, so they have to be cheap and safe for
+// concurrent use.
+type Metrics interface {
+	// StartAPICall is called when an API call starts. The returned context is the one the requests of the call
+	// are sent with, e.g. to propagate the span to Marathon through an interceptor.
+	//		ctx:		the context of the call
+	//		method:		the HTTP method of the call
+	//		path:		the path template of the call, e.g. "v2/apps/{id}/tasks"
+	StartAPICall(ctx context.Context, method, path string) (context.Context, APICallSpan)
+	// ObserveRequest is called for each request sent to a member, i.e. for each attempt of an API call
+	ObserveRequest(request APICallInfo)
+	// MemberDown is called when a member is marked down
+	MemberDown(member string)
+	// EventsStreamReconnected is called when the SSE event stream has been reconnected
+	EventsStreamReconnected()
+	// EventDispatched is called when an event has been queued for its listeners
+	//		eventType:	the type of the event, e.g. "status_update_event"
+	//		listeners:	the number of listeners the event was queued for
+	//		duration:	the time the queueing took, which grows with blocking listeners
+	EventDispatched(eventType string, listeners int, duration time.Duration)
+}
+
+// APICallSpan is the span of an API call
+type APICallSpan interface {
+	// End is called once the API call has completed
+	End(call APICallInfo)
+}
+
+// APICallInfo describes an API call, or one of its requests
+type APICallInfo struct {
+	// Method is the HTTP method
+	Method string
+	// Path is the path template, e.g. "v2/apps/{id}/tasks"
+	Path string
+	// Member is the member the (last) request was sent to, empty when none was available
+	Member string
+	// StatusCode is the status of the (last) response, zero when none was received
+	StatusCode int
+	// Retries is the number of requests sent before the (last) one
+	Retries int
+	// Duration is the time the call or the request took
+	Duration time.Duration
+	// Err is the error the call or the request failed with, if any
+	Err error
+	// Cached indicates that the call was served from the cache, possibly after revalidating it
+	Cached bool
+}
+
+// nopMetrics is used when no metrics are configured
+type nopMetrics struct{}
+
+func (nopMetrics) StartAPICall(ctx context.Context, method, path string) (context.Context, APICallSpan) {
+	return ctx, nopMetrics{}
+}
+
+func (nopMetrics) ObserveRequest(request APICallInfo) {}
+
+func (nopMetrics) MemberDown(member string) {}
+
+func (nopMetrics) EventsStreamReconnected() {}
+
+func (nopMetrics) EventDispatched(eventType string, listeners int, duration time.Duration) {}
+
+func (nopMetrics) End(call APICallInfo) {}
+
+// metrics returns the metrics of the configuration, or the no-op metrics if none are configured
+func (rc *httpClient) metrics() Metrics {
+	if rc.config.Metrics == nil {
+		return nopMetrics{}
+	}
+	return rc.config.Metrics
+}
+
+// apiSubresource is a resource nested in a resource of the API, with its own ID parameter if any
+type apiSubresource struct {
+	name      string
+	parameter string
+}
+
+// apiResources lists the resources of the API whose paths contain IDs, with their nested resources
+var apiResources = []struct {
+	prefix       string
+	subresources []apiSubresource
+}{
+	{marathonAPIApps + "/", []apiSubresource{{"/tasks", "{taskId}"}, {"/versions", "{version}"}, {"/restart", ""}}},
+	{marathonAPIGroups + "/", []apiSubresource{{"/versions", "{version}"}}},
+	{marathonAPIPods + "/", []apiSubresource{{"::status", ""}, {"::instances", "{instanceId}"}, {"::versions", "{version}"}}},
+	{marathonAPIDeployments + "/", nil},
+	{marathonAPIQueue + "/", []apiSubresource{{"/delay", ""}}},
+}
+
+// pathTemplate replaces the IDs in the path of an API call by placeholders and removes its query, e.g.
+// "v2/apps/prod/web/tasks/web.1?scale=true" becomes "v2/apps/{id}/tasks/{taskId}"
+//		path:		the path of the API call
+func pathTemplate(path string) string {
+	path = strings.SplitN(path, "?", 2)[0]
+	for _, resource := range apiResources {
+		if !strings.HasPrefix(path, resource.prefix) || path == resource.prefix {
+			continue
+		}
+		rest := strings.TrimPrefix(path, resource.prefix)
+
+		template := resource.prefix + "{id}"
+		for _, subresource := range resource.subresources {
+			index := strings.Index(rest, subresource.name)
+			end := index + len(subresource.name)
+			if index < 0 || (end < len(rest) && rest[end] != '/') {
+				continue
+			}
+			if index == 0 {
+				template = resource.prefix
+			}
+			template += subresource.name
+			if end < len(rest) && subresource.parameter != "" {
+				template += "/" + subresource.parameter
+			}
+			break
+		}
+		return template
+	}
+	return path
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type spanContextKey struct{}
+
+// recordingMetrics records the measurements of a client
+type recordingMetrics struct {
+	sync.Mutex
+	calls      []APICallInfo
+	requests   []APICallInfo
+	down       []string
+	reconnects int
+	dispatched map[string]int
+}
+
+type recordingSpan struct {
+	metrics *recordingMetrics
+}
+
+func (m *recordingMetrics) StartAPICall(ctx context.Context, method, path string) (context.Context, APICallSpan) {
+	return context.WithValue(ctx, spanContextKey{}, method+" "+path), &recordingSpan{metrics: m}
+}
+
+func (m *recordingMetrics) ObserveRequest(request APICallInfo) {
+	m.Lock()
+	defer m.Unlock()
+	m.requests = append(m.requests, request)
+}
+
+func (m *recordingMetrics) MemberDown(member string) {
+	m.Lock()
+	defer m.Unlock()
+	m.down = append(m.down, member)
+}
+
+func (m *recordingMetrics) EventsStreamReconnected() {
+	m.Lock()
+	defer m.Unlock()
+	m.reconnects++
+}
+
+func (m *recordingMetrics) EventDispatched(eventType string, listeners int, duration time.Duration) {
+	m.Lock()
+	defer m.Unlock()
+	if m.dispatched == nil {
+		m.dispatched = make(map[string]int)
+	}
+	m.dispatched[eventType] += listeners
+}
+
+func (s *recordingSpan) End(call APICallInfo) {
+	s.metrics.Lock()
+	defer s.metrics.Unlock()
+	s.metrics.calls = append(s.metrics.calls, call)
+}
+
+func TestMetricsAPICall(t *testing.T) {
+	metrics := &recordingMetrics{}
+	var spans []interface{}
+	var spansLock sync.Mutex
+
+	config := NewDefaultConfig()
+	config.RetryPolicy = NewDefaultRetryPolicy()
+	config.RetryPolicy.InitialBackoff = 0
+	config.Metrics = metrics
+	config.Interceptors = []Interceptor{
+		func(next RoundTripFunc) RoundTripFunc {
+			return func(request *http.Request) (*http.Response, error) {
+				// step: the health checks of the members are not part of any call
+				if span := request.Context().Value(spanContextKey{}); span != nil {
+					spansLock.Lock()
+					spans = append(spans, span)
+					spansLock.Unlock()
+				}
+				return next(request)
+			}
+		},
+	}
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{
+		client: &config,
+		server: &serverConfig{scope: "retry"},
+	})
+	defer endpoint.Close()
+
+	_, err := endpoint.Client.Application(fakeAppName)
+	require.NoError(t, err)
+
+	metrics.Lock()
+	defer metrics.Unlock()
+	require.Len(t, metrics.calls, 1)
+	call := metrics.calls[0]
+	assert.Equal(t, "GET", call.Method)
+	assert.Equal(t, "v2/apps/{id}", call.Path)
+	assert.Equal(t, endpoint.Server.httpSrv.URL, call.Member)
+	assert.Equal(t, 200, call.StatusCode)
+	assert.Equal(t, 1, call.Retries)
+	assert.NoError(t, call.Err)
+
+	require.Len(t, metrics.requests, 2)
+	assert.Equal(t, 503, metrics.requests[0].StatusCode)
+	assert.Equal(t, 0, metrics.requests[0].Retries)
+	assert.Equal(t, 200, metrics.requests[1].StatusCode)
+	assert.Equal(t, []string{endpoint.Server.httpSrv.URL}, metrics.down)
+	spansLock.Lock()
+	defer spansLock.Unlock()
+	assert.Equal(t, []interface{}{"GET v2/apps/{id}", "GET v2/apps/{id}"}, spans)
+}
+
+func TestMetricsEventDispatch(t *testing.T) {
+	metrics := &recordingMetrics{}
+	config := NewDefaultConfig()
+	config.EventsTransport = EventsTransportSSE
+	config.Metrics = metrics
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config})
+	defer endpoint.Close()
+
+	events, err := endpoint.Client.AddEventsListener(EventIDStatusUpdate)
+	require.NoError(t, err)
+	defer endpoint.Client.RemoveEventsListener(events)
+
+	time.Sleep(SSEConnectWaitTime)
+	endpoint.Server.PublishEvent(testCases.find("status_update_event").source)
+	select {
+	case <-events:
+	case <-time.After(eventPublishTimeout):
+		require.Fail(t, "did not receive event in time")
+	}
+
+	metrics.Lock()
+	defer metrics.Unlock()
+	assert.Equal(t, 1, metrics.dispatched["status_update_event"])
+}
+
+func TestPathTemplate(t *testing.T) {
+	cases := map[string]string{
+		"v2/apps":                                         "v2/apps",
+		"v2/apps?embed=apps.tasks":                        "v2/apps",
+		"v2/apps/prod/web":                                "v2/apps/{id}",
+		"v2/apps/prod/web?force=true":                     "v2/apps/{id}",
+		"v2/apps/prod/web/tasks":                          "v2/apps/{id}/tasks",
+		"v2/apps/prod/web/tasks/prod_web.1234?scale=true": "v2/apps/{id}/tasks/{taskId}",
+		"v2/apps/prod/web/versions/2014-08-18T22:36:41Z":  "v2/apps/{id}/versions/{version}",
+		"v2/apps/prod/web/restart":                        "v2/apps/{id}/restart",
+		"v2/apps/prod/tasksrunner":                        "v2/apps/{id}",
+		"v2/groups/prod":                                  "v2/groups/{id}",
+		"v2/pods/web::status":                             "v2/pods/{id}::status",
+		"v2/pods/::status":                                "v2/pods/::status",
+		"v2/pods/web::instances/web.instance-1":           "v2/pods/{id}::instances/{instanceId}",
+		"v2/pods/web::versions":                           "v2/pods/{id}::versions",
+		"v2/deployments/867ed450":                         "v2/deployments/{id}",
+		"v2/queue/prod/web/delay":                         "v2/queue/{id}/delay",
+		"v2/tasks/delete":                                 "v2/tasks/delete",
+		"ping":                                            "ping",
+	}
+	for path, template := range cases {
+		assert.Equal(t, template, pathTemplate(path), path)
+	}
+}
@@ -256,7 +256,7 @@ func (r *marathonClient) DeletePod(name string, force bool) (*DeploymentID, erro
 	uri := fmt.Sprintf("%s?force=%v", buildPodURI(name), force)
 	// step: check of the pod already exists
 	deployID := new(DeploymentID)
-	if err := r.apiDelete(uri, nil, deployID); err != nil {
+	if err := r.resolveConflicts(func() error { return r.apiDelete(uri, nil, deployID) }); err != nil {
 		return nil, err
 	}
 
@@ -269,7 +269,7 @@ func (r *marathonClient) UpdatePod(pod *Pod, force bool) (*Pod, error) {
 	uri := fmt.Sprintf("%s?force=%v", buildPodURI(pod.ID), force)
 	result := new(Pod)
 	// step: check of the pod already exists
-	if err := r.apiPut(uri, pod, result); err != nil {
+	if err := r.resolveConflicts(func() error { return r.apiPut(uri, pod, result) }); err != nil {
 		return nil, err
 	}
 
@@ -60,6 +60,14 @@ func (r *PodPlacement) AddConstraint(constraints ...string) *PodPlacement {
 	return r
 }
 
+// AddConstraints adds typed constraints, e.g. Unique("hostname") or GroupBy("rack_id", 3)
+//		constraints:	the constraints to add
+func (r *PodPlacement) AddConstraints(constraints ...Constraint) *PodPlacement {
+	r.Constraints = appendConstraints(r.Constraints, constraints)
+
+	return r
+}
+
 // NewPodSchedulingPolicy creates an empty PodSchedulingPolicy
 func NewPodSchedulingPolicy() *PodSchedulingPolicy {
 	return &PodSchedulingPolicy{
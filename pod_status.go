@@ -102,24 +102,9 @@ func (r *marathonClient) GetAllPodStatus() ([]*PodStatus, error) {
 //		name:		the id of the pod
 //		timeout:	a duration of time to wait for an pod to deploy
 func (r *marathonClient) WaitOnPod(name string, timeout time.Duration) error {
-	if r.PodExistsAndRunning(name) {
-		return nil
-	}
-
-	timeoutTimer := time.After(timeout)
-	ticker := time.NewTicker(r.config.PollingWaitTime)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-timeoutTimer:
-			return ErrTimeoutError
-		case <-ticker.C:
-			if r.PodExistsAndRunning(name) {
-				return nil
-			}
-		}
-	}
+	return r.waitUntil(timeout, watchRunSpecs(matchID(name)), func(client *marathonClient) (bool, error) {
+		return client.PodExistsAndRunning(name), nil
+	})
 }
 
 // PodExistsAndRunning returns whether the pod is stably running
@@ -0,0 +1,145 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy defines how API calls which failed on a cluster member, either on the transport level or with
+// a 5xx response, are retried on the remaining members. Without a policy, failed calls are retried immediately
+// and regardless of their method until all the members are marked down.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts of a single API call, zero means no limit
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry, it doubles with every further retry
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between two attempts, zero means no cap
+	MaxBackoff time.Duration
+	// Jitter is the fraction (between 0 and 1) of the delay which is randomized, to spread the retries of
+	// concurrent calls
+	Jitter float64
+	// Idempotent decides whether a request of the given method may be replayed when it possibly reached
+	// Marathon already. Defaults to GET, HEAD, PUT and DELETE being idempotent.
+	Idempotent func(method string) bool
+}
+
+// NewDefaultRetryPolicy creates a retry policy making up to five attempts, backing off exponentially from
+// 100 milliseconds to 5 seconds with a 20% jitter
+func NewDefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Jitter:         0.2,
+	}
+}
+
+// isIdempotent checks if requests of the given method may safely be sent more than once
+func (p *RetryPolicy) isIdempotent(method string) bool {
+	if p.Idempotent != nil {
+		return p.Idempotent(method)
+	}
+
+	switch method {
+	case "GET", "HEAD", "PUT", "DELETE":
+		return true
+	}
+	return false
+}
+
+// retryable decides whether the failed attempt of an API call should be retried
+//		attempt:	the number of the failed attempt, starting at 1
+//		method:		the method of the request
+//		response:	the response of the failed attempt, if any
+//		err:		the transport error of the failed attempt, if any
+func (p *RetryPolicy) retryable(attempt int, method string, response *http.Response, err error) bool {
+	if p == nil {
+		return true
+	}
+	if p.MaxAttempts > 0 && attempt >= p.MaxAttempts {
+		return false
+	}
+	if p.isIdempotent(method) {
+		return true
+	}
+
+	// step: a non idempotent request is only replayed when it surely hasn't been processed
+	if err != nil {
+		return isDialError(err)
+	}
+	return response.StatusCode == http.StatusServiceUnavailable
+}
+
+// backoff returns the delay before the next attempt after the given failed one. A Retry-After header on the
+// response takes precedence over the computed delay.
+func (p *RetryPolicy) backoff(attempt int, response *http.Response) time.Duration {
+	if p == nil {
+		return 0
+	}
+	if response != nil {
+		if delay, found := parseRetryAfter(response.Header.Get("Retry-After")); found {
+			return delay
+		}
+	}
+
+	delay := p.InitialBackoff
+	for i := 1; i < attempt && (p.MaxBackoff <= 0 || delay < p.MaxBackoff); i++ {
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		delay -= time.Duration(rand.Float64() * p.Jitter * float64(delay))
+	}
+
+	return delay
+}
+
+// parseRetryAfter parses the value of a Retry-After header, given either in seconds or as HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		delay := date.Sub(time.Now())
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+
+	return 0, false
+}
+
+// isDialError checks if the error occurred while connecting, i.e. before the request was sent
+func isDialError(err error) bool {
+	if urlErr, ok := err.(*url.Error); ok {
+		err = urlErr.Err
+	}
+	opErr, ok := err.(*net.OpError)
+	return ok && opErr.Op == "dial"
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRetryEndpoint(t *testing.T, policy *RetryPolicy) *endpoint {
+	config := NewDefaultConfig()
+	config.RetryPolicy = policy
+	return newFakeMarathonEndpoint(t, &configContainer{
+		client: &config,
+		server: &serverConfig{scope: "retry"},
+	})
+}
+
+func TestRetryIdempotentRequest(t *testing.T) {
+	endpoint := newRetryEndpoint(t, NewDefaultRetryPolicy())
+	defer endpoint.Close()
+
+	application, err := endpoint.Client.Application(fakeAppName)
+	require.NoError(t, err)
+	assert.Equal(t, fakeAppName, application.ID)
+}
+
+func TestRetryMaxAttempts(t *testing.T) {
+	endpoint := newRetryEndpoint(t, &RetryPolicy{MaxAttempts: 1})
+	defer endpoint.Close()
+
+	_, err := endpoint.Client.Application(fakeAppName)
+	require.Error(t, err)
+	apiErr, ok := err.(*APIError)
+	require.True(t, ok)
+	assert.Equal(t, ErrCodeServer, apiErr.ErrCode)
+}
+
+func TestRetryNonIdempotentRequest(t *testing.T) {
+	endpoint := newRetryEndpoint(t, NewDefaultRetryPolicy())
+	defer endpoint.Close()
+
+	// a POST failing with a 500 may have been processed, so it must not be replayed
+	_, err := endpoint.Client.CreateApplication(NewDockerApplication().Name(fakeAppName))
+	require.Error(t, err)
+	apiErr, ok := err.(*APIError)
+	require.True(t, ok)
+	assert.Equal(t, ErrCodeServer, apiErr.ErrCode)
+
+	// unless the policy declares it idempotent
+	policy := NewDefaultRetryPolicy()
+	policy.Idempotent = func(string) bool { return true }
+	endpoint = newRetryEndpoint(t, policy)
+	defer endpoint.Close()
+
+	application, err := endpoint.Client.CreateApplication(NewDockerApplication().Name(fakeAppName))
+	require.NoError(t, err)
+	assert.Equal(t, fakeAppName, application.ID)
+}
+
+func TestRetryWithoutPolicy(t *testing.T) {
+	endpoint := newRetryEndpoint(t, nil)
+	defer endpoint.Close()
+
+	application, err := endpoint.Client.CreateApplication(NewDockerApplication().Name(fakeAppName))
+	require.NoError(t, err)
+	assert.Equal(t, fakeAppName, application.ID)
+}
+
+func TestRetryable(t *testing.T) {
+	dialErr := &url.Error{Op: "Post", URL: "http://marathon", Err: &net.OpError{Op: "dial", Err: errors.New("refused")}}
+	readErr := &url.Error{Op: "Post", URL: "http://marathon", Err: &net.OpError{Op: "read", Err: errors.New("reset")}}
+	unavailable := &http.Response{StatusCode: http.StatusServiceUnavailable}
+	internal := &http.Response{StatusCode: http.StatusInternalServerError}
+
+	var policy *RetryPolicy
+	assert.True(t, policy.retryable(100, "POST", nil, readErr))
+
+	policy = &RetryPolicy{MaxAttempts: 3}
+	assert.True(t, policy.retryable(1, "GET", nil, readErr))
+	assert.True(t, policy.retryable(2, "PUT", internal, nil))
+	assert.False(t, policy.retryable(3, "GET", nil, readErr))
+	assert.True(t, policy.retryable(1, "POST", nil, dialErr))
+	assert.False(t, policy.retryable(1, "POST", nil, readErr))
+	assert.True(t, policy.retryable(1, "POST", unavailable, nil))
+	assert.False(t, policy.retryable(1, "POST", internal, nil))
+}
+
+func TestRetryBackoff(t *testing.T) {
+	var policy *RetryPolicy
+	assert.Equal(t, time.Duration(0), policy.backoff(1, nil))
+
+	policy = &RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	assert.Equal(t, 100*time.Millisecond, policy.backoff(1, nil))
+	assert.Equal(t, 200*time.Millisecond, policy.backoff(2, nil))
+	assert.Equal(t, 800*time.Millisecond, policy.backoff(4, nil))
+	assert.Equal(t, time.Second, policy.backoff(5, nil))
+	assert.Equal(t, time.Second, policy.backoff(100, nil))
+
+	response := &http.Response{Header: http.Header{}}
+	response.Header.Set("Retry-After", "3")
+	assert.Equal(t, 3*time.Second, policy.backoff(1, response))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 10; i++ {
+		delay := policy.backoff(2, nil)
+		assert.True(t, delay > 100*time.Millisecond && delay <= 200*time.Millisecond, "delay %s out of range", delay)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	delay, found := parseRetryAfter("")
+	assert.False(t, found)
+
+	delay, found = parseRetryAfter("120")
+	assert.True(t, found)
+	assert.Equal(t, 2*time.Minute, delay)
+
+	delay, found = parseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	assert.True(t, found)
+	assert.True(t, delay > 59*time.Minute && delay <= time.Hour)
+
+	delay, found = parseRetryAfter("Wed, 21 Oct 2015 07:28:00 GMT")
+	assert.True(t, found)
+	assert.Equal(t, time.Duration(0), delay)
+
+	_, found = parseRetryAfter("soon")
+	assert.False(t, found)
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package marathon
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -44,27 +45,72 @@ func (r *marathonClient) Subscriptions() (*Subscriptions, error) {
 	return subscriptions, nil
 }
 
-// AddEventsListener adds your self as a listener to events from Marathon
-//		channel:	a EventsChannel used to receive event on
+// AddEventsListener adds your self as a listener to events from Marathon, with the default queue options
+//		filter:		the listener IDs of the events to receive
 func (r *marathonClient) AddEventsListener(filter int) (EventsChannel, error) {
+	return r.AddEventsListenerWithOptions(filter, EventsListenerOptions{})
+}
+
+// AddEventsListenerWithOptions adds your self as a listener to events from Marathon. The events are queued
+// for the listener and delivered in order, the options decide what happens when the listener falls behind.
+//		filter:		the listener IDs of the events to receive
+//		options:	the options of the queue of the listener
+func (r *marathonClient) AddEventsListenerWithOptions(filter int, options EventsListenerOptions) (EventsChannel, error) {
+	return r.addEventsListener(filter, options, false)
+}
+
+// addEventsListener adds a listener of the events
+//		filter:		the listener IDs of the events to receive
+//		options:	the options of the queue of the listener
+//		internal:	whether the listener is one of the client itself, which only receives the events of the
+//				current subscription without registering it, nor unsubscribing once removed
+func (r *marathonClient) addEventsListener(filter int, options EventsListenerOptions, internal bool) (EventsChannel, error) {
 	r.Lock()
 	defer r.Unlock()
 
+	if r.isClosed() {
+		return nil, ErrClientClosed
+	}
+
 	// step: someone has asked to start listening to event, we need to register for events
 	// if we haven't done so already
-	if err := r.registerSubscription(); err != nil {
-		return nil, err
+	if !internal {
+		if err := r.registerSubscription(); err != nil {
+			return nil, err
+		}
 	}
 
 	channel := make(EventsChannel)
-	r.listeners[channel] = EventsChannelContext{
+	context := EventsChannelContext{
 		filter:     filter,
 		done:       make(chan struct{}, 1),
 		completion: &sync.WaitGroup{},
+		queue:      newEventsQueue(options),
+		internal:   internal,
 	}
+	context.completion.Add(1)
+	go func() {
+		defer context.completion.Done()
+		context.queue.deliver(channel, context.done)
+	}()
+	r.listeners[channel] = context
+
 	return channel, nil
 }
 
+// EventsListenerStats retrieves the state of the queue of a listener, if the listener is still registered
+//		channel:	the channel of the listener
+func (r *marathonClient) EventsListenerStats(channel EventsChannel) (EventsListenerStats, bool) {
+	r.RLock()
+	defer r.RUnlock()
+
+	context, found := r.listeners[channel]
+	if !found {
+		return EventsListenerStats{}, false
+	}
+	return context.queue.stats(), true
+}
+
 // RemoveEventsListener removes the channel from the events listeners
 //		channel:			the channel you are removing
 func (r *marathonClient) RemoveEventsListener(channel EventsChannel) {
@@ -76,7 +122,7 @@ func (r *marathonClient) RemoveEventsListener(channel EventsChannel) {
 		delete(r.listeners, channel)
 		// step: if there is no one else listening, let's remove ourselves
 		// from the events callback
-		if r.config.EventsTransport == EventsTransportCallback && len(r.listeners) == 0 {
+		if r.config.EventsTransport == EventsTransportCallback && !context.internal && !r.hasListeners() {
 			r.Unsubscribe(r.SubscriptionURL())
 		}
 
@@ -88,6 +134,16 @@ func (r *marathonClient) RemoveEventsListener(channel EventsChannel) {
 	}
 }
 
+// hasListeners checks if any listener other than the ones of the client itself is registered, the lock must be held
+func (r *marathonClient) hasListeners() bool {
+	for _, context := range r.listeners {
+		if !context.internal {
+			return true
+		}
+	}
+	return false
+}
+
 // SubscriptionURL retrieves the subscription callback URL used when registering
 func (r *marathonClient) SubscriptionURL() string {
 	if r.config.CallbackURL != "" {
@@ -122,11 +178,12 @@ func (r *marathonClient) registerCallbackSubscription() error {
 		r.ipAddress = ipAddress
 		binding := fmt.Sprintf("%s:%d", ipAddress, r.config.EventsPort)
 		// step: register the handler
-		http.HandleFunc(defaultEventsURL, r.handleCallbackEvent)
+		mux := http.NewServeMux()
+		mux.HandleFunc(defaultEventsURL, r.handleCallbackEvent)
 		// step: create the http server
-		r.eventsHTTP = &http.Server{
+		eventsHTTP := &http.Server{
 			Addr:           binding,
-			Handler:        nil,
+			Handler:        mux,
 			ReadTimeout:    10 * time.Second,
 			WriteTimeout:   10 * time.Second,
 			MaxHeaderBytes: 1 << 20,
@@ -135,14 +192,15 @@ func (r *marathonClient) registerCallbackSubscription() error {
 		// @todo need to add a timeout value here
 		listener, err := net.Listen("tcp", binding)
 		if err != nil {
-			return nil
+			return err
 		}
 
 		go func() {
-			for {
-				r.eventsHTTP.Serve(listener)
+			if err := eventsHTTP.Serve(listener); err != http.ErrServerClosed {
+				r.logger.Error("registerCallbackSubscription(): events server failed", "error", err)
 			}
 		}()
+		r.eventsHTTP = eventsHTTP
 	}
 
 	// step: get the callback url
@@ -166,34 +224,68 @@ func (r *marathonClient) registerCallbackSubscription() error {
 // registerSSESubscription starts a go routine that continously tries to
 // connect to the SSE stream and to process the received events. To establish
 // the connection it tries the active cluster members until no more member is
-// active. When this happens it will retry to get a connection every 5 seconds.
+// active. When this happens it backs off before retrying, as configured by
+// EventsStreamBackoff. A reconnected stream resumes from the last received
+// event ID and is announced to the listeners with an 'event_stream_reconnected'
+// event.
 func (r *marathonClient) registerSSESubscription() {
 	if r.subscribedToSSE {
 		return
 	}
 
-	go func() {
-		for {
-			stream, err := r.connectToSSE()
+	go func(r *marathonClient) {
+		var lastEventID string
+		connected := false
+		for attempt := 1; ; attempt++ {
+			stream, err := r.connectToSSE(lastEventID)
+			if r.ctx.Err() != nil {
+				// step: the client has been closed
+				if stream != nil {
+					stream.close(false)
+				}
+				return
+			}
 			if err != nil {
-				r.debugLog.Printf("Error connecting SSE subscription: %s", err)
-				<-time.After(5 * time.Second)
+				r.logger.Warn("registerSSESubscription(): failed to connect the event stream", "error", err)
+				select {
+				case <-r.ctx.Done():
+				case <-time.After(r.eventsStreamBackoff(attempt)):
+				}
 				continue
 			}
 
-			err = r.listenToSSE(stream)
-			stream.Close()
-			r.debugLog.Printf("Error on SSE subscription: %s", err)
+			// step: let the listeners know they may have missed events
+			if connected {
+				r.client.metrics().EventsStreamReconnected()
+				r.dispatchEvent(newStreamReconnectedEvent(lastEventID))
+			}
+			connected = true
+			attempt = 0
+
+			err = r.listenToSSE(stream, &lastEventID)
+			if r.ctx.Err() == nil {
+				r.logger.Warn("registerSSESubscription(): event stream failed", "error", err)
+			}
 		}
-	}()
+	}(r.detached())
 
 	r.subscribedToSSE = true
 }
 
+// eventsStreamBackoff returns the delay before the next attempt to connect to the SSE stream
+func (r *marathonClient) eventsStreamBackoff(attempt int) time.Duration {
+	policy := r.config.EventsStreamBackoff
+	if policy == nil {
+		policy = defaultEventsStreamBackoff
+	}
+	return policy.backoff(attempt, nil)
+}
+
 // connectToSSE tries to establish an *eventsource.Stream to any of the Marathon cluster members, marking the
 // member as down on connection failure, until there is no more active member in the cluster.
 // Given the http request can not be built, it will panic as this case should never happen.
-func (r *marathonClient) connectToSSE() (*eventsource.Stream, error) {
+//		lastEventID:	the ID of the last received event, sent as Last-Event-ID to resume the stream
+func (r *marathonClient) connectToSSE(lastEventID string) (*eventStream, error) {
 	for {
 		request, member, err := r.buildAPIRequest("GET", marathonAPIEventStream, nil)
 		if err != nil {
@@ -215,35 +307,104 @@ func (r *marathonClient) connectToSSE() (*eventsource.Stream, error) {
 			Timeout:       r.config.HTTPClient.Timeout,
 		}
 
-		stream, err := eventsource.SubscribeWith("", httpClient, request)
+		// step: the connection of each stream can be aborted on its own
+		ctx, cancel := context.WithCancel(r.ctx)
+		stream, err := eventsource.SubscribeWith(lastEventID, httpClient, request.WithContext(ctx))
 		if err != nil {
-			r.debugLog.Printf("Error subscribing to Marathon event stream: %s", err)
+			cancel()
+			if ctxErr := r.ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			r.logger.Warn("connectToSSE(): failed to subscribe to the event stream", "member", member, "error", err)
 			r.hosts.markDown(member)
 			continue
 		}
 
-		return stream, nil
+		return &eventStream{Stream: stream, cancel: cancel}, nil
 	}
 }
 
-func (r *marathonClient) listenToSSE(stream *eventsource.Stream) error {
+// listenToSSE handles the events of the stream until it fails, goes stale or the client is closed, and then
+// closes the stream
+//		stream:		the stream to listen to
+//		lastEventID:	updated with the ID of every received event
+func (r *marathonClient) listenToSSE(stream *eventStream, lastEventID *string) error {
+	// step: a stream without any event for too long is considered stale
+	timeout := r.config.EventsStreamIdleTimeout
+	var timer *time.Timer
+	var idle <-chan time.Time
+	if timeout > 0 {
+		timer = time.NewTimer(timeout)
+		defer timer.Stop()
+		idle = timer.C
+	}
+
 	for {
 		select {
 		case ev := <-stream.Events:
+			if id := ev.Id(); id != "" {
+				*lastEventID = id
+			}
 			if err := r.handleEvent(ev.Data()); err != nil {
-				r.debugLog.Printf("listenToSSE(): failed to handle event: %v", err)
+				r.logger.Warn("listenToSSE(): failed to handle event", "error", err)
+			}
+			if timer != nil {
+				if !timer.Stop() {
+					<-timer.C
+				}
+				timer.Reset(timeout)
 			}
 		case err := <-stream.Errors:
+			stream.close(true)
 			return err
-
+		case <-idle:
+			stream.close(false)
+			return fmt.Errorf("no event received within %s, the stream is stale", timeout)
+		case <-r.ctx.Done():
+			stream.close(false)
+			return r.ctx.Err()
 		}
 	}
 }
 
+// eventStream is an SSE stream whose connection can be aborted
+type eventStream struct {
+	*eventsource.Stream
+	cancel context.CancelFunc
+}
+
+// close aborts the connection and closes the stream. The eventsource library panics when the channels of the
+// stream are closed while it sends the error ending the stream, so unless this error has been received already,
+// the channels are drained in the background until it is, the caller not having to wait for it.
+//		failed:		whether the error ending the stream has been received
+func (s *eventStream) close(failed bool) {
+	s.cancel()
+	if failed {
+		s.Stream.Close()
+		return
+	}
+
+	go func() {
+		timeout := time.NewTimer(eventsShutdownTimeout)
+		defer timeout.Stop()
+	drain:
+		for {
+			select {
+			case <-s.Events:
+			case <-s.Errors:
+				break drain
+			case <-timeout.C:
+				break drain
+			}
+		}
+		s.Stream.Close()
+	}()
+}
+
 // Subscribe adds a URL to Marathon's callback facility
 //	callback	: the URL you wish to subscribe
 func (r *marathonClient) Subscribe(callback string) error {
-	path := fmt.Sprintf("%s?callbackUrl=%s", marathonAPISubscription, callback)
+	path := subscriptionPath(callback)
 	return r.apiPost(path, "", nil)
 
 }
@@ -252,7 +413,12 @@ func (r *marathonClient) Subscribe(callback string) error {
 //	callback	: the URL you wish to unsubscribe
 func (r *marathonClient) Unsubscribe(callback string) error {
 	// step: remove from the list of subscriptions
-	return r.apiDelete(fmt.Sprintf("%s?callbackUrl=%s", marathonAPISubscription, callback), nil, nil)
+	return r.apiDelete(subscriptionPath(callback), nil, nil)
+}
+
+// subscriptionPath returns the path of the subscription of a callback URL
+func subscriptionPath(callback string) string {
+	return fmt.Sprintf("%s?callbackUrl=%s", marathonAPISubscription, callback)
 }
 
 // HasSubscription checks to see a subscription already exists with Marathon
@@ -284,6 +450,15 @@ func (r *marathonClient) handleEvent(content string) error {
 	// step: check whether event type is handled
 	event, err := GetEvent(eventType.EventType)
 	if err != nil {
+		// step: hand the event over undecoded to the listeners of unknown events, if any
+		if r.listensTo(EventIDUnknown) {
+			r.dispatchEvent(&Event{
+				ID:    EventIDUnknown,
+				Name:  eventType.EventType,
+				Event: &EventUnknown{EventType: eventType.EventType, Raw: json.RawMessage(content)},
+			})
+			return nil
+		}
 		return fmt.Errorf("unable to handle event, type: %s, error: %s", eventType.EventType, err)
 	}
 
@@ -293,38 +468,76 @@ func (r *marathonClient) handleEvent(content string) error {
 		return fmt.Errorf("failed to decode the event, id: %d, error: %s", event.ID, err)
 	}
 
+	// step: a (re)attached event stream may be the result of a leader change
+	if event.ID == EventIDStreamAttached && r.config.RouteToLeader {
+		r.hosts.invalidateLeader()
+	}
+
+	r.dispatchEvent(event)
+
+	return nil
+}
+
+// newStreamReconnectedEvent creates the event announcing a reconnection of the event stream
+func newStreamReconnectedEvent(lastEventID string) *Event {
+	return &Event{
+		ID:   EventIDStreamReconnected,
+		Name: "event_stream_reconnected",
+		Event: &EventStreamReconnected{
+			EventType:   "event_stream_reconnected",
+			Timestamp:   time.Now().UTC().Format(time.RFC3339Nano),
+			LastEventID: lastEventID,
+		},
+	}
+}
+
+// listensTo checks if any listener is interested in the events of the given listener ID
+func (r *marathonClient) listensTo(id int) bool {
 	r.RLock()
 	defer r.RUnlock()
 
-	// step: check if anyone is listen for this event
+	for _, context := range r.listeners {
+		if id&context.filter != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// dispatchEvent queues the event for the listeners subscribed to its type
+func (r *marathonClient) dispatchEvent(event *Event) {
+	// step: check if anyone is listen for this event, the lock can't be held while
+	// a blocking queue waits for its listener
+	r.RLock()
+	listeners := make(map[EventsChannel]EventsChannelContext)
 	for channel, context := range r.listeners {
 		// step: check if this listener wants this event type
 		if event.ID&context.filter != 0 {
-			context.completion.Add(1)
-			go func(ch EventsChannel, context EventsChannelContext, e *Event) {
-				defer context.completion.Done()
-				select {
-				case ch <- e:
-				case <-context.done:
-					// Terminates goroutine.
-				}
-			}(channel, context, event)
+			listeners[channel] = context
 		}
 	}
+	r.RUnlock()
 
-	return nil
+	start := time.Now()
+	for channel, context := range listeners {
+		if !context.queue.push(event, context.done) {
+			r.logger.Warn("dispatchEvent(): disconnecting the listener, its queue is full", "filter", context.filter)
+			r.RemoveEventsListener(channel)
+		}
+	}
+	r.client.metrics().EventDispatched(event.Name, len(listeners), time.Since(start))
 }
 
 func (r *marathonClient) handleCallbackEvent(writer http.ResponseWriter, request *http.Request) {
 	body, err := ioutil.ReadAll(request.Body)
 	if err != nil {
 		// TODO should this return a 500?
-		r.debugLog.Printf("handleCallbackEvent(): failed to read request body, error: %s\n", err)
+		r.logger.Warn("handleCallbackEvent(): failed to read request body", "error", err)
 		return
 	}
 
 	if err := r.handleEvent(string(body[:])); err != nil {
 		// TODO should this return a 500?
-		r.debugLog.Printf("handleCallbackEvent(): failed to handle event: %v\n", err)
+		r.logger.Warn("handleCallbackEvent(): failed to handle event", "error", err)
 	}
 }
@@ -17,9 +17,11 @@ limitations under the License.
 package marathon
 
 import (
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/donovanhide/eventsource"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -199,6 +201,120 @@ var testCases = testCaseList{
 			},
 		},
 	},
+	testCase{
+		name: "instance_changed_event",
+		source: `{
+	"eventType": "instance_changed_event",
+	"timestamp": "2017-04-19T16:38:25.122Z",
+	"instanceId": "my-app.marathon-d6fdc2e7-2522-11e7-bb34-70b3d5800001",
+	"condition": "Running",
+	"runSpecId": "/my-app",
+	"agentId": "2c4e4e2b-3c5a-4d67-8cd5-a0e1d8f2c2d1-S0",
+	"host": "slave-1234.acme.org",
+	"runSpecVersion": "2017-04-19T16:38:20.116Z"
+}`,
+		expectation: &EventInstanceChanged{
+			EventType:      "instance_changed_event",
+			Timestamp:      "2017-04-19T16:38:25.122Z",
+			InstanceID:     "my-app.marathon-d6fdc2e7-2522-11e7-bb34-70b3d5800001",
+			Condition:      "Running",
+			RunSpecID:      "/my-app",
+			AgentID:        "2c4e4e2b-3c5a-4d67-8cd5-a0e1d8f2c2d1-S0",
+			Host:           "slave-1234.acme.org",
+			RunSpecVersion: "2017-04-19T16:38:20.116Z",
+		},
+	},
+	testCase{
+		name: "instance_health_changed_event",
+		source: `{
+	"eventType": "instance_health_changed_event",
+	"timestamp": "2017-04-19T16:38:30.201Z",
+	"instanceId": "my-app.marathon-d6fdc2e7-2522-11e7-bb34-70b3d5800001",
+	"runSpecId": "/my-app",
+	"runSpecVersion": "2017-04-19T16:38:20.116Z",
+	"healthy": true
+}`,
+		expectation: &EventInstanceHealthChanged{
+			EventType:      "instance_health_changed_event",
+			Timestamp:      "2017-04-19T16:38:30.201Z",
+			InstanceID:     "my-app.marathon-d6fdc2e7-2522-11e7-bb34-70b3d5800001",
+			RunSpecID:      "/my-app",
+			RunSpecVersion: "2017-04-19T16:38:20.116Z",
+			Healthy:        func() *bool { healthy := true; return &healthy }(),
+		},
+	},
+	testCase{
+		name: "unknown_instance_terminated_event",
+		source: `{
+	"eventType": "unknown_instance_terminated_event",
+	"timestamp": "2017-04-19T16:40:02.018Z",
+	"instanceId": "my-app.marathon-11e7-bb34-70b3d5800001",
+	"runSpecId": "/my-app",
+	"condition": "Killed"
+}`,
+		expectation: &EventUnknownInstanceTerminated{
+			EventType:  "unknown_instance_terminated_event",
+			Timestamp:  "2017-04-19T16:40:02.018Z",
+			InstanceID: "my-app.marathon-11e7-bb34-70b3d5800001",
+			RunSpecID:  "/my-app",
+			Condition:  "Killed",
+		},
+	},
+	testCase{
+		name: "unhealthy_instance_kill_event",
+		source: `{
+	"eventType": "unhealthy_instance_kill_event",
+	"timestamp": "2017-04-19T16:41:12.870Z",
+	"appId": "/my-app",
+	"taskId": "my-app.d6fdc2e7-2522-11e7-bb34-70b3d5800001",
+	"instanceId": "my-app.marathon-d6fdc2e7-2522-11e7-bb34-70b3d5800001",
+	"version": "2017-04-19T16:38:20.116Z",
+	"reason": "3 consecutive health check failures",
+	"host": "slave-1234.acme.org",
+	"slaveId": "2c4e4e2b-3c5a-4d67-8cd5-a0e1d8f2c2d1-S0"
+}`,
+		expectation: &EventUnhealthyInstanceKill{
+			EventType:  "unhealthy_instance_kill_event",
+			Timestamp:  "2017-04-19T16:41:12.870Z",
+			AppID:      "/my-app",
+			TaskID:     "my-app.d6fdc2e7-2522-11e7-bb34-70b3d5800001",
+			InstanceID: "my-app.marathon-d6fdc2e7-2522-11e7-bb34-70b3d5800001",
+			Version:    "2017-04-19T16:38:20.116Z",
+			Reason:     "3 consecutive health check failures",
+			Host:       "slave-1234.acme.org",
+			SlaveID:    "2c4e4e2b-3c5a-4d67-8cd5-a0e1d8f2c2d1-S0",
+		},
+	},
+	testCase{
+		name: "pod_created_event",
+		source: `{
+	"eventType": "pod_created_event",
+	"timestamp": "2017-04-19T16:50:01.445Z",
+	"clientIp": "10.0.0.12",
+	"uri": "/v2/pods/my-pod"
+}`,
+		expectation: &EventPodCreated{
+			EventType: "pod_created_event",
+			Timestamp: "2017-04-19T16:50:01.445Z",
+			ClientIP:  "10.0.0.12",
+			URI:       "/v2/pods/my-pod",
+		},
+	},
+	testCase{
+		name: "pod_deleted_event",
+		source: `{
+	"eventType": "pod_deleted_event",
+	"timestamp": "2017-04-19T16:55:41.005Z",
+	"clientIp": "10.0.0.12",
+	"uri": "/v2/pods/my-pod"
+}`,
+		expectation: &EventPodDeleted{
+			EventType: "pod_deleted_event",
+			Timestamp: "2017-04-19T16:55:41.005Z",
+			ClientIP:  "10.0.0.12",
+			URI:       "/v2/pods/my-pod",
+		},
+	},
 	// For Marathon 1.1.2 and after
 	testCase{
 		name: "deployment_step_success",
@@ -307,7 +423,7 @@ func TestEventStreamEventsReceived(t *testing.T) {
 	endpoint := newFakeMarathonEndpoint(t, &config)
 	defer endpoint.Close()
 
-	events, err := endpoint.Client.AddEventsListener(EventIDApplications | EventIDDeploymentInfo | EventIDDeploymentStepSuccess)
+	events, err := endpoint.Client.AddEventsListener(EventIDApplications | EventIDInstances | EventIDPods | EventIDDeploymentInfo | EventIDDeploymentStepSuccess)
 	assert.NoError(t, err)
 
 	almostAllTestCases := testCases[:len(testCases)-1]
@@ -356,6 +472,60 @@ func TestEventStreamEventsReceived(t *testing.T) {
 	}
 }
 
+func TestUnknownEventsReceived(t *testing.T) {
+	clientCfg := NewDefaultConfig()
+	clientCfg.EventsTransport = EventsTransportSSE
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &clientCfg})
+	defer endpoint.Close()
+
+	client := endpoint.Client.(*marathonClient)
+	source := `{"eventType": "brand_new_event", "timestamp": "2017-04-19T16:55:41.005Z"}`
+
+	// step: without a listener of unknown events, they can't be handled
+	assert.Error(t, client.handleEvent(source))
+
+	events, err := endpoint.Client.AddEventsListener(EventIDUnknown)
+	require.NoError(t, err)
+	time.Sleep(SSEConnectWaitTime)
+	endpoint.Server.PublishEvent(source)
+
+	select {
+	case event := <-events:
+		assert.Equal(t, EventIDUnknown, event.ID)
+		assert.Equal(t, "brand_new_event", event.Name)
+		unknown, ok := event.Event.(*EventUnknown)
+		require.True(t, ok)
+		assert.Equal(t, "brand_new_event", unknown.EventType)
+		assert.JSONEq(t, source, string(unknown.Raw))
+	case <-time.After(eventPublishTimeout):
+		assert.Fail(t, "did not receive event in time")
+	}
+}
+
+func TestStreamReconnectedNotDecoded(t *testing.T) {
+	clientCfg := NewDefaultConfig()
+	clientCfg.EventsTransport = EventsTransportSSE
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &clientCfg})
+	defer endpoint.Close()
+
+	_, err := GetEvent("event_stream_reconnected")
+	assert.Error(t, err)
+
+	// step: the synthetic event can't be injected by the event stream
+	events, err := endpoint.Client.AddEventsListener(EventIDStreamReconnected | EventIDUnknown)
+	require.NoError(t, err)
+	time.Sleep(SSEConnectWaitTime)
+	endpoint.Server.PublishEvent(`{"eventType": "event_stream_reconnected", "timestamp": "2017-04-19T16:55:41.005Z"}`)
+
+	select {
+	case event := <-events:
+		assert.Equal(t, EventIDUnknown, event.ID)
+		assert.IsType(t, &EventUnknown{}, event.Event)
+	case <-time.After(eventPublishTimeout):
+		assert.Fail(t, "did not receive event in time")
+	}
+}
+
 func TestConnectToSSESuccess(t *testing.T) {
 	clientCfg := NewDefaultConfig()
 	// Use non-existent address as first cluster member
@@ -371,7 +541,7 @@ func TestConnectToSSESuccess(t *testing.T) {
 	client.hosts.members = append(client.hosts.members, &member{endpoint: endpoint.Server.httpSrv.URL})
 
 	// Connection should work as one of the Marathon members is up
-	_, err := client.connectToSSE()
+	_, err := client.connectToSSE("")
 	assert.NoError(t, err, "expected no error in connectToSSE")
 }
 
@@ -386,7 +556,7 @@ func TestConnectToSSEFailure(t *testing.T) {
 	client := endpoint.Client.(*marathonClient)
 
 	// No Marathon member is up, we should get an error
-	_, err := client.connectToSSE()
+	_, err := client.connectToSSE("")
 	assert.Error(t, err, "expected error in connectToSSE when all cluster members are down")
 }
 
@@ -423,3 +593,175 @@ func TestRegisterSEESubscriptionReconnectsStreamOnError(t *testing.T) {
 		assert.Fail(t, "did not receive event in time")
 	}
 }
+
+func TestRegisterSSESubscriptionSendsReconnectedEvent(t *testing.T) {
+	clientCfg := NewDefaultConfig()
+	clientCfg.EventsTransport = EventsTransportSSE
+	config := configContainer{client: &clientCfg}
+
+	endpoint1 := newFakeMarathonEndpoint(t, &config)
+	endpoint2 := newFakeMarathonEndpoint(t, &config)
+	defer endpoint2.Close()
+
+	client1 := endpoint1.Client.(*marathonClient)
+	client1.hosts.members = append(client1.hosts.members, &member{endpoint: endpoint2.Server.httpSrv.URL})
+
+	events, err := endpoint1.Client.AddEventsListener(EventIDStreamReconnected)
+	require.NoError(t, err)
+	time.Sleep(SSEConnectWaitTime)
+
+	// the failover to the second member must be announced
+	endpoint1.Close()
+
+	select {
+	case event := <-events:
+		assert.Equal(t, "event_stream_reconnected", event.Name)
+		_, ok := event.Event.(*EventStreamReconnected)
+		assert.True(t, ok, "unexpected event type %T", event.Event)
+	case <-time.After(SSEConnectWaitTime + eventPublishTimeout):
+		assert.Fail(t, "did not receive the reconnected event in time")
+	}
+}
+
+// replayRecorder is an eventsource.Repository recording the event IDs the clients resume from
+type replayRecorder chan string
+
+func (r replayRecorder) Replay(channel, id string) chan eventsource.Event {
+	r <- id
+	events := make(chan eventsource.Event)
+	close(events)
+	return events
+}
+
+func TestRegisterSSESubscriptionReconnectsStaleStream(t *testing.T) {
+	clientCfg := NewDefaultConfig()
+	clientCfg.EventsTransport = EventsTransportSSE
+	clientCfg.EventsStreamIdleTimeout = time.Second
+	config := configContainer{client: &clientCfg}
+
+	endpoint := newFakeMarathonEndpoint(t, &config)
+	defer endpoint.Close()
+
+	resumed := make(replayRecorder, 10)
+	endpoint.Server.eventSrv.Register("event", resumed)
+
+	events, err := endpoint.Client.AddEventsListener(EventIDApplications | EventIDStreamReconnected)
+	require.NoError(t, err)
+
+	time.Sleep(SSEConnectWaitTime)
+	endpoint.Server.PublishEvent(testCases[0].source)
+
+	// the stream goes stale without further events and must be resumed from the last event
+	var reconnected *EventStreamReconnected
+	timeout := time.After(3 * time.Second)
+	for reconnected == nil {
+		select {
+		case event := <-events:
+			if event.ID == EventIDStreamReconnected {
+				reconnected = event.Event.(*EventStreamReconnected)
+			}
+		case <-timeout:
+			require.Fail(t, "did not receive the reconnected event in time")
+		}
+	}
+	assert.Equal(t, "0", reconnected.LastEventID)
+
+	select {
+	case id := <-resumed:
+		assert.Equal(t, "0", id)
+	case <-time.After(eventPublishTimeout):
+		assert.Fail(t, "the stream was not resumed from the last event")
+	}
+}
+
+func TestEventsStreamBackoff(t *testing.T) {
+	client := &marathonClient{config: NewDefaultConfig()}
+	delay := client.eventsStreamBackoff(1)
+	assert.True(t, delay > 0 && delay <= time.Second, "delay %s out of range", delay)
+	assert.True(t, client.eventsStreamBackoff(100) <= 30*time.Second)
+
+	client.config.EventsStreamBackoff = &RetryPolicy{InitialBackoff: 10 * time.Millisecond}
+	assert.Equal(t, 40*time.Millisecond, client.eventsStreamBackoff(3))
+}
+
+func TestEventStreamClose(t *testing.T) {
+	stream := &eventStream{
+		Stream: &eventsource.Stream{Events: make(chan eventsource.Event), Errors: make(chan error)},
+		cancel: func() {},
+	}
+
+	// step: the caller doesn't wait for the error ending the stream
+	start := time.Now()
+	stream.close(false)
+	assert.True(t, time.Since(start) < eventPublishTimeout, "closing the stream took %s", time.Since(start))
+
+	// step: the stream is closed once it has been received
+	stream.Errors <- errors.New("EOF")
+	select {
+	case _, more := <-stream.Events:
+		assert.False(t, more, "the events channel should have been closed")
+	case <-time.After(eventPublishTimeout):
+		assert.Fail(t, "the stream was not closed in time")
+	}
+}
+
+func TestEventsListenerWithOptions(t *testing.T) {
+	clientCfg := NewDefaultConfig()
+	clientCfg.EventsTransport = EventsTransportSSE
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &clientCfg})
+	defer endpoint.Close()
+
+	client := endpoint.Client.(*marathonClient)
+	events, err := endpoint.Client.AddEventsListenerWithOptions(EventIDStatusUpdate, EventsListenerOptions{
+		QueueSize: 1,
+		Overflow:  EventsOverflowDisconnect,
+	})
+	require.NoError(t, err)
+
+	// step: the first event is in flight, the second one queued and the third one overflows
+	for i := 0; i < 3; i++ {
+		require.NoError(t, client.handleEvent(testCases.find("status_update_event").source))
+		if i == 0 {
+			time.Sleep(eventPublishTimeout)
+		}
+	}
+
+	_, found := endpoint.Client.EventsListenerStats(events)
+	assert.False(t, found, "the listener should have been disconnected")
+
+	received := 0
+	for range events {
+		received++
+	}
+	assert.True(t, received <= 1)
+}
+
+func TestEventsListenerStats(t *testing.T) {
+	clientCfg := NewDefaultConfig()
+	clientCfg.EventsTransport = EventsTransportSSE
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &clientCfg})
+	defer endpoint.Close()
+
+	client := endpoint.Client.(*marathonClient)
+	events, err := endpoint.Client.AddEventsListener(EventIDStatusUpdate)
+	require.NoError(t, err)
+
+	source := testCases.find("status_update_event").source
+	require.NoError(t, client.handleEvent(source))
+	require.NoError(t, client.handleEvent(source))
+
+	select {
+	case <-events:
+	case <-time.After(eventPublishTimeout):
+		require.Fail(t, "did not receive event in time")
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	stats, found := endpoint.Client.EventsListenerStats(events)
+	require.True(t, found)
+	assert.Equal(t, EventsListenerStats{Queued: 0, Delivered: 1}, stats)
+
+	endpoint.Client.RemoveEventsListener(events)
+	_, found = endpoint.Client.EventsListenerStats(events)
+	assert.False(t, found)
+}
@@ -51,6 +51,7 @@ var (
 
 type indexedResponse struct {
 	Index   int               `yaml:"index,omitempty"`
+	Status  int               `yaml:"status,omitempty"`
 	Content string            `yaml:"content,omitempty"`
 	Headers map[string]string `yaml:"headers,omitempty"`
 }
@@ -167,6 +168,9 @@ func newFakeMarathonEndpoint(t *testing.T, configs *configContainer) *endpoint {
 					for k, v := range response.Headers {
 						writer.Header().Add(k, v)
 					}
+					if response.Status != 0 {
+						writer.WriteHeader(response.Status)
+					}
 
 					writer.Write([]byte(response.Content))
 					return
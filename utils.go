@@ -22,27 +22,12 @@ import (
 	"net"
 	"net/url"
 	"reflect"
+	"strconv"
 	"strings"
-	"sync/atomic"
-	"time"
 
 	"github.com/google/go-querystring/query"
 )
 
-type atomicSwitch int64
-
-func (r *atomicSwitch) IsSwitched() bool {
-	return atomic.LoadInt64((*int64)(r)) != 0
-}
-
-func (r *atomicSwitch) SwitchOn() {
-	atomic.StoreInt64((*int64)(r), 1)
-}
-
-func (r *atomicSwitch) SwitchedOff() {
-	atomic.StoreInt64((*int64)(r), 0)
-}
-
 func validateID(id string) string {
 	if !strings.HasPrefix(id, "/") {
 		return fmt.Sprintf("/%s", id)
@@ -57,26 +42,6 @@ func trimRootPath(id string) string {
 	return id
 }
 
-func deadline(timeout time.Duration, work func(chan bool) error) error {
-	result := make(chan error)
-	timer := time.After(timeout)
-	stopChannel := make(chan bool, 1)
-
-	// allow the method to attempt
-	go func() {
-		result <- work(stopChannel)
-	}()
-	for {
-		select {
-		case err := <-result:
-			return err
-		case <-timer:
-			stopChannel <- true
-			return ErrTimeoutError
-		}
-	}
-}
-
 func getInterfaceAddress(name string) (string, error) {
 	interfaces, err := net.Interfaces()
 	if err != nil {
@@ -133,3 +98,22 @@ func addOptions(s string, opt interface{}) (string, error) {
 	u.RawQuery = qs.Encode()
 	return u.String(), nil
 }
+
+// containsInt checks if the value is in the list
+func containsInt(list []int, value int) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// joinInts joins the integers with the separator
+func joinInts(list []int, separator string) string {
+	var items []string
+	for _, item := range list {
+		items = append(items, strconv.Itoa(item))
+	}
+	return strings.Join(items, separator)
+}
@@ -0,0 +1,303 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// validationMessage is the message of the validation errors, as returned by Marathon
+const validationMessage = "Object is not valid"
+
+var (
+	// idSegmentRegexp matches the segments of the IDs of the applications, the groups and the pods
+	idSegmentRegexp = regexp.MustCompile(`^(([a-z0-9]|[a-z0-9][a-z0-9\-]*[a-z0-9])\.)*([a-z0-9]|[a-z0-9][a-z0-9\-]*[a-z0-9])$`)
+	// containerNameRegexp matches the names of the containers of the pods
+	containerNameRegexp = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9]+)*$`)
+)
+
+// validator collects the violations of a definition
+type validator struct {
+	violations []Violation
+}
+
+// add records a violation of the attribute of the path
+func (v *validator) add(path, format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	for index := range v.violations {
+		if v.violations[index].Path == path {
+			v.violations[index].Errors = append(v.violations[index].Errors, message)
+			return
+		}
+	}
+	v.violations = append(v.violations, Violation{Path: path, Errors: []string{message}})
+}
+
+// err returns the *ValidationError of the violations, nil if there are none
+func (v *validator) err() error {
+	if len(v.violations) == 0 {
+		return nil
+	}
+	return &ValidationError{Message: validationMessage, Violations: v.violations}
+}
+
+// Validate checks the application against the rules of Marathon before it is submitted, returning a
+// *ValidationError listing all the violations, if any
+func (r *Application) Validate() error {
+	v := new(validator)
+	v.application("", r, false)
+	return v.err()
+}
+
+// Validate checks the group and its applications and subgroups against the rules of Marathon before it is
+// submitted, returning a *ValidationError listing all the violations, if any
+func (r *Group) Validate() error {
+	v := new(validator)
+	v.group("", r, false)
+	return v.err()
+}
+
+// Validate checks the pod against the rules of Marathon before it is submitted, returning a *ValidationError
+// listing all the violations, if any
+func (p *Pod) Validate() error {
+	v := new(validator)
+	v.pod("", p)
+	return v.err()
+}
+
+// group validates a group and its applications and subgroups
+//		path:		the path of the group
+//		group:		the group to validate
+//		relative:	whether the ID of the group may be relative to its parent
+func (v *validator) group(path string, group *Group, relative bool) {
+	if group.ID != "/" || relative {
+		v.id(path+"/id", group.ID, relative)
+	}
+	for index, application := range group.Apps {
+		if application != nil {
+			v.application(fmt.Sprintf("%s/apps(%d)", path, index), application, true)
+		}
+	}
+	for index, subgroup := range group.Groups {
+		if subgroup != nil {
+			v.group(fmt.Sprintf("%s/groups(%d)", path, index), subgroup, true)
+		}
+	}
+}
+
+// application validates an application
+//		path:		the path of the application
+//		application:	the application to validate
+//		relative:	whether the ID of the application may be relative to its group
+func (v *validator) application(path string, application *Application, relative bool) {
+	v.id(path+"/id", application.ID, relative)
+
+	// step: the application needs something to run
+	hasCmd := application.Cmd != nil && *application.Cmd != ""
+	hasArgs := application.Args != nil && len(*application.Args) > 0
+	if hasCmd && hasArgs {
+		v.add(path+"/", "AppDefinition must either contain a 'cmd' or a 'args', but not both")
+	}
+	if !hasCmd && !hasArgs && application.Container == nil {
+		v.add(path+"/", "AppDefinition must either contain one of 'cmd' or 'args', and/or a 'container'")
+	}
+
+	// step: the resources
+	v.minimum(path+"/cpus", application.CPUs, 0)
+	if application.Mem != nil {
+		v.minimum(path+"/mem", *application.Mem, 0)
+	}
+	if application.Disk != nil {
+		v.minimum(path+"/disk", *application.Disk, 0)
+	}
+	if application.GPUs != nil {
+		v.minimum(path+"/gpus", *application.GPUs, 0)
+	}
+	if application.Instances != nil {
+		v.minimum(path+"/instances", float64(*application.Instances), 0)
+	}
+
+	// step: the networking
+	hasPorts := len(application.Ports) > 0 ||
+		(application.PortDefinitions != nil && len(*application.PortDefinitions) > 0)
+	if application.IPAddressPerTask != nil && hasPorts {
+		v.add(path+"/ipAddress", "IP address and ports are not allowed at the same time, the ports and the port definitions must be empty")
+	}
+	if application.Container != nil && application.Container.Docker != nil {
+		docker := application.Container.Docker
+		if docker.Image == "" {
+			v.add(path+"/container/docker/image", "must not be empty")
+		}
+		if docker.PortMappings != nil && len(*docker.PortMappings) > 0 && !isMappedNetwork(docker.Network) {
+			v.add(path+"/container/docker/portMappings", "port mappings are only allowed with BRIDGE or USER networking")
+		}
+	}
+
+	// step: the health checks address the ports of the application
+	if application.HealthChecks != nil {
+		ports := applicationPortsCount(application)
+		for index, check := range *application.HealthChecks {
+			if check.PortIndex == nil {
+				continue
+			}
+			if *check.PortIndex < 0 || *check.PortIndex >= ports {
+				v.add(fmt.Sprintf("%s/healthChecks(%d)/portIndex", path, index),
+					"Health check port indices must address an element of the ports array or container port mappings")
+			}
+		}
+	}
+
+	if application.Constraints != nil {
+		v.constraints(path+"/constraints", *application.Constraints)
+	}
+
+	if strategy := application.UpgradeStrategy; strategy != nil {
+		if strategy.MinimumHealthCapacity != nil {
+			v.between(path+"/upgradeStrategy/minimumHealthCapacity", *strategy.MinimumHealthCapacity, 0, 1)
+		}
+		if strategy.MaximumOverCapacity != nil {
+			v.between(path+"/upgradeStrategy/maximumOverCapacity", *strategy.MaximumOverCapacity, 0, 1)
+		}
+	}
+}
+
+// pod validates a pod
+//		path:		the path of the pod
+//		pod:		the pod to validate
+func (v *validator) pod(path string, pod *Pod) {
+	v.id(path+"/id", pod.ID, false)
+
+	if len(pod.Containers) == 0 {
+		v.add(path+"/containers", "must not be empty")
+	}
+	names := make(map[string]bool)
+	for index, container := range pod.Containers {
+		if container == nil {
+			continue
+		}
+		containerPath := fmt.Sprintf("%s/containers(%d)", path, index)
+		switch {
+		case container.Name == "":
+			v.add(containerPath+"/name", "must not be empty")
+		case !containerNameRegexp.MatchString(container.Name):
+			v.add(containerPath+"/name", "must fully match regular expression '%s'", containerNameRegexp)
+		case names[container.Name]:
+			v.add(containerPath+"/name", "container names are not unique")
+		}
+		names[container.Name] = true
+
+		if container.Resources != nil {
+			v.minimum(containerPath+"/resources/cpus", container.Resources.Cpus, 0)
+			v.minimum(containerPath+"/resources/mem", container.Resources.Mem, 0)
+			v.minimum(containerPath+"/resources/disk", container.Resources.Disk, 0)
+			v.minimum(containerPath+"/resources/gpus", float64(container.Resources.Gpus), 0)
+		}
+	}
+
+	if scaling := pod.Scaling; scaling != nil {
+		v.minimum(path+"/scaling/instances", float64(scaling.Instances), 0)
+		if scaling.MaxInstances > 0 && scaling.Instances > scaling.MaxInstances {
+			v.add(path+"/scaling/instances", "got %d, expected at most maxInstances %d", scaling.Instances, scaling.MaxInstances)
+		}
+	}
+
+	if scheduling := pod.Scheduling; scheduling != nil {
+		if upgrade := scheduling.Upgrade; upgrade != nil {
+			v.between(path+"/scheduling/upgrade/minimumHealthCapacity", float64(upgrade.MinimumHealthCapacity), 0, 1)
+			v.between(path+"/scheduling/upgrade/maximumOverCapacity", float64(upgrade.MaximumOverCapacity), 0, 1)
+		}
+		if placement := scheduling.Placement; placement != nil && placement.Constraints != nil {
+			v.constraints(path+"/scheduling/placement/constraints", *placement.Constraints)
+		}
+	}
+}
+
+// id validates the ID of an application, a group or a pod
+//		path:		the path of the ID
+//		id:		the ID to validate
+//		relative:	whether the ID may be relative to the group of the application, the group or the pod
+func (v *validator) id(path, id string, relative bool) {
+	if id == "" {
+		v.add(path, "must not be empty")
+		return
+	}
+	if !relative && !strings.HasPrefix(id, "/") {
+		// step: the client makes the IDs absolute, as Marathon does
+		id = "/" + id
+	}
+	for _, segment := range strings.Split(strings.TrimPrefix(id, "/"), "/") {
+		if segment == "." || segment == ".." {
+			continue
+		}
+		if !idSegmentRegexp.MatchString(segment) {
+			v.add(path, `path contains invalid characters (allowed: lowercase letters, digits, hyphen, ".", "..")`)
+			return
+		}
+	}
+}
+
+// constraints validates the operators and the arities of constraints
+//		path:		the path of the constraints
+//		constraints:	the constraints to validate
+func (v *validator) constraints(path string, constraints [][]string) {
+	for index, constraint := range constraints {
+		if _, err := ParseConstraint(constraint); err != nil {
+			v.add(fmt.Sprintf("%s(%d)", path, index), err.Error())
+		}
+	}
+}
+
+// minimum validates that a value is not below a minimum
+func (v *validator) minimum(path string, value, minimum float64) {
+	if value < minimum {
+		v.add(path, "got %v, expected %v or more", value, minimum)
+	}
+}
+
+// between validates that a value lies within bounds
+func (v *validator) between(path string, value, minimum, maximum float64) {
+	if value < minimum || value > maximum {
+		v.add(path, "got %v, expected between %v and %v", value, minimum, maximum)
+	}
+}
+
+// isMappedNetwork checks if the Docker network mode supports port mappings
+func isMappedNetwork(network string) bool {
+	return network == "BRIDGE" || network == "USER"
+}
+
+// applicationPortsCount returns the number of ports the health checks of the application can address
+func applicationPortsCount(application *Application) int {
+	if container := application.Container; container != nil && container.Docker != nil &&
+		container.Docker.PortMappings != nil && isMappedNetwork(container.Docker.Network) {
+		return len(*container.Docker.PortMappings)
+	}
+	if ipAddress := application.IPAddressPerTask; ipAddress != nil && ipAddress.Discovery != nil &&
+		ipAddress.Discovery.Ports != nil {
+		return len(*ipAddress.Discovery.Ports)
+	}
+	if application.PortDefinitions != nil {
+		return len(*application.PortDefinitions)
+	}
+	if application.Ports != nil {
+		return len(application.Ports)
+	}
+	// step: Marathon assigns a port to the applications which define none
+	return 1
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// violationPaths returns the paths of the violations of a *ValidationError
+func violationPaths(t *testing.T, err error) []string {
+	validationErr, ok := err.(*ValidationError)
+	require.True(t, ok, "the error is not a *ValidationError: %v", err)
+	assert.Equal(t, validationMessage, validationErr.Message)
+
+	var paths []string
+	for _, violation := range validationErr.Violations {
+		paths = append(paths, violation.Path)
+	}
+	return paths
+}
+
+func TestApplicationValidate(t *testing.T) {
+	application := NewDockerApplication().Name("/prod/web.frontend").Count(2).CPU(0.5).Memory(64)
+	application.Container.Docker.Container("nginx").Bridged().Expose(80, 443)
+	application.AddHealthCheck(HealthCheck{Protocol: "HTTP"}.SetPortIndex(1))
+	application.AddConstraint("hostname", "UNIQUE").AddConstraint("rack", "MAX_PER", "2")
+	application.SetUpgradeStrategy(UpgradeStrategy{}.SetMinimumHealthCapacity(0.5))
+	assert.NoError(t, application.Validate())
+
+	// step: all the violations are reported
+	application.ID = "/Prod/web"
+	application.Count(-1).CPU(-1)
+	application.Container.Docker.Host()
+	application.AddHealthCheck(HealthCheck{Protocol: "HTTP"}.SetPortIndex(2))
+	application.AddConstraint("hostname", "EQUALS", "a").AddConstraint("hostname", "LIKE").
+		AddConstraint("rack", "MAX_PER", "many")
+	application.SetUpgradeStrategy(UpgradeStrategy{}.SetMaximumOverCapacity(1.5))
+	assert.Equal(t, []string{
+		"/id",
+		"/cpus",
+		"/instances",
+		"/container/docker/portMappings",
+		"/healthChecks(0)/portIndex",
+		"/healthChecks(1)/portIndex",
+		"/constraints(2)",
+		"/constraints(3)",
+		"/constraints(4)",
+		"/upgradeStrategy/maximumOverCapacity",
+	}, violationPaths(t, application.Validate()))
+}
+
+func TestApplicationValidateCommand(t *testing.T) {
+	application := new(Application).Name("app").Command("sleep 60")
+	assert.NoError(t, application.Validate())
+
+	application.AddArgs("60")
+	application.SetIPAddressPerTask(IPAddressPerTask{})
+	application.AddPortDefinition(PortDefinition{}.SetPort(0))
+	assert.Equal(t, []string{"/", "/ipAddress"}, violationPaths(t, application.Validate()))
+
+	err := new(Application).Validate()
+	assert.Equal(t, []string{"/id", "/"}, violationPaths(t, err))
+	assert.Contains(t, err.Error(), "path: '/id' errors: must not be empty")
+}
+
+func TestGroupValidate(t *testing.T) {
+	group := NewApplicationGroup("/prod").
+		App(new(Application).Name("/prod/web").Command("sleep 60"))
+	group.Groups = []*Group{{ID: "db", Apps: []*Application{{ID: "../db_primary"}}}}
+
+	assert.Equal(t, []string{"/groups(0)/apps(0)/id", "/groups(0)/apps(0)/"}, violationPaths(t, group.Validate()))
+}
+
+func TestPodValidate(t *testing.T) {
+	pod := NewPod().Name("/prod/pod").Count(1).
+		AddContainer(NewPodContainer().SetName("web").CPUs(0.1).Memory(32)).
+		AddContainer(NewPodContainer().SetName("sidecar"))
+	assert.NoError(t, pod.Validate())
+
+	pod.AddContainer(NewPodContainer().SetName("web").CPUs(-1)).
+		AddContainer(NewPodContainer().SetName("Side_Car"))
+	pod.Count(-1)
+	assert.Equal(t, []string{
+		"/containers(2)/name",
+		"/containers(2)/resources/cpus",
+		"/containers(3)/name",
+		"/scaling/instances",
+	}, violationPaths(t, pod.Validate()))
+
+	assert.Equal(t, []string{"/id", "/containers"}, violationPaths(t, new(Pod).Validate()))
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// waitEventsFilter comprises the listener IDs of the events which may end a wait
+const waitEventsFilter = EventIDStatusUpdate | EventIDAppTerminated | EventIDChangedHealthCheck |
+	EventIDInstanceChanged | EventIDInstanceHealthChanged | EventIDGroupChangeSuccess |
+	EventIDDeploymentSuccess | EventIDDeploymentFailed | EventIDDeploymentStepFailure
+
+// eventsWatch decides whether an event is relevant to a wait, or ends it with an error. It is handed the
+// copy of the client bound to the timeout of the wait
+type eventsWatch func(client *marathonClient, event *Event) (bool, error)
+
+// DeploymentFailedError is returned by the waits when a deployment they depend on has failed
+type DeploymentFailedError struct {
+	// DeploymentID is the ID of the failed deployment
+	DeploymentID string
+	// FailedStep is the step of the deployment which failed, if known
+	FailedStep *StepActions
+	// LastTaskFailure is the last task failure of an application of the failed step, if any
+	LastTaskFailure *LastTaskFailure
+}
+
+func (e *DeploymentFailedError) Error() string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "deployment %s failed", e.DeploymentID)
+	if e.FailedStep != nil {
+		var actions []string
+		for _, action := range e.FailedStep.Actions {
+			name := action.Action
+			if name == "" {
+				name = action.Type
+			}
+			actions = append(actions, fmt.Sprintf("%s %s", name, action.App))
+		}
+		fmt.Fprintf(&b, " at step: %s", strings.Join(actions, ", "))
+	}
+	if failure := e.LastTaskFailure; failure != nil {
+		fmt.Fprintf(&b, ", last task failure: %s %s: %s", failure.TaskID, failure.State, failure.Message)
+	}
+	return b.String()
+}
+
+// receivesEvents checks if the client receives the events of Marathon, through the event stream or the callback
+func (r *marathonClient) receivesEvents() bool {
+	r.RLock()
+	defer r.RUnlock()
+	return r.subscribedToSSE || r.eventsHTTP != nil
+}
+
+// waitEvents registers a listener of the events of interest to waits, provided the client receives events
+func (r *marathonClient) waitEvents() EventsChannel {
+	if !r.receivesEvents() {
+		return nil
+	}
+
+	// step: the waits poll anyway, so they never hold up the dispatching of events, nor change the subscription
+	events, err := r.addEventsListener(waitEventsFilter, EventsListenerOptions{
+		Overflow: EventsOverflowDropOldest,
+	}, true)
+	if err != nil {
+		return nil
+	}
+	return events
+}
+
+// watchDeployment watches the events of a deployment, its success being relevant and its failure an error
+//		id:		the deployment ID
+//		succeeded:	set once the success of the deployment has been received
+func watchDeployment(id string, succeeded *bool) eventsWatch {
+	var failedStep *StepActions
+	return func(client *marathonClient, event *Event) (bool, error) {
+		switch e := event.Event.(type) {
+		case *EventDeploymentSuccess:
+			if e.ID == id {
+				*succeeded = true
+				return true, nil
+			}
+		case *EventDeploymentStepFailure:
+			if e.Plan != nil && e.Plan.ID == id {
+				failedStep = e.CurrentStep
+			}
+		case *EventDeploymentFailed:
+			if e.ID == id {
+				return false, client.deploymentFailed(id, failedStep)
+			}
+		}
+		return false, nil
+	}
+}
+
+// watchRunSpecs watches the events of applications or pods: the events concerning them are relevant, and the
+// failure of a deployment having failed a step affecting them is an error
+//		match:		matches the IDs of the applications or pods
+func watchRunSpecs(match func(id string) bool) eventsWatch {
+	failedSteps := make(map[string]*StepActions)
+	return func(client *marathonClient, event *Event) (bool, error) {
+		switch e := event.Event.(type) {
+		case *EventDeploymentStepFailure:
+			if e.Plan != nil && matchAny(match, deploymentAppIDs(e.CurrentStep, nil)) {
+				failedSteps[e.Plan.ID] = e.CurrentStep
+			}
+			return false, nil
+		case *EventDeploymentFailed:
+			if step, found := failedSteps[e.ID]; found {
+				return false, client.deploymentFailed(e.ID, step)
+			}
+			return false, nil
+		case *EventGroupChangeSuccess:
+			return match(e.GroupID), nil
+		}
+		return matchAny(match, eventAppIDs(event)), nil
+	}
+}
+
+// deploymentFailed creates the error of a failed deployment, looking up the last task failure of the
+// applications of the failed step
+func (r *marathonClient) deploymentFailed(id string, step *StepActions) error {
+	err := &DeploymentFailedError{DeploymentID: id, FailedStep: step}
+	for _, appID := range deploymentAppIDs(step, nil) {
+		if application, appErr := r.Application(appID); appErr == nil && application.LastTaskFailure != nil {
+			err.LastTaskFailure = application.LastTaskFailure
+			break
+		}
+	}
+	return err
+}
+
+// matchID returns a function matching the given application or pod ID, regardless of the leading slash
+func matchID(id string) func(string) bool {
+	return func(other string) bool {
+		return trimRootPath(other) == trimRootPath(id)
+	}
+}
+
+// matchGroup returns a function matching the given group ID and the IDs of its members
+func matchGroup(id string) func(string) bool {
+	group := strings.TrimSuffix(trimRootPath(id), "/")
+	return func(other string) bool {
+		other = trimRootPath(other)
+		return other == group || strings.HasPrefix(other, group+"/")
+	}
+}
+
+// matchAny checks if any of the IDs matches
+func matchAny(match func(string) bool, ids []string) bool {
+	for _, id := range ids {
+		if match(id) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2017 Rohith All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package marathon
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func failedStep(appID string) *StepActions {
+	return &StepActions{Actions: []struct {
+		Action string `json:"action"`
+		Type   string `json:"type"`
+		App    string `json:"app"`
+	}{{Action: "ScaleApplication", App: appID}}}
+}
+
+func TestWaitOnDeploymentFailedEvent(t *testing.T) {
+	endpoint := newSSEEndpoint(t)
+	defer endpoint.Close()
+
+	// step: polling alone would never end the wait in time
+	client := endpoint.Client.(*marathonClient)
+	client.config.PollingWaitTime = time.Hour
+
+	events, err := endpoint.Client.AddEventsListener(EventIDApplications)
+	require.NoError(t, err)
+	defer endpoint.Client.RemoveEventsListener(events)
+	time.Sleep(SSEConnectWaitTime)
+
+	go func() {
+		time.Sleep(eventPublishTimeout)
+		endpoint.Server.PublishEvent(`{
+	"eventType": "deployment_step_failure",
+	"timestamp": "2017-04-19T16:38:25.122Z",
+	"plan": {"id": "` + fakeDeploymentID + `", "steps": []},
+	"currentStep": {"actions": [{"action": "ScaleApplication", "app": "/fake-app-broken"}]}
+}`)
+		endpoint.Server.PublishEvent(`{
+	"eventType": "deployment_failed",
+	"timestamp": "2017-04-19T16:38:26.001Z",
+	"id": "` + fakeDeploymentID + `"
+}`)
+	}()
+
+	err = endpoint.Client.WaitOnDeployment(fakeDeploymentID, 5*time.Second)
+	require.Error(t, err)
+	failed, ok := err.(*DeploymentFailedError)
+	require.True(t, ok, "unexpected error %v", err)
+	assert.Equal(t, fakeDeploymentID, failed.DeploymentID)
+	assert.Equal(t, failedStep("/fake-app-broken"), failed.FailedStep)
+	require.NotNil(t, failed.LastTaskFailure)
+	assert.Equal(t, "TASK_FAILED", failed.LastTaskFailure.State)
+	assert.Contains(t, err.Error(), "ScaleApplication /fake-app-broken")
+	assert.Contains(t, err.Error(), "Abnormal executor termination")
+}
+
+func TestWaitOnDeploymentVanishedBeforeFailure(t *testing.T) {
+	endpoint := newSSEEndpoint(t)
+	defer endpoint.Close()
+
+	client := endpoint.Client.(*marathonClient)
+	client.config.PollingWaitTime = 10 * time.Millisecond
+
+	events, err := endpoint.Client.AddEventsListener(EventIDApplications)
+	require.NoError(t, err)
+	defer endpoint.Client.RemoveEventsListener(events)
+	time.Sleep(SSEConnectWaitTime)
+
+	// step: the deployment is gone from the list before its failure is received
+	const id = "vanished-deployment"
+	go func() {
+		time.Sleep(eventPublishTimeout)
+		endpoint.Server.PublishEvent(`{
+	"eventType": "deployment_failed",
+	"timestamp": "2017-04-19T16:38:26.001Z",
+	"id": "` + id + `"
+}`)
+	}()
+
+	err = endpoint.Client.WaitOnDeployment(id, 5*time.Second)
+	require.Error(t, err)
+	failed, ok := err.(*DeploymentFailedError)
+	require.True(t, ok, "unexpected error %v", err)
+	assert.Equal(t, id, failed.DeploymentID)
+}
+
+func TestWaitOnDeploymentVanishedBeforeSuccess(t *testing.T) {
+	endpoint := newSSEEndpoint(t)
+	defer endpoint.Close()
+
+	client := endpoint.Client.(*marathonClient)
+	client.config.PollingWaitTime = 10 * time.Millisecond
+
+	events, err := endpoint.Client.AddEventsListener(EventIDApplications)
+	require.NoError(t, err)
+	defer endpoint.Client.RemoveEventsListener(events)
+	time.Sleep(SSEConnectWaitTime)
+
+	const id = "vanished-deployment"
+	go func() {
+		time.Sleep(eventPublishTimeout)
+		endpoint.Server.PublishEvent(`{
+	"eventType": "deployment_success",
+	"timestamp": "2017-04-19T16:38:26.001Z",
+	"id": "` + id + `"
+}`)
+	}()
+
+	// step: the success ends the wait before the outcome timeout
+	start := time.Now()
+	assert.NoError(t, endpoint.Client.WaitOnDeployment(id, 5*time.Second))
+	assert.True(t, time.Since(start) < deploymentOutcomeTimeout, "the wait took %s", time.Since(start))
+}
+
+func TestWaitOnDeploymentWithoutEvents(t *testing.T) {
+	endpoint := newFakeMarathonEndpoint(t, nil)
+	defer endpoint.Close()
+
+	client := endpoint.Client.(*marathonClient)
+	assert.Nil(t, client.waitEvents())
+	assert.Equal(t, ErrTimeoutError, endpoint.Client.WaitOnDeployment(fakeDeploymentID, time.Second))
+}
+
+func TestWaitUntilMetAlready(t *testing.T) {
+	endpoint := newFakeMarathonEndpoint(t, nil)
+	defer endpoint.Close()
+
+	// step: the condition is met before the first tick
+	client := endpoint.Client.(*marathonClient)
+	client.config.PollingWaitTime = time.Hour
+	evaluations := 0
+	err := client.waitUntil(time.Second, nil, func(*marathonClient) (bool, error) {
+		evaluations++
+		return true, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, evaluations)
+}
+
+func TestWaitEventsKeepSubscription(t *testing.T) {
+	config := NewDefaultConfig()
+	config.EventsTransport = EventsTransportCallback
+	endpoint := newFakeMarathonEndpoint(t, &configContainer{client: &config})
+	defer endpoint.Close()
+
+	// step: pretend the callback server receives the events
+	client := endpoint.Client.(*marathonClient)
+	client.Lock()
+	client.eventsHTTP = &http.Server{}
+	client.Unlock()
+	defer func() {
+		client.Lock()
+		client.eventsHTTP = nil
+		client.Unlock()
+	}()
+
+	events := client.waitEvents()
+	require.NotNil(t, events)
+	client.RemoveEventsListener(events)
+
+	// step: the listeners of the waits neither subscribed nor unsubscribed the callback
+	indices := endpoint.Server.fakeRespIndices
+	indices.Lock()
+	defer indices.Unlock()
+	for key := range indices.m {
+		assert.NotContains(t, key, marathonAPISubscription)
+	}
+}
+
+func TestWatchRunSpecs(t *testing.T) {
+	endpoint := newFakeMarathonEndpoint(t, nil)
+	defer endpoint.Close()
+
+	client := endpoint.Client.(*marathonClient)
+	watch := watchRunSpecs(matchGroup("/prod"))
+
+	relevant, err := watch(client, &Event{Event: &EventStatusUpdate{AppID: "/prod/web"}})
+	assert.NoError(t, err)
+	assert.True(t, relevant)
+	relevant, _ = watch(client, &Event{Event: &EventStatusUpdate{AppID: "/production"}})
+	assert.False(t, relevant)
+	relevant, _ = watch(client, &Event{Event: &EventGroupChangeSuccess{GroupID: "/prod"}})
+	assert.True(t, relevant)
+	relevant, _ = watch(client, &Event{Event: &EventInstanceChanged{RunSpecID: "/prod/pod"}})
+	assert.True(t, relevant)
+
+	// step: only the failure of deployments failing a step of the group end the wait
+	_, err = watch(client, &Event{Event: &EventDeploymentFailed{ID: "other"}})
+	assert.NoError(t, err)
+	_, err = watch(client, &Event{Event: &EventDeploymentStepFailure{
+		Plan:        &DeploymentPlan{ID: "1"},
+		CurrentStep: failedStep("/prod/web"),
+	}})
+	assert.NoError(t, err)
+	_, err = watch(client, &Event{Event: &EventDeploymentFailed{ID: "1"}})
+	require.Error(t, err)
+	assert.Equal(t, "1", err.(*DeploymentFailedError).DeploymentID)
+}
+
+func TestWatchDeployment(t *testing.T) {
+	endpoint := newFakeMarathonEndpoint(t, nil)
+	defer endpoint.Close()
+
+	client := endpoint.Client.(*marathonClient)
+	succeeded := false
+	watch := watchDeployment("1", &succeeded)
+
+	relevant, err := watch(client, &Event{Event: &EventDeploymentSuccess{ID: "2"}})
+	assert.NoError(t, err)
+	assert.False(t, relevant)
+	assert.False(t, succeeded)
+	relevant, err = watch(client, &Event{Event: &EventDeploymentSuccess{ID: "1"}})
+	assert.NoError(t, err)
+	assert.True(t, relevant)
+	assert.True(t, succeeded)
+
+	_, err = watch(client, &Event{Event: &EventDeploymentFailed{ID: "1"}})
+	require.Error(t, err)
+	assert.Equal(t, "deployment 1 failed", err.Error())
+}
+
+func TestMatchIDs(t *testing.T) {
+	assert.True(t, matchID("/app")("app"))
+	assert.False(t, matchID("/app")("/app2"))
+	assert.True(t, matchGroup("/group/")("/group/app"))
+	assert.True(t, matchGroup("group")("/group"))
+	assert.False(t, matchGroup("/group")("/group2/app"))
+}